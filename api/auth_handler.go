@@ -1,7 +1,7 @@
 package api
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -28,22 +28,22 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // @Param username formData string true "Username"
 // @Param password formData string true "Password"
 // @Success 200 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Router /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	username := c.PostForm("username")
 	password := c.PostForm("password")
 
 	if username != h.authService.Cfg.Username || password != h.authService.Cfg.Password {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
-		fmt.Println("Invalid username or password, expected:", h.authService.Cfg.Username, h.authService.Cfg.Password, " got:", username, password)
+		slog.InfoContext(c.Request.Context(), "Failed login attempt", "username", username, "client_ip", c.ClientIP())
+		c.Error(service.ErrInvalidCredentials)
 		return
 	}
 
 	token, err := h.authService.GenerateToken(username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/service"
+	"propmanager/internal/config"
+	"propmanager/internal/logging"
+)
+
+// TestConfiguredSecretsNeverLogged drives login and authenticated requests
+// through the logging middleware at debug level and checks that no
+// configured secret, password or issued token reaches the log.
+func TestConfiguredSecretsNeverLogged(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{S3AccessKey: "AKIATESTACCESSKEY", S3SecretKey: "test-s3-secret-key"}
+	authCfg := config.AuthConfig{Username: "admin", Password: "correct-horse-battery", SecretKey: "test-jwt-signing-key"}
+	logging.RegisterSecrets(config.SecretValues(cfg)...)
+	logging.RegisterSecrets(config.SecretValues(authCfg)...)
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, "debug", "json"))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	service.NewS3Service(&cfg)
+
+	authHandler := NewAuthHandler(service.NewAuthService(&authCfg))
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Logger(), middleware.ErrorHandler())
+	r.POST("/login", authHandler.Login)
+	r.GET("/private", authCfg.AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	login := func(password string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {"admin"}, "password": {password}}
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := login("wrong-password-attempt"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("failed login status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w := login(authCfg.Password)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login status = %d, want %d", w.Code, http.StatusOK)
+	}
+	token := strings.Split(strings.TrimSuffix(w.Body.String(), `"}`), `"token":"`)[1]
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("authenticated request status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	output := buf.String()
+	if output == "" {
+		t.Fatal("expected log output")
+	}
+	for _, secret := range []string{cfg.S3AccessKey, cfg.S3SecretKey, authCfg.Password, authCfg.SecretKey, "wrong-password-attempt", token} {
+		if strings.Contains(output, secret) {
+			t.Errorf("log output contains secret %q:\n%s", secret, output)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/validation"
+)
+
+// bindJSON decodes and validates the request body into obj. It records the
+// error and returns false if the body is malformed or invalid.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := validation.Decode(c.Request.Body, obj); err != nil {
+		c.Error(err)
+		return false
+	}
+	return true
+}
+
+// parseID parses the named path parameter as a positive integer ID. It
+// records the error and returns false if the parameter is invalid.
+func parseID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.Error(apperror.BadRequest("invalid_path_parameter", "Path parameter "+name+" must be a positive integer.").Wrap(err))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// readFormFile reads the file uploaded in the "file" form field. It records
+// the error and returns false if there is none or it cannot be read.
+func readFormFile(c *gin.Context) ([]byte, *multipart.FileHeader, bool) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errFileRequired.Wrap(err))
+		return nil, nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(err)
+		return nil, nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(err)
+		return nil, nil, false
+	}
+	return data, header, true
+}
+
+// sniffImage returns the content type of an uploaded image, detected from
+// its contents rather than taken from the client. It records the error and
+// returns false unless the file is an image of one of the allowed types, or
+// of any type if none are given.
+func sniffImage(c *gin.Context, data []byte, allowed ...string) (string, bool) {
+	contentType := http.DetectContentType(data)
+	if len(allowed) == 0 && strings.HasPrefix(contentType, "image/") {
+		return contentType, true
+	}
+	for _, t := range allowed {
+		if contentType == t {
+			return contentType, true
+		}
+	}
+
+	message := "The file must be an image."
+	if len(allowed) > 0 {
+		message = "The file must be an image of type " + strings.Join(allowed, " or ") + "."
+	}
+	c.Error(apperror.UnsupportedMediaType("unsupported_file_type", message))
+	return "", false
+}
+
+// sniffDocument returns the content type of an uploaded document, detected
+// from its contents rather than taken from the client. It records the error
+// and returns false unless the file is a PDF, an image or plain text.
+func sniffDocument(c *gin.Context, data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	if contentType == "application/pdf" || strings.HasPrefix(contentType, "image/") || strings.HasPrefix(contentType, "text/plain") {
+		return contentType, true
+	}
+	c.Error(apperror.UnsupportedMediaType("unsupported_file_type", "The file must be a PDF, an image or plain text."))
+	return "", false
+}
+
+// parseIDQuery parses the named optional query parameter as a positive
+// integer ID, returning zero if it is absent. It records the error and
+// returns false if the parameter is invalid.
+func parseIDQuery(c *gin.Context, name string) (uint, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		c.Error(apperror.Validation([]apperror.FieldError{{Field: name, Reason: "must be a positive integer"}}))
+		return 0, false
+	}
+	return uint(id), true
+}
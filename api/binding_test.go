@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	pngData  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpegData = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	gifData  = []byte("GIF89a\x01\x00\x01\x00")
+	pdfData  = []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	htmlData = []byte("<!DOCTYPE html><html><script>alert(1)</script></html>")
+	zipData  = []byte("PK\x03\x04\x14\x00\x00\x00")
+)
+
+func TestSniffUploads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name  string
+		sniff func(c *gin.Context, data []byte) (string, bool)
+		data  []byte
+		want  string
+	}{
+		{"document PDF", sniffDocument, pdfData, "application/pdf"},
+		{"document image", sniffDocument, pngData, "image/png"},
+		{"document text", sniffDocument, []byte("Signed by both parties."), "text/plain; charset=utf-8"},
+		{"document HTML", sniffDocument, htmlData, ""},
+		{"document archive", sniffDocument, zipData, ""},
+		{"any image GIF", anyImage, gifData, "image/gif"},
+		{"any image PDF", anyImage, pdfData, ""},
+		{"JPEG or PNG given JPEG", jpegOrPNG, jpegData, "image/jpeg"},
+		{"JPEG or PNG given GIF", jpegOrPNG, gifData, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			got, ok := tt.sniff(c, tt.data)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("sniffed %q, %v; want %q", got, ok, tt.want)
+			}
+			if !ok && len(c.Errors) != 1 {
+				t.Errorf("recorded %d errors for a refused file, want 1", len(c.Errors))
+			}
+		})
+	}
+}
+
+func anyImage(c *gin.Context, data []byte) (string, bool) {
+	return sniffImage(c, data)
+}
+
+func jpegOrPNG(c *gin.Context, data []byte) (string, bool) {
+	return sniffImage(c, data, "image/jpeg", "image/png")
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/service"
+)
+
+// DelinquencyHandler represents the handler for overdue accounts.
+type DelinquencyHandler struct {
+	lateFeeService *service.LateFeeService
+}
+
+// NewDelinquencyHandler returns a new delinquency handler.
+func NewDelinquencyHandler(lateFeeService *service.LateFeeService) *DelinquencyHandler {
+	return &DelinquencyHandler{lateFeeService: lateFeeService}
+}
+
+// GetDelinquencyReport godoc
+// @Summary Get the delinquency report
+// @Description List the leases with unpaid charges, most overdue first, with the unpaid amounts aged into 0–30, 31–60, 61–90 and over 90 days past due. Payments and credits settle the oldest charges first.
+// @Tags Ledger
+// @Accept  json
+// @Produce  json
+// @Param as_of query string false "Day to report on (YYYY-MM-DD); defaults to today"
+// @Param property_id query int false "Only leases of this property"
+// @Success 200 {object} model.DelinquencyReport
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /reports/delinquency [get]
+func (h *DelinquencyHandler) GetDelinquencyReport(c *gin.Context) {
+	asOf, ok := parseDayQuery(c, "as_of")
+	if !ok {
+		c.Error(apperror.Validation([]apperror.FieldError{{Field: "as_of", Reason: "must be a date formatted as YYYY-MM-DD"}}))
+		return
+	}
+	if asOf.IsZero() {
+		asOf = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	propertyID, ok := parseIDQuery(c, "property_id")
+	if !ok {
+		return
+	}
+
+	report, err := h.lateFeeService.GetDelinquencyReport(c.Request.Context(), asOf, propertyID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
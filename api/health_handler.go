@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/service"
+)
+
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Report that the server process is running. No dependencies are checked.
+// @Tags Health
+// @Produce  json
+// @Success 200 {object} model.HealthReport
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, h.healthService.Liveness())
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Check the database and S3 bucket and report the status and latency of each. Responds 503 if any dependency is down or the server is shutting down.
+// @Tags Health
+// @Produce  json
+// @Success 200 {object} model.HealthReport
+// @Failure 503 {object} model.HealthReport
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.healthService.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != model.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
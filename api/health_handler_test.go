@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/service"
+)
+
+// TestHealthProbes checks that readiness reports each dependency and goes
+// unavailable when one is down or slow, or when the server is shutting
+// down, while liveness stays up throughout.
+func TestHealthProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bucket service.HealthCheck = func(context.Context) error { return nil }
+	healthService := service.NewHealthService(map[string]service.HealthCheck{
+		"database": service.DatabaseCheck(db),
+		"s3":       func(ctx context.Context) error { return bucket(ctx) },
+	}, 50*time.Millisecond)
+
+	h := NewHealthHandler(healthService)
+	r := gin.New()
+	r.GET("/healthz", h.Liveness)
+	r.GET("/readyz", h.Readiness)
+
+	probe := func(path string) (int, model.HealthReport) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if strings.Contains(w.Body.String(), "AccessDenied") {
+			t.Errorf("%s exposes the underlying error: %s", path, w.Body)
+		}
+		var report model.HealthReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, report
+	}
+
+	tests := []struct {
+		name         string
+		bucket       service.HealthCheck
+		shutDown     bool
+		wantStatus   int
+		wantS3       string
+		wantS3Error  string
+		wantShutdown bool
+	}{
+		{name: "all up", bucket: func(context.Context) error { return nil }, wantStatus: http.StatusOK, wantS3: model.HealthStatusUp},
+		{name: "bucket down", bucket: func(context.Context) error { return errors.New("AccessDenied") }, wantStatus: http.StatusServiceUnavailable, wantS3: model.HealthStatusDown, wantS3Error: "unreachable"},
+		{name: "bucket slow", bucket: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }, wantStatus: http.StatusServiceUnavailable, wantS3: model.HealthStatusDown, wantS3Error: "timed out"},
+		{name: "shutting down", bucket: func(context.Context) error { return nil }, shutDown: true, wantStatus: http.StatusServiceUnavailable, wantS3: model.HealthStatusUp, wantShutdown: true},
+	}
+	for _, tt := range tests {
+		bucket = tt.bucket
+		if tt.shutDown {
+			healthService.SetShuttingDown()
+		}
+
+		code, report := probe("/readyz")
+		if code != tt.wantStatus || report.ShuttingDown != tt.wantShutdown {
+			t.Errorf("%s: readiness = %d %+v, want %d", tt.name, code, report, tt.wantStatus)
+		}
+		if report.Checks["database"].Status != model.HealthStatusUp {
+			t.Errorf("%s: database = %+v, want up", tt.name, report.Checks["database"])
+		}
+		if s3 := report.Checks["s3"]; s3.Status != tt.wantS3 || s3.Error != tt.wantS3Error {
+			t.Errorf("%s: s3 = %+v, want %s %q", tt.name, s3, tt.wantS3, tt.wantS3Error)
+		}
+
+		if code, report := probe("/healthz"); code != http.StatusOK || report.Status != model.HealthStatusOK || report.Checks != nil {
+			t.Errorf("%s: liveness = %d %+v, want 200 without checks", tt.name, code, report)
+		}
+	}
+}
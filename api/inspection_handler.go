@@ -0,0 +1,635 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/report"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// Limits on the photos embedded in an inspection report, which is built in
+// memory. Photos beyond them are listed by name instead.
+const (
+	maxReportPhotos     = 50
+	maxReportPhotoBytes = 50 << 20
+)
+
+// InspectionHandler represents the handler for inspection templates and
+// the inspections scheduled from them.
+type InspectionHandler struct {
+	inspectionService *service.InspectionService
+	s3Service         *service.S3Service
+}
+
+// NewInspectionHandler returns a new inspection handler.
+func NewInspectionHandler(inspectionService *service.InspectionService, s3Service *service.S3Service) *InspectionHandler {
+	return &InspectionHandler{inspectionService: inspectionService, s3Service: s3Service}
+}
+
+// GetTemplates godoc
+// @Summary List inspection templates
+// @Description List the inspection templates ordered by name
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.InspectionTemplate
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspection-templates [get]
+func (h *InspectionHandler) GetTemplates(c *gin.Context) {
+	templates, err := h.inspectionService.GetTemplates(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate godoc
+// @Summary Get an inspection template
+// @Description Get an inspection template by ID
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection template ID"
+// @Success 200 {object} model.InspectionTemplate
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspection-templates/{id} [get]
+func (h *InspectionHandler) GetTemplate(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	template, err := h.inspectionService.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate godoc
+// @Summary Create an inspection template
+// @Description Create a checklist of rooms and the items to rate in each, on a scale of condition ratings listed best first
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param template body dto.InspectionTemplateRequest true "Inspection template"
+// @Success 201 {object} model.InspectionTemplate
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspection-templates [post]
+func (h *InspectionHandler) CreateTemplate(c *gin.Context) {
+	var request dto.InspectionTemplateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	template := request.ToModel(0)
+	if err := h.inspectionService.CreateTemplate(c.Request.Context(), &template); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateTemplate godoc
+// @Summary Update an inspection template
+// @Description Replace an inspection template. Inspections already scheduled from it keep the checklist they were scheduled with.
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection template ID"
+// @Param template body dto.InspectionTemplateRequest true "Inspection template"
+// @Success 200 {object} model.InspectionTemplate
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspection-templates/{id} [put]
+func (h *InspectionHandler) UpdateTemplate(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.InspectionTemplateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	template := request.ToModel(id)
+	if err := h.inspectionService.UpdateTemplate(c.Request.Context(), &template); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete an inspection template
+// @Description Delete an inspection template. Inspections already scheduled from it are kept.
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection template ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspection-templates/{id} [delete]
+func (h *InspectionHandler) DeleteTemplate(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.inspectionService.DeleteTemplate(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetPropertyInspections godoc
+// @Summary List a property's inspections
+// @Description List the inspections of a property, latest scheduled first, optionally filtered
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id query int false "Only inspections of this unit"
+// @Param type query string false "Only inspections of this type" Enums(move_in, move_out, routine)
+// @Param status query string false "Only inspections with this status" Enums(scheduled, completed, cancelled)
+// @Success 200 {array} model.Inspection
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/inspections [get]
+func (h *InspectionHandler) GetPropertyInspections(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	unitID, ok := parseIDQuery(c, "unit_id")
+	if !ok {
+		return
+	}
+
+	filter := repository.InspectionFilter{
+		PropertyID: id,
+		UnitID:     unitID,
+		Type:       c.Query("type"),
+		Status:     c.Query("status"),
+	}
+	var fields []apperror.FieldError
+	fields = checkQueryOneOf(fields, "type", filter.Type, "move_in", "move_out", "routine")
+	fields = checkQueryOneOf(fields, "status", filter.Status, "scheduled", "completed", "cancelled")
+	if len(fields) > 0 {
+		c.Error(apperror.Validation(fields))
+		return
+	}
+
+	inspections, err := h.inspectionService.SearchInspections(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inspections)
+}
+
+// GetInspection godoc
+// @Summary Get an inspection
+// @Description Get an inspection by ID with its checklist, results and photos
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection ID"
+// @Success 200 {object} model.Inspection
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id} [get]
+func (h *InspectionHandler) GetInspection(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	inspection, err := h.inspectionService.GetInspection(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
+// ScheduleInspection godoc
+// @Summary Schedule an inspection
+// @Description Schedule an inspection of a property, unit or the unit of a lease, with a copy of the checklist of a template
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param inspection body dto.InspectionRequest true "Inspection"
+// @Success 201 {object} model.Inspection
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections [post]
+func (h *InspectionHandler) ScheduleInspection(c *gin.Context) {
+	var request dto.InspectionRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	inspection := request.ToModel()
+	if err := h.inspectionService.ScheduleInspection(c.Request.Context(), &inspection); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, inspection)
+}
+
+// RescheduleInspection godoc
+// @Summary Reschedule an inspection
+// @Description Move a scheduled inspection or hand it to another inspector
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection ID"
+// @Param schedule body dto.RescheduleInspectionRequest true "New time and inspector"
+// @Success 200 {object} model.Inspection
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id} [put]
+func (h *InspectionHandler) RescheduleInspection(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.RescheduleInspectionRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	inspection, err := h.inspectionService.RescheduleInspection(c.Request.Context(), id, request.Time(), request.Inspector)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
+// CancelInspection godoc
+// @Summary Cancel an inspection
+// @Description Cancel a scheduled inspection
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection ID"
+// @Success 200 {object} model.Inspection
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/cancel [post]
+func (h *InspectionHandler) CancelInspection(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	inspection, err := h.inspectionService.CancelInspection(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
+// SubmitInspection godoc
+// @Summary Submit an inspection
+// @Description Complete a scheduled inspection with a rating for every item of its checklist, notes on any of them and on the inspection as a whole. Upload photos before submitting; the results and photos of a completed inspection cannot be changed.
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection ID"
+// @Param submission body dto.InspectionSubmissionRequest true "Results"
+// @Success 200 {object} model.Inspection
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/submit [post]
+func (h *InspectionHandler) SubmitInspection(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.InspectionSubmissionRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	inspection, err := h.inspectionService.SubmitInspection(c.Request.Context(), id, request.Notes, request.ToModel(id), c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inspection)
+}
+
+// UploadPhoto godoc
+// @Summary Upload an inspection photo
+// @Description Upload a JPEG or PNG photo taken during a scheduled inspection, optionally of a room or an item of its checklist. The file is stored privately.
+// @Tags Inspections
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path int true "Inspection ID"
+// @Param file formData file true "Photo"
+// @Param room formData string false "Room of the checklist shown"
+// @Param item formData string false "Item of the room shown"
+// @Success 201 {object} model.InspectionPhoto
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 415 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/photos [post]
+func (h *InspectionHandler) UploadPhoto(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	fileBytes, file, ok := readFormFile(c)
+	if !ok {
+		return
+	}
+	contentType, ok := sniffImage(c, fileBytes, "image/jpeg", "image/png")
+	if !ok {
+		return
+	}
+
+	photo := model.InspectionPhoto{
+		InspectionID: id,
+		Room:         c.PostForm("room"),
+		Item:         c.PostForm("item"),
+		Name:         file.Filename,
+		ContentType:  contentType,
+		Size:         file.Size,
+		UploadedBy:   c.GetString(middleware.UsernameKey),
+	}
+	if err := h.inspectionService.CheckPhoto(c.Request.Context(), photo); err != nil {
+		c.Error(err)
+		return
+	}
+
+	key, err := h.s3Service.UploadDocument(c.Request.Context(), fileBytes, fmt.Sprintf("inspection-%d-%s", id, file.Filename), photo.ContentType)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	photo.Key = key
+	if err := h.inspectionService.AddPhoto(c.Request.Context(), &photo); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// DownloadPhoto godoc
+// @Summary Download an inspection photo
+// @Description Redirect to a short-lived link to a photo of an inspection
+// @Tags Inspections
+// @Param id path int true "Inspection ID"
+// @Param photo_id path int true "Photo ID"
+// @Success 307 "Temporary Redirect"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/photos/{photo_id} [get]
+func (h *InspectionHandler) DownloadPhoto(c *gin.Context) {
+	id, photoID, ok := parsePhotoPath(c)
+	if !ok {
+		return
+	}
+
+	photo, err := h.inspectionService.GetPhoto(c.Request.Context(), id, photoID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	url, err := h.s3Service.PresignGetURL(c.Request.Context(), photo.Key, documentLinkTTL)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// DeletePhoto godoc
+// @Summary Delete an inspection photo
+// @Description Delete a photo of a scheduled inspection and its stored file
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Inspection ID"
+// @Param photo_id path int true "Photo ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/photos/{photo_id} [delete]
+func (h *InspectionHandler) DeletePhoto(c *gin.Context) {
+	id, photoID, ok := parsePhotoPath(c)
+	if !ok {
+		return
+	}
+
+	photo, err := h.inspectionService.CheckDeletePhoto(c.Request.Context(), id, photoID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Delete the stored file first, so that a failure leaves the photo
+	// listed and the deletion can be retried.
+	if err := h.s3Service.DeleteDocument(c.Request.Context(), photo.Key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.inspectionService.DeletePhoto(c.Request.Context(), id, photoID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetComparison godoc
+// @Summary Compare an inspection with an earlier one
+// @Description Set the results of a completed inspection side by side with those of a baseline: by default the latest move-in inspection of the same unit completed before it, preferring one of the same lease. Each item is marked better, same or worse on the inspection's rating scale. Returned as PDF with format=pdf or an Accept header of application/pdf.
+// @Tags Inspections
+// @Accept  json
+// @Produce  json
+// @Produce  application/pdf
+// @Param id path int true "Inspection ID"
+// @Param baseline_id query int false "Compare with this completed inspection instead"
+// @Param format query string false "Response format" Enums(json, pdf)
+// @Success 200 {object} model.InspectionComparison
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/comparison [get]
+func (h *InspectionHandler) GetComparison(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	baselineID, ok := parseIDQuery(c, "baseline_id")
+	if !ok {
+		return
+	}
+	pdf, ok := wantsPDF(c)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.inspectionService.GetComparison(c.Request.Context(), id, baselineID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !pdf {
+		c.JSON(http.StatusOK, comparison)
+		return
+	}
+	inspection, err := h.inspectionService.GetInspection(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	place, err := h.inspectionService.Place(c.Request.Context(), inspection)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := report.InspectionComparison(&buf, comparison, place); err != nil {
+		c.Error(err)
+		return
+	}
+	writePDF(c, fmt.Sprintf("inspection-%d-comparison-%d.pdf", id, comparison.Baseline.ID), buf.Bytes())
+}
+
+// GetReport godoc
+// @Summary Get an inspection report
+// @Description Get the report of an inspection as PDF: its checklist with the rating and notes of each item, followed by its photos. At most 50 photos, of 50 MiB in all, are embedded; the rest are listed by name.
+// @Tags Inspections
+// @Produce  application/pdf
+// @Param id path int true "Inspection ID"
+// @Success 200 {file} file
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /inspections/{id}/report [get]
+func (h *InspectionHandler) GetReport(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	inspection, err := h.inspectionService.GetInspection(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	place, err := h.inspectionService.Place(c.Request.Context(), inspection)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	photos := map[uint][]byte{}
+	var size int64
+	for _, photo := range inspection.Photos {
+		if photo.ContentType != "image/jpeg" && photo.ContentType != "image/png" {
+			continue
+		}
+		if len(photos) == maxReportPhotos || size+photo.Size > maxReportPhotoBytes {
+			continue
+		}
+		data, err := h.s3Service.DownloadDocument(c.Request.Context(), photo.Key)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		photos[photo.ID] = data
+		size += int64(len(data))
+	}
+
+	var buf bytes.Buffer
+	if err := report.Inspection(&buf, inspection, place, photos); err != nil {
+		c.Error(err)
+		return
+	}
+	writePDF(c, fmt.Sprintf("inspection-%d-report.pdf", id), buf.Bytes())
+}
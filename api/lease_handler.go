@@ -0,0 +1,318 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// LeaseHandler represents the lease handler.
+type LeaseHandler struct {
+	leaseService *service.LeaseService
+}
+
+// NewLeaseHandler returns a new lease handler.
+func NewLeaseHandler(leaseService *service.LeaseService) *LeaseHandler {
+	return &LeaseHandler{leaseService: leaseService}
+}
+
+// SearchLeases godoc
+// @Summary List leases
+// @Description List leases, latest start first, optionally only those of a property, unit or tenant or with a status
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param property_id query int false "Only leases of this property"
+// @Param unit_id query int false "Only leases of this unit"
+// @Param tenant_id query int false "Only leases of this tenant"
+// @Param status query string false "Only leases with this status" Enums(draft, active, renewing, ended, terminated)
+// @Success 200 {array} model.Lease
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases [get]
+func (h *LeaseHandler) SearchLeases(c *gin.Context) {
+	var filter repository.LeaseFilter
+	var ok bool
+	if filter.PropertyID, ok = parseIDQuery(c, "property_id"); !ok {
+		return
+	}
+	if filter.UnitID, ok = parseIDQuery(c, "unit_id"); !ok {
+		return
+	}
+	if filter.TenantID, ok = parseIDQuery(c, "tenant_id"); !ok {
+		return
+	}
+
+	filter.Status = c.Query("status")
+	switch filter.Status {
+	case "", model.LeaseStatusDraft, model.LeaseStatusActive, model.LeaseStatusRenewing, model.LeaseStatusEnded, model.LeaseStatusTerminated:
+	default:
+		c.Error(apperror.Validation([]apperror.FieldError{{Field: "status", Reason: "must be one of: draft, active, renewing, ended, terminated"}}))
+		return
+	}
+
+	leases, err := h.leaseService.SearchLeases(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leases)
+}
+
+// GetLease godoc
+// @Summary Get a lease
+// @Description Get a lease by ID with its tenants
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Success 200 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id} [get]
+func (h *LeaseHandler) GetLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	lease, err := h.leaseService.GetLease(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lease)
+}
+
+// CreateLease godoc
+// @Summary Draft a lease
+// @Description Draft a lease of a property or unit to one or more tenants
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param lease body dto.LeaseRequest true "Lease"
+// @Success 201 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases [post]
+func (h *LeaseHandler) CreateLease(c *gin.Context) {
+	var request dto.LeaseRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	lease := request.ToModel(0)
+	if err := h.leaseService.CreateLease(c.Request.Context(), &lease); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, lease)
+}
+
+// UpdateLease godoc
+// @Summary Update a draft lease
+// @Description Replace the terms and tenants of a lease that is still a draft
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param lease body dto.LeaseRequest true "Lease"
+// @Success 200 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id} [put]
+func (h *LeaseHandler) UpdateLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.LeaseRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	lease := request.ToModel(id)
+	if err := h.leaseService.UpdateLease(c.Request.Context(), &lease); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lease)
+}
+
+// DeleteLease godoc
+// @Summary Delete a draft lease
+// @Description Delete a lease that is still a draft. Deleting a draft renewal returns the lease it renews to active.
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id} [delete]
+func (h *LeaseHandler) DeleteLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.leaseService.DeleteLease(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// ActivateLease godoc
+// @Summary Activate a lease
+// @Description Put a draft lease in force. It fails if another lease in force covers the same unit for part of its term. Activating a renewal leaves the lease it renews renewing, and billed, until the day after its end date, when the ledger job ends it. Activating a renewing lease abandons its draft renewal.
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Success 200 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/activate [post]
+func (h *LeaseHandler) ActivateLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	lease, err := h.leaseService.ActivateLease(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lease)
+}
+
+// RenewLease godoc
+// @Summary Renew a lease
+// @Description Draft a renewal of an active lease for the same unit and tenants, starting the day after it ends, and mark the lease as renewing. Activate the renewal once it is signed.
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param renewal body dto.RenewLeaseRequest true "Renewal terms"
+// @Success 201 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/renew [post]
+func (h *LeaseHandler) RenewLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.RenewLeaseRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	renewal, err := h.leaseService.RenewLease(c.Request.Context(), id, request.ToModel())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, renewal)
+}
+
+// EndLease godoc
+// @Summary End a lease
+// @Description Record that a lease has run its term. It fails before the lease's end date.
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Success 200 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/end [post]
+func (h *LeaseHandler) EndLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	lease, err := h.leaseService.EndLease(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lease)
+}
+
+// TerminateLease godoc
+// @Summary Terminate a lease early
+// @Description End a lease in force before its end date. The given last day becomes its end date.
+// @Tags Leases
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param termination body dto.TerminateLeaseRequest true "Termination"
+// @Success 200 {object} model.Lease
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/terminate [post]
+func (h *LeaseHandler) TerminateLease(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.TerminateLeaseRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	lease, err := h.leaseService.TerminateLease(c.Request.Context(), id, request.EndDate(), request.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lease)
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/service"
+)
+
+// LedgerHandler represents the handler for lease accounts.
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+}
+
+// NewLedgerHandler returns a new ledger handler.
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// GetLedger godoc
+// @Summary Get a lease's ledger
+// @Description Get the charges, payments and credits of a lease in date order with running balances. Amounts are decimal strings.
+// @Tags Ledger
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Success 200 {object} model.Ledger
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/ledger [get]
+func (h *LedgerHandler) GetLedger(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	ledger, err := h.ledgerService.GetLedger(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// PostCharge godoc
+// @Summary Charge a lease
+// @Description Charge a lease a one-off amount, such as a utility bill. Rent is charged automatically on the billing day.
+// @Tags Ledger
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param charge body dto.ChargeRequest true "Charge"
+// @Success 201 {object} model.LedgerEntry
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/charges [post]
+func (h *LedgerHandler) PostCharge(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.ChargeRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	h.postEntry(c, request.ToModel(id))
+}
+
+// PostPayment godoc
+// @Summary Record a payment
+// @Description Record a payment received against a lease
+// @Tags Ledger
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param payment body dto.PaymentRequest true "Payment"
+// @Success 201 {object} model.LedgerEntry
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/payments [post]
+func (h *LedgerHandler) PostPayment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.PaymentRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	h.postEntry(c, request.ToModel(id))
+}
+
+// PostCredit godoc
+// @Summary Credit a lease
+// @Description Credit a lease's account, for example to waive a fee or correct a charge
+// @Tags Ledger
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param credit body dto.CreditRequest true "Credit"
+// @Success 201 {object} model.LedgerEntry
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/credits [post]
+func (h *LedgerHandler) PostCredit(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.CreditRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	h.postEntry(c, request.ToModel(id))
+}
+
+func (h *LedgerHandler) postEntry(c *gin.Context, entry model.LedgerEntry) {
+	if err := h.ledgerService.PostEntry(c.Request.Context(), &entry, c.GetString(middleware.UsernameKey)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetStatement godoc
+// @Summary Get a tenant statement
+// @Description Get a tenant's statement for a period: for each of their leases, the balance brought forward, the entries in the period with running balances, and the balance carried forward
+// @Tags Ledger
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tenant ID"
+// @Param from query string false "First day of the period (YYYY-MM-DD); defaults to the first day of the month of to"
+// @Param to query string false "Last day of the period (YYYY-MM-DD); defaults to today"
+// @Success 200 {object} model.Statement
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id}/statement [get]
+func (h *LedgerHandler) GetStatement(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	from, to, ok := parsePeriodQuery(c, func(to time.Time) time.Time {
+		return to.AddDate(0, 0, 1-to.Day())
+	})
+	if !ok {
+		return
+	}
+
+	statement, err := h.ledgerService.GetStatement(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// parsePeriodQuery parses the optional from and to query parameters of a
+// period report. To defaults to today and from to defaultFrom(to). It
+// records the error and returns false if either is invalid or from is
+// after to.
+func parsePeriodQuery(c *gin.Context, defaultFrom func(to time.Time) time.Time) (time.Time, time.Time, bool) {
+	var fields []apperror.FieldError
+	from, fromOK := parseDayQuery(c, "from")
+	if !fromOK {
+		fields = append(fields, apperror.FieldError{Field: "from", Reason: "must be a date formatted as YYYY-MM-DD"})
+	}
+	to, toOK := parseDayQuery(c, "to")
+	if !toOK {
+		fields = append(fields, apperror.FieldError{Field: "to", Reason: "must be a date formatted as YYYY-MM-DD"})
+	}
+	if to.IsZero() {
+		to = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if from.IsZero() {
+		from = defaultFrom(to)
+	}
+	if fromOK && toOK && from.After(to) {
+		fields = append(fields, apperror.FieldError{Field: "to", Reason: "must not be before from"})
+	}
+	if len(fields) > 0 {
+		c.Error(apperror.Validation(fields))
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// parseDayQuery parses an optional YYYY-MM-DD query parameter, returning
+// the zero time if it is absent.
+func parseDayQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	day, err := time.Parse(dto.DateLayout, value)
+	return day, err == nil
+}
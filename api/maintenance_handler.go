@@ -0,0 +1,497 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// MaintenanceHandler represents the handler for maintenance requests.
+type MaintenanceHandler struct {
+	maintenanceService *service.MaintenanceService
+	s3Service          *service.S3Service
+}
+
+// NewMaintenanceHandler returns a new maintenance handler.
+func NewMaintenanceHandler(maintenanceService *service.MaintenanceService, s3Service *service.S3Service) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenanceService: maintenanceService, s3Service: s3Service}
+}
+
+// GetPropertyRequests godoc
+// @Summary List a property's maintenance requests
+// @Description List the maintenance requests of a property, soonest due first, optionally filtered
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id query int false "Only requests for this unit"
+// @Param status query string false "Only requests with this status" Enums(open, assigned, in_progress, waiting_on_parts, resolved, closed)
+// @Param priority query string false "Only requests with this priority" Enums(emergency, high, normal, low)
+// @Param category query string false "Only requests in this category" Enums(plumbing, electrical, hvac, appliance, structural, pest, locks, grounds, other)
+// @Param assignee query string false "Only requests assigned to this person"
+// @Param overdue query bool false "Only requests unresolved past their due date"
+// @Success 200 {array} model.MaintenanceRequest
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/maintenance-requests [get]
+func (h *MaintenanceHandler) GetPropertyRequests(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	unitID, ok := parseIDQuery(c, "unit_id")
+	if !ok {
+		return
+	}
+
+	filter := repository.MaintenanceFilter{
+		PropertyID: id,
+		UnitID:     unitID,
+		Status:     c.Query("status"),
+		Priority:   c.Query("priority"),
+		Category:   c.Query("category"),
+		Assignee:   c.Query("assignee"),
+	}
+	var fields []apperror.FieldError
+	fields = checkQueryOneOf(fields, "status", filter.Status, "open", "assigned", "in_progress", "waiting_on_parts", "resolved", "closed")
+	fields = checkQueryOneOf(fields, "priority", filter.Priority, "emergency", "high", "normal", "low")
+	fields = checkQueryOneOf(fields, "category", filter.Category, "plumbing", "electrical", "hvac", "appliance", "structural", "pest", "locks", "grounds", "other")
+	if value := c.Query("overdue"); value != "" {
+		overdue, err := strconv.ParseBool(value)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: "overdue", Reason: "must be true or false"})
+		} else if overdue {
+			filter.OverdueAt = time.Now()
+		}
+	}
+	if len(fields) > 0 {
+		c.Error(apperror.Validation(fields))
+		return
+	}
+
+	requests, err := h.maintenanceService.SearchRequests(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetRequest godoc
+// @Summary Get a maintenance request
+// @Description Get a maintenance request by ID with its photos and comments
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Success 200 {object} model.MaintenanceRequest
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id} [get]
+func (h *MaintenanceHandler) GetRequest(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	request, err := h.maintenanceService.GetRequest(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// CreateRequest godoc
+// @Summary Report a maintenance request
+// @Description Report a problem at a property or unit. The request is open and due to be resolved within the service level for its priority.
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param request body dto.MaintenanceTicketRequest true "Maintenance request"
+// @Success 201 {object} model.MaintenanceRequest
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests [post]
+func (h *MaintenanceHandler) CreateRequest(c *gin.Context) {
+	var body dto.MaintenanceTicketRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	request := body.ToModel(0)
+	if err := h.maintenanceService.CreateRequest(c.Request.Context(), &request); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// UpdateRequest godoc
+// @Summary Update a maintenance request
+// @Description Replace the details of a maintenance request that is not closed. Changing the priority moves the due date to the new priority's service level, counted from when the request was reported.
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param request body dto.MaintenanceTicketRequest true "Maintenance request"
+// @Success 200 {object} model.MaintenanceRequest
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id} [put]
+func (h *MaintenanceHandler) UpdateRequest(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.MaintenanceTicketRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	request := body.ToModel(id)
+	if err := h.maintenanceService.UpdateRequest(c.Request.Context(), &request); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// DeleteRequest godoc
+// @Summary Delete a maintenance request
+// @Description Delete a maintenance request reported by mistake and its stored photos. Requests that work orders have been issued for cannot be deleted.
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id} [delete]
+func (h *MaintenanceHandler) DeleteRequest(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	request, err := h.maintenanceService.CheckDeleteRequest(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	for _, photo := range request.Photos {
+		if err := h.s3Service.DeleteDocument(c.Request.Context(), photo.Key); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := h.maintenanceService.DeleteRequest(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// AssignRequest godoc
+// @Summary Assign a maintenance request
+// @Description Assign a maintenance request that is not closed to someone, or unassign it with an empty assignee. An open request becomes assigned, and an assigned one open again when unassigned. The change is recorded in the comments.
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param assignment body dto.AssignMaintenanceRequest true "Assignment"
+// @Success 200 {object} model.MaintenanceRequest
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/assign [post]
+func (h *MaintenanceHandler) AssignRequest(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.AssignMaintenanceRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	request, err := h.maintenanceService.AssignRequest(c.Request.Context(), id, body.Assignee, body.Note, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ChangeStatus godoc
+// @Summary Change a maintenance request's status
+// @Description Move a maintenance request through its workflow: open → assigned → in_progress ⇄ waiting_on_parts → resolved → closed. Assigned requests may go back to open, resolved ones back to in_progress, and open or assigned ones may be closed without work. The change is recorded in the comments.
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param status body dto.MaintenanceStatusRequest true "Status"
+// @Success 200 {object} model.MaintenanceRequest
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/status [post]
+func (h *MaintenanceHandler) ChangeStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.MaintenanceStatusRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	request, err := h.maintenanceService.ChangeStatus(c.Request.Context(), id, body.Status, body.Note, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// AddComment godoc
+// @Summary Comment on a maintenance request
+// @Description Add a comment to the discussion of a maintenance request
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param comment body dto.MaintenanceCommentRequest true "Comment"
+// @Success 201 {object} model.MaintenanceComment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/comments [post]
+func (h *MaintenanceHandler) AddComment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.MaintenanceCommentRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	comment, err := h.maintenanceService.AddComment(c.Request.Context(), id, body.Body, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UploadPhoto godoc
+// @Summary Upload a maintenance photo
+// @Description Store a private photo of the problem or the repair for a maintenance request
+// @Tags Maintenance
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param file formData file true "Photo"
+// @Success 201 {object} model.MaintenancePhoto
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 415 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/photos [post]
+func (h *MaintenanceHandler) UploadPhoto(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	fileBytes, file, ok := readFormFile(c)
+	if !ok {
+		return
+	}
+	contentType, ok := sniffImage(c, fileBytes)
+	if !ok {
+		return
+	}
+
+	request, err := h.maintenanceService.GetRequest(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if request.Status == model.MaintenanceStatusClosed {
+		c.Error(service.ErrMaintenanceClosed)
+		return
+	}
+
+	key, err := h.s3Service.UploadDocument(c.Request.Context(), fileBytes, fmt.Sprintf("maintenance-%d-%s", id, file.Filename), contentType)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	photo := model.MaintenancePhoto{
+		RequestID:   id,
+		Name:        file.Filename,
+		ContentType: contentType,
+		Size:        file.Size,
+		UploadedBy:  c.GetString(middleware.UsernameKey),
+		Key:         key,
+	}
+	if err := h.maintenanceService.AddPhoto(c.Request.Context(), &photo); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// DownloadPhoto godoc
+// @Summary Download a maintenance photo
+// @Description Redirect to a short-lived link to a photo of a maintenance request
+// @Tags Maintenance
+// @Param id path int true "Maintenance request ID"
+// @Param photo_id path int true "Photo ID"
+// @Success 307 "Temporary Redirect"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/photos/{photo_id} [get]
+func (h *MaintenanceHandler) DownloadPhoto(c *gin.Context) {
+	id, photoID, ok := parsePhotoPath(c)
+	if !ok {
+		return
+	}
+
+	photo, err := h.maintenanceService.GetPhoto(c.Request.Context(), id, photoID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	url, err := h.s3Service.PresignGetURL(c.Request.Context(), photo.Key, documentLinkTTL)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// DeletePhoto godoc
+// @Summary Delete a maintenance photo
+// @Description Delete a photo of a maintenance request that is not closed and its stored file
+// @Tags Maintenance
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param photo_id path int true "Photo ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/photos/{photo_id} [delete]
+func (h *MaintenanceHandler) DeletePhoto(c *gin.Context) {
+	id, photoID, ok := parsePhotoPath(c)
+	if !ok {
+		return
+	}
+
+	photo, err := h.maintenanceService.CheckDeletePhoto(c.Request.Context(), id, photoID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Delete the stored file first, so that a failure leaves the photo
+	// listed and the deletion can be retried.
+	if err := h.s3Service.DeleteDocument(c.Request.Context(), photo.Key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.maintenanceService.DeletePhoto(c.Request.Context(), id, photoID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// parsePhotoPath parses the parent and photo IDs of a photo route.
+func parsePhotoPath(c *gin.Context) (uint, uint, bool) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	photoID, ok := parseID(c, "photo_id")
+	return id, photoID, ok
+}
+
+// checkQueryOneOf appends a field error to fields if the query parameter
+// name has a value other than one of allowed.
+func checkQueryOneOf(fields []apperror.FieldError, name string, value string, allowed ...string) []apperror.FieldError {
+	if value == "" {
+		return fields
+	}
+	for _, a := range allowed {
+		if value == a {
+			return fields
+		}
+	}
+	return append(fields, apperror.FieldError{Field: name, Reason: "must be one of: " + strings.Join(allowed, ", ")})
+}
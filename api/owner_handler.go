@@ -0,0 +1,441 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/report"
+	"propmanager/internal/app/service"
+)
+
+// OwnerHandler represents the handler for owners, their shares of
+// properties, management agreements and owner statements.
+type OwnerHandler struct {
+	ownerService *service.OwnerService
+}
+
+// NewOwnerHandler returns a new owner handler.
+func NewOwnerHandler(ownerService *service.OwnerService) *OwnerHandler {
+	return &OwnerHandler{ownerService: ownerService}
+}
+
+// SearchOwners godoc
+// @Summary Search owners
+// @Description List owners ordered by name with their shares of properties, optionally matching part of their name or email address
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Part of a name or email address"
+// @Success 200 {array} model.Owner
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /owners [get]
+func (h *OwnerHandler) SearchOwners(c *gin.Context) {
+	owners, err := h.ownerService.SearchOwners(c.Request.Context(), c.Query("q"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, owners)
+}
+
+// GetOwner godoc
+// @Summary Get an owner
+// @Description Get an owner by ID with their shares of properties
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Owner ID"
+// @Success 200 {object} model.Owner
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /owners/{id} [get]
+func (h *OwnerHandler) GetOwner(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	owner, err := h.ownerService.GetOwner(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, owner)
+}
+
+// CreateOwner godoc
+// @Summary Create an owner
+// @Description Create an owner on whose behalf properties are managed
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param owner body dto.OwnerRequest true "Owner"
+// @Success 201 {object} model.Owner
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /owners [post]
+func (h *OwnerHandler) CreateOwner(c *gin.Context) {
+	var request dto.OwnerRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	owner := request.ToModel(0)
+	if err := h.ownerService.CreateOwner(c.Request.Context(), &owner); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, owner)
+}
+
+// UpdateOwner godoc
+// @Summary Update an owner
+// @Description Replace an owner's details
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Owner ID"
+// @Param owner body dto.OwnerRequest true "Owner"
+// @Success 200 {object} model.Owner
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /owners/{id} [put]
+func (h *OwnerHandler) UpdateOwner(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.OwnerRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	owner := request.ToModel(id)
+	if err := h.ownerService.UpdateOwner(c.Request.Context(), &owner); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, owner)
+}
+
+// DeleteOwner godoc
+// @Summary Delete an owner
+// @Description Delete an owner and their shares of properties
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Owner ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /owners/{id} [delete]
+func (h *OwnerHandler) DeleteOwner(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.ownerService.DeleteOwner(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetPropertyOwners godoc
+// @Summary List a property's owners
+// @Description List the owners of a property with their percentage shares, largest first
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Success 200 {array} model.Ownership
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/owners [get]
+func (h *OwnerHandler) GetPropertyOwners(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	ownerships, err := h.ownerService.GetPropertyOwners(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ownerships)
+}
+
+// SetPropertyOwners godoc
+// @Summary Set a property's owners
+// @Description Replace the owners of a property and their percentage shares, which add up to at most 100
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param owners body dto.PropertyOwnersRequest true "Owners"
+// @Success 200 {array} model.Ownership
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/owners [put]
+func (h *OwnerHandler) SetPropertyOwners(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyOwnersRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	ownerships, err := h.ownerService.SetPropertyOwners(c.Request.Context(), id, request.ToModel(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ownerships)
+}
+
+// GetAgreements godoc
+// @Summary List a property's management agreements
+// @Description List the management agreements of a property, latest first
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Success 200 {array} model.ManagementAgreement
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/management-agreements [get]
+func (h *OwnerHandler) GetAgreements(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	agreements, err := h.ownerService.GetAgreements(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, agreements)
+}
+
+// CreateAgreement godoc
+// @Summary Create a management agreement
+// @Description Agree the fee for managing a property over a period: a percentage of the rent collected plus a flat fee, each month. Agreements of a property may not overlap.
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param agreement body dto.ManagementAgreementRequest true "Management agreement"
+// @Success 201 {object} model.ManagementAgreement
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/management-agreements [post]
+func (h *OwnerHandler) CreateAgreement(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.ManagementAgreementRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	agreement := request.ToModel(0, id)
+	if err := h.ownerService.CreateAgreement(c.Request.Context(), &agreement); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, agreement)
+}
+
+// UpdateAgreement godoc
+// @Summary Update a management agreement
+// @Description Replace the terms of a management agreement, for example to end it
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param agreement_id path int true "Management agreement ID"
+// @Param agreement body dto.ManagementAgreementRequest true "Management agreement"
+// @Success 200 {object} model.ManagementAgreement
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/management-agreements/{agreement_id} [put]
+func (h *OwnerHandler) UpdateAgreement(c *gin.Context) {
+	id, agreementID, ok := parseAgreementPath(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ManagementAgreementRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	agreement := request.ToModel(agreementID, id)
+	if err := h.ownerService.UpdateAgreement(c.Request.Context(), &agreement); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, agreement)
+}
+
+// DeleteAgreement godoc
+// @Summary Delete a management agreement
+// @Description Delete a management agreement made by mistake
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param agreement_id path int true "Management agreement ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/management-agreements/{agreement_id} [delete]
+func (h *OwnerHandler) DeleteAgreement(c *gin.Context) {
+	id, agreementID, ok := parseAgreementPath(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ownerService.DeleteAgreement(c.Request.Context(), id, agreementID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetOwnerStatement godoc
+// @Summary Get an owner statement
+// @Description Get an owner's statement for a month: for each property they own a share of, the rent collected less refunds, the expenses paid, the management fee and the owner's share of the net. Returned as PDF with format=pdf or an Accept header of application/pdf.
+// @Tags Owners
+// @Accept  json
+// @Produce  json
+// @Produce  application/pdf
+// @Param id path int true "Owner ID"
+// @Param month path string true "Month (YYYY-MM)"
+// @Param format query string false "Response format" Enums(json, pdf)
+// @Success 200 {object} model.OwnerStatement
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /owners/{id}/statements/{month} [get]
+func (h *OwnerHandler) GetOwnerStatement(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	month, err := time.Parse("2006-01", c.Param("month"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid_path_parameter", "Path parameter month must be a month formatted as YYYY-MM.").Wrap(err))
+		return
+	}
+	pdf, ok := wantsPDF(c)
+	if !ok {
+		return
+	}
+
+	statement, err := h.ownerService.GetOwnerStatement(c.Request.Context(), id, month)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !pdf {
+		c.JSON(http.StatusOK, statement)
+		return
+	}
+	var buf bytes.Buffer
+	if err := report.OwnerStatement(&buf, statement); err != nil {
+		c.Error(err)
+		return
+	}
+	writePDF(c, fmt.Sprintf("owner-%d-statement-%s.pdf", id, statement.Month), buf.Bytes())
+}
+
+// parseAgreementPath parses the property and agreement IDs of a management
+// agreement route.
+func parseAgreementPath(c *gin.Context) (uint, uint, bool) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	agreementID, ok := parseID(c, "agreement_id")
+	return id, agreementID, ok
+}
+
+// wantsPDF reports whether the client asked for a PDF document, by the
+// format query parameter or else the Accept header, rather than JSON. It
+// records the error and returns false if the format is unknown.
+func wantsPDF(c *gin.Context) (bool, bool) {
+	switch c.Query("format") {
+	case "pdf":
+		return true, true
+	case "json":
+		return false, true
+	case "":
+		return c.NegotiateFormat(gin.MIMEJSON, report.ContentType) == report.ContentType, true
+	}
+	c.Error(apperror.Validation([]apperror.FieldError{{Field: "format", Reason: "must be one of: json, pdf"}}))
+	return false, false
+}
+
+// writePDF sends a PDF document to be saved as fileName.
+func writePDF(c *gin.Context, fileName string, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, report.ContentType, data)
+}
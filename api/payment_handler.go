@@ -0,0 +1,185 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/service"
+	"propmanager/internal/payments"
+)
+
+// maxWebhookBytes bounds the size of a webhook request body.
+const maxWebhookBytes = 1 << 20
+
+// PaymentHandler represents the handler for online payments.
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+	provider       payments.Provider
+}
+
+// NewPaymentHandler returns a new payment handler.
+func NewPaymentHandler(paymentService *service.PaymentService, provider payments.Provider) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService, provider: provider}
+}
+
+// CreatePaymentIntent godoc
+// @Summary Start an online payment
+// @Description Start an online payment towards a lease with the payment provider. Without a charge it pays the lease's balance, and with one what is unpaid of that charge; an amount pays part of it instead. The payer completes the payment with the returned client secret, and the payment is posted to the ledger when the provider reports it succeeded.
+// @Tags Payments
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Param payment body dto.PaymentIntentRequest true "Payment"
+// @Success 201 {object} model.PaymentIntent
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/payment-intents [post]
+func (h *PaymentHandler) CreatePaymentIntent(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.PaymentIntentRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	intent, err := h.paymentService.CreatePaymentIntent(c.Request.Context(), id, request.ChargeID, request.Amount)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, intent)
+}
+
+// GetPaymentIntents godoc
+// @Summary List a lease's online payments
+// @Description List the online payments started towards a lease, newest first
+// @Tags Payments
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Lease ID"
+// @Success 200 {array} model.PaymentIntent
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /leases/{id}/payment-intents [get]
+func (h *PaymentHandler) GetPaymentIntents(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	intents, err := h.paymentService.GetPaymentIntents(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intents)
+}
+
+// HandleWebhook godoc
+// @Summary Receive a payment provider webhook
+// @Description Receive an event from the payment provider, verified by its signature header. Succeeded payments are posted to the lease's ledger, refunds are charged back and payouts are reconciled against recorded payments. Redelivered events are acknowledged and ignored.
+// @Tags Payments
+// @Accept  json
+// @Produce  json
+// @Param Stripe-Signature header string true "Webhook signature"
+// @Success 204
+// @Failure 400 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /webhooks/payments [post]
+func (h *PaymentHandler) HandleWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid_body", "Request body could not be read.").Wrap(err))
+		return
+	}
+
+	event, err := h.provider.ParseWebhook(payload, c.Request.Header)
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		c.Error(apperror.BadRequest("invalid_signature", "Webhook signature is missing or invalid.").Wrap(err))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid_event", "Webhook event could not be parsed.").Wrap(err))
+		return
+	}
+
+	if err := h.paymentService.HandleEvent(c.Request.Context(), event); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetPayouts godoc
+// @Summary List payouts
+// @Description List the payouts from the payment provider, newest first, with how they reconciled against recorded payments
+// @Tags Payments
+// @Accept  json
+// @Produce  json
+// @Param status query string false "Only payouts with this status" Enums(reconciled, discrepancy)
+// @Success 200 {array} model.Payout
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /payouts [get]
+func (h *PaymentHandler) GetPayouts(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != model.PayoutStatusReconciled && status != model.PayoutStatusDiscrepancy {
+		c.Error(apperror.Validation([]apperror.FieldError{{Field: "status", Reason: "must be one of: reconciled, discrepancy"}}))
+		return
+	}
+
+	payouts, err := h.paymentService.GetPayouts(c.Request.Context(), status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payouts)
+}
+
+// GetPayout godoc
+// @Summary Get a payout
+// @Description Get a payout with the payments it settled
+// @Tags Payments
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Payout ID"
+// @Success 200 {object} model.Payout
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /payouts/{id} [get]
+func (h *PaymentHandler) GetPayout(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	payout, err := h.paymentService.GetPayout(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
@@ -1,16 +1,27 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
-	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/middleware"
 	"propmanager/internal/app/model"
 	"propmanager/internal/app/service"
 )
 
+var (
+	errIfMatchRequired = apperror.PreconditionRequired("if_match_required", "The If-Match header is required.")
+	errFileRequired    = apperror.BadRequest("file_required", "A file must be uploaded in the \"file\" form field.")
+)
+
 // PropertyHandler represents the property handler.
 type PropertyHandler struct {
 	propertyService *service.PropertyService
@@ -29,13 +40,12 @@ func NewPropertyHandler(propertyService *service.PropertyService, s3Service *ser
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} model.Property
-// @Failure 500 {object} map[string]string
+// @Failure 500 {object} middleware.Problem
 // @Router /properties [get]
 func (h *PropertyHandler) GetAllProperties(c *gin.Context) {
-	properties, err := h.propertyService.GetAllProperties()
+	properties, err := h.propertyService.GetAllProperties(c.Request.Context())
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -49,22 +59,30 @@ func (h *PropertyHandler) GetAllProperties(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Property ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} model.Property
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 304 "Not Modified"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Header 200 {string} ETag "Current version of the property"
 // @Router /properties/{id} [get]
 func (h *PropertyHandler) GetProperty(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	property, err := h.propertyService.GetProperty(uint(id))
+	property, err := h.propertyService.GetProperty(c.Request.Context(), id)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	etag := propertyETag(property)
+	c.Header("ETag", etag)
+	if matchesETag(c.GetHeader("If-None-Match"), etag, true) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -77,23 +95,22 @@ func (h *PropertyHandler) GetProperty(c *gin.Context) {
 // @Tags Properties
 // @Accept  json
 // @Produce  json
-// @Param property body model.Property true "Property"
+// @Param property body dto.CreatePropertyRequest true "Property"
 // @Success 201 {object} model.Property
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Security ApiKeyAuth
 // @Router /properties [post]
 func (h *PropertyHandler) CreateProperty(c *gin.Context) {
-	var property model.Property
-	if err := c.BindJSON(&property); err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request dto.CreatePropertyRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
-	if err := h.propertyService.CreateProperty(&property); err != nil {
+	property := request.ToModel()
+	if err := h.propertyService.CreateProperty(c.Request.Context(), &property, c.GetString(middleware.UsernameKey)); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -107,35 +124,95 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Property ID"
-// @Param property body model.Property true "Property"
+// @Param If-Match header string true "ETag of the version being updated"
+// @Param property body dto.UpdatePropertyRequest true "Property"
 // @Success 200 {object} model.Property
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 428 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Header 200 {string} ETag "New version of the property"
 // @Security ApiKeyAuth
 // @Router /properties/{id} [put]
 func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.requireIfMatch(c, id, h.propertyService.GetProperty)
+	if !ok {
+		return
+	}
+
+	var request dto.UpdatePropertyRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
-	var property model.Property
-	if err := c.BindJSON(&property); err != nil {
+	property := request.ToModel(id)
+	if err := h.propertyService.UpdateProperty(c.Request.Context(), &property, expectedVersion, c.GetString(middleware.UsernameKey)); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	property.ID = uint(id)
+	c.Header("ETag", propertyETag(property))
+	c.JSON(http.StatusOK, property)
+}
+
+// PatchProperty godoc
+// @Summary Patch a property
+// @Description Apply a JSON Merge Patch or JSON Patch document to the editable fields of a property
+// @Tags Properties
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param If-Match header string true "ETag of the version being patched"
+// @Param patch body object true "Merge patch object or JSON Patch operation array"
+// @Success 200 {object} model.Property
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 415 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 428 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Header 200 {string} ETag "New version of the property"
+// @Security ApiKeyAuth
+// @Router /properties/{id} [patch]
+func (h *PropertyHandler) PatchProperty(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || (contentType != service.MergePatchContentType && contentType != service.JSONPatchContentType) {
+		c.Error(service.ErrUnsupportedPatch)
+		return
+	}
+
+	expectedVersion, ok := h.requireIfMatch(c, id, h.propertyService.GetProperty)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(service.ErrInvalidPatch.Wrap(err))
+		return
+	}
 
-	if err := h.propertyService.UpdateProperty(&property); err != nil {
+	property, err := h.propertyService.PatchProperty(c.Request.Context(), id, expectedVersion, contentType, patch, c.GetString(middleware.UsernameKey))
+	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", propertyETag(property))
 	c.JSON(http.StatusOK, property)
 }
 
@@ -146,22 +223,28 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Property ID"
-// @Success 204 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param If-Match header string true "ETag of the version being deleted"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 428 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Security ApiKeyAuth
 // @Router /properties/{id} [delete]
 func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.requireIfMatch(c, id, h.propertyService.GetProperty)
+	if !ok {
 		return
 	}
 
-	if err := h.propertyService.DeleteProperty(uint(id)); err != nil {
+	if err := h.propertyService.DeleteProperty(c.Request.Context(), id, expectedVersion, c.GetString(middleware.UsernameKey)); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -177,37 +260,24 @@ func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
 // @Param id path int true "Property ID"
 // @Param file formData file true "Image file"
 // @Success 201 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Security ApiKeyAuth
 // @Router /properties/{id}/images [post]
 func (h *PropertyHandler) UploadImage(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
-	openedFile, err := file.Open()
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	fileBytes, file, ok := readFormFile(c)
+	if !ok {
 		return
 	}
 
-	fileBytes := make([]byte, file.Size)
-	_, err = openedFile.Read(fileBytes)
-	if err != nil {
+	if _, err := h.propertyService.GetProperty(c.Request.Context(), id); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -215,22 +285,11 @@ func (h *PropertyHandler) UploadImage(c *gin.Context) {
 	url, err := h.s3Service.UploadImage(c.Request.Context(), fileBytes, fileName)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	property, err := h.propertyService.GetProperty(uint(id))
-	if err != nil {
-		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	property.Images = append(property.Images, model.Image{URL: url})
-
-	if err := h.propertyService.UpdateProperty(&property); err != nil {
+	if _, err := h.propertyService.AddImage(c.Request.Context(), id, url, file.Size, c.GetString(middleware.UsernameKey)); err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -245,31 +304,184 @@ func (h *PropertyHandler) UploadImage(c *gin.Context) {
 // @Produce  json
 // @Param id path int true "Property ID"
 // @Param image_id path int true "Image ID"
-// @Success 204 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
 // @Security ApiKeyAuth
 // @Router /properties/{id}/images/{image_id} [delete]
 func (h *PropertyHandler) DeleteImage(c *gin.Context) {
-	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	propertyID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	imageID, ok := parseID(c, "image_id")
+	if !ok {
+		return
+	}
+
+	if err := h.propertyService.DeleteImage(c.Request.Context(), propertyID, imageID, c.GetString(middleware.UsernameKey)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// GetPropertyHistory godoc
+// @Summary Get property history
+// @Description Get every recorded version of a property, newest first
+// @Tags Properties
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Success 200 {array} model.PropertyVersion
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/history [get]
+func (h *PropertyHandler) GetPropertyHistory(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	versions, err := h.propertyService.GetPropertyHistory(c.Request.Context(), id)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	imageID, err := strconv.ParseUint(c.Param("image_id"), 10, 64)
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetPropertyVersion godoc
+// @Summary Get a property version
+// @Description Get a single recorded version of a property
+// @Tags Properties
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} model.PropertyVersion
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/history/{version} [get]
+func (h *PropertyHandler) GetPropertyVersion(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	version, ok := parseID(c, "version")
+	if !ok {
+		return
+	}
+
+	propertyVersion, err := h.propertyService.GetPropertyVersion(c.Request.Context(), id, version)
 	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.propertyService.DeleteImage(uint(propertyID), uint(imageID)); err != nil {
+	c.JSON(http.StatusOK, propertyVersion)
+}
+
+// RestorePropertyVersion godoc
+// @Summary Restore a property version
+// @Description Restore a property, including a deleted one, to an earlier version
+// @Tags Properties
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param version path int true "Version number"
+// @Param If-Match header string true "ETag of the version being replaced"
+// @Success 200 {object} model.Property
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 412 {object} middleware.Problem
+// @Failure 428 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Header 200 {string} ETag "New version of the property"
+// @Security ApiKeyAuth
+// @Router /properties/{id}/history/{version}/restore [post]
+func (h *PropertyHandler) RestorePropertyVersion(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	version, ok := parseID(c, "version")
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.requireIfMatch(c, id, h.propertyService.GetPropertyUnscoped)
+	if !ok {
+		return
+	}
+
+	property, err := h.propertyService.RestorePropertyVersion(c.Request.Context(), id, version, expectedVersion, c.GetString(middleware.UsernameKey))
+	if err != nil {
 		c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusNoContent, gin.H{})
+	c.Header("ETag", propertyETag(property))
+	c.JSON(http.StatusOK, property)
+}
+
+// propertyETag returns the strong entity tag of a property: its version,
+// which is bumped whenever its own fields or images change. Unit occupancy
+// is derived from the units and does not change the tag.
+func propertyETag(property model.Property) string {
+	return fmt.Sprintf("\"%d\"", property.Version)
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header value
+// lists etag. With weak set, weak validators are compared by their opaque
+// tag; otherwise they never match, as If-Match requires (RFC 7232 §3.1).
+func matchesETag(header string, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// requireIfMatch resolves the If-Match header of a modifying request into
+// the property version the client expects to change, looking the property up
+// with get. It records the error and returns false if the header is missing or stale.
+func (h *PropertyHandler) requireIfMatch(c *gin.Context, id uint, get func(context.Context, uint) (model.Property, error)) (uint, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.Error(errIfMatchRequired)
+		return 0, false
+	}
+
+	property, err := get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return 0, false
+	}
+
+	if !matchesETag(header, propertyETag(property), false) {
+		c.Error(service.ErrVersionMismatch)
+		return 0, false
+	}
+
+	return property.Version, true
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// newPropertyRouter serves the property routes over a fresh database that
+// holds one property, Elm, at version 1.
+func newPropertyRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Property{}, &model.Image{}, &model.PropertyVersion{}, &model.Unit{}); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := service.NewPropertyService(repository.NewPropertyRepository(db))
+	elm := model.Property{Name: "Elm", Location: "1 Elm St", Price: 1000}
+	if err := properties.CreateProperty(context.Background(), &elm, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewPropertyHandler(properties, nil)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.ErrorHandler())
+	r.GET("/properties/:id", h.GetProperty)
+	r.PUT("/properties/:id", h.UpdateProperty)
+	r.PATCH("/properties/:id", h.PatchProperty)
+	r.DELETE("/properties/:id", h.DeleteProperty)
+	r.GET("/properties/:id/history", h.GetPropertyHistory)
+	r.POST("/properties/:id/history/:version/restore", h.RestorePropertyVersion)
+	return r, db
+}
+
+func serve(r *gin.Engine, method string, target string, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestPropertyPreconditions checks that modifying a property requires a
+// strong If-Match on its current version, and that its ETag does not move
+// when only its units change.
+func TestPropertyPreconditions(t *testing.T) {
+	r, db := newPropertyRouter(t)
+	update := `{"name":"Oak","location":"1 Elm St","price":1200}`
+
+	w := serve(r, http.MethodGet, "/properties/1", "")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("GET = %d with ETag %s, want 200 with \"1\"", w.Code, w.Header().Get("ETag"))
+	}
+
+	if err := db.Create(&model.Unit{PropertyID: 1, Number: "1A", Status: model.UnitStatusVacant}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(r, http.MethodGet, "/properties/1", "", "If-None-Match", `W/"1"`); w.Code != http.StatusNotModified {
+		t.Errorf("GET after adding a unit with If-None-Match = %d, want 304", w.Code)
+	}
+
+	tests := []struct {
+		name    string
+		ifMatch []string
+		want    int
+	}{
+		{"missing", nil, http.StatusPreconditionRequired},
+		{"stale", []string{"If-Match", `"2"`}, http.StatusPreconditionFailed},
+		{"weak", []string{"If-Match", `W/"1"`}, http.StatusPreconditionFailed},
+		{"current", []string{"If-Match", `"7", "1"`}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(r, http.MethodPut, "/properties/1", update, tt.ifMatch...)
+		if w.Code != tt.want {
+			t.Errorf("PUT with %s If-Match = %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+		if w.Code == http.StatusPreconditionFailed && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/problem+json") {
+			t.Errorf("412 Content-Type = %q, want application/problem+json", w.Header().Get("Content-Type"))
+		}
+	}
+	if w := serve(r, http.MethodGet, "/properties/1", ""); w.Header().Get("ETag") != `"2"` {
+		t.Errorf("ETag after update = %s, want \"2\"", w.Header().Get("ETag"))
+	}
+}
+
+// TestRestorePropertyVersion deletes a property and restores its first
+// version through the API, which requires If-Match on the deleted property.
+func TestRestorePropertyVersion(t *testing.T) {
+	r, _ := newPropertyRouter(t)
+
+	if w := serve(r, http.MethodDelete, "/properties/1", "", "If-Match", `"1"`); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d, want 204: %s", w.Code, w.Body)
+	}
+	if w := serve(r, http.MethodGet, "/properties/1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("GET after DELETE = %d, want 404", w.Code)
+	}
+
+	w := serve(r, http.MethodGet, "/properties/1/history", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"action":"delete"`) {
+		t.Fatalf("history of the deleted property = %d: %s", w.Code, w.Body)
+	}
+	if w := serve(r, http.MethodGet, "/properties/9/history", ""); w.Code != http.StatusNotFound {
+		t.Errorf("history of a missing property = %d, want 404", w.Code)
+	}
+
+	if w := serve(r, http.MethodPost, "/properties/1/history/1/restore", ""); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("restore without If-Match = %d, want 428", w.Code)
+	}
+	if w := serve(r, http.MethodPost, "/properties/1/history/1/restore", "", "If-Match", `"0"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("restore with a stale If-Match = %d, want 412", w.Code)
+	}
+	if w := serve(r, http.MethodPost, "/properties/1/history/2/restore", "", "If-Match", `"1"`); w.Code != http.StatusConflict {
+		t.Errorf("restore of the deleted version = %d, want 409", w.Code)
+	}
+	w = serve(r, http.MethodPost, "/properties/1/history/1/restore", "", "If-Match", `"1"`)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("restore = %d with ETag %s, want 200 with \"2\": %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	if w := serve(r, http.MethodGet, "/properties/1", ""); w.Code != http.StatusOK {
+		t.Errorf("GET after restore = %d, want 200", w.Code)
+	}
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"propmanager/internal/app/apperror"
 	"propmanager/internal/app/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,7 +17,56 @@ func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
 	return &StatsHandler{statsService: statsService}
 }
 
+// GetStats godoc
+// @Summary Get portfolio statistics
+// @Description Get property counts, price statistics, weekly listings and image storage for the properties created in a date range
+// @Tags Stats
+// @Accept  json
+// @Produce  json
+// @Param from query string false "Start of the range, inclusive (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "End of the range, exclusive (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} model.PortfolioStats
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /stats [get]
 func (h *StatsHandler) GetStats(c *gin.Context) {
-	stats := h.statsService.GetStats()
+	var fields []apperror.FieldError
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		fields = append(fields, apperror.FieldError{Field: "from", Reason: "must be a date (YYYY-MM-DD) or RFC 3339 timestamp"})
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		fields = append(fields, apperror.FieldError{Field: "to", Reason: "must be a date (YYYY-MM-DD) or RFC 3339 timestamp"})
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		fields = append(fields, apperror.FieldError{Field: "to", Reason: "must be after from"})
+	}
+	if len(fields) > 0 {
+		c.Error(apperror.Validation(fields))
+		return
+	}
+
+	stats, err := h.statsService.GetStats(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
+
+// parseDateQuery parses an optional date or timestamp query parameter.
+func parseDateQuery(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, true
+		}
+	}
+	return nil, false
+}
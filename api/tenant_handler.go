@@ -0,0 +1,336 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// documentLinkTTL is how long a document download link stays valid.
+const documentLinkTTL = 15 * time.Minute
+
+// TenantHandler represents the tenant handler.
+type TenantHandler struct {
+	tenantService *service.TenantService
+	s3Service     *service.S3Service
+}
+
+// NewTenantHandler returns a new tenant handler.
+func NewTenantHandler(tenantService *service.TenantService, s3Service *service.S3Service) *TenantHandler {
+	return &TenantHandler{tenantService: tenantService, s3Service: s3Service}
+}
+
+// SearchTenants godoc
+// @Summary Search tenants
+// @Description List tenants ordered by name, optionally matching part of their name, email address or phone number, or linked to a property or unit
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Part of a name, email address or phone number"
+// @Param property_id query int false "Only tenants of this property"
+// @Param unit_id query int false "Only tenants of this unit"
+// @Success 200 {array} model.Tenant
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants [get]
+func (h *TenantHandler) SearchTenants(c *gin.Context) {
+	propertyID, ok := parseIDQuery(c, "property_id")
+	if !ok {
+		return
+	}
+	unitID, ok := parseIDQuery(c, "unit_id")
+	if !ok {
+		return
+	}
+
+	tenants, err := h.tenantService.SearchTenants(c.Request.Context(), repository.TenantFilter{
+		Query:      c.Query("q"),
+		PropertyID: propertyID,
+		UnitID:     unitID,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenants)
+}
+
+// GetTenant godoc
+// @Summary Get a tenant
+// @Description Get a tenant by ID with their emergency contacts and documents
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tenant ID"
+// @Success 200 {object} model.Tenant
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id} [get]
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.GetTenant(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// CreateTenant godoc
+// @Summary Create a tenant
+// @Description Create a tenant, optionally linked to a property or unit
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param tenant body dto.TenantRequest true "Tenant"
+// @Success 201 {object} model.Tenant
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants [post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var request dto.TenantRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	tenant := request.ToModel(0)
+	if err := h.tenantService.CreateTenant(c.Request.Context(), &tenant); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tenant)
+}
+
+// UpdateTenant godoc
+// @Summary Update a tenant
+// @Description Replace a tenant's details and emergency contacts
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tenant ID"
+// @Param tenant body dto.TenantRequest true "Tenant"
+// @Success 200 {object} model.Tenant
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id} [put]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.TenantRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	tenant := request.ToModel(id)
+	if err := h.tenantService.UpdateTenant(c.Request.Context(), &tenant); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// DeleteTenant godoc
+// @Summary Delete a tenant
+// @Description Delete a tenant and their stored documents
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tenant ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.GetTenant(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	for _, document := range tenant.Documents {
+		if err := h.s3Service.DeleteDocument(c.Request.Context(), document.Key); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := h.tenantService.DeleteTenant(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// UploadDocument godoc
+// @Summary Upload a tenant document
+// @Description Store a private document, such as a signed application, for a tenant. Documents must be PDFs, images or plain text.
+// @Tags Tenants
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path int true "Tenant ID"
+// @Param file formData file true "Document"
+// @Param name formData string false "Display name; defaults to the file name"
+// @Success 201 {object} model.TenantDocument
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 415 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id}/documents [post]
+func (h *TenantHandler) UploadDocument(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	fileBytes, file, ok := readFormFile(c)
+	if !ok {
+		return
+	}
+	contentType, ok := sniffDocument(c, fileBytes)
+	if !ok {
+		return
+	}
+
+	if _, err := h.tenantService.GetTenant(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	key, err := h.s3Service.UploadDocument(c.Request.Context(), fileBytes, fmt.Sprintf("tenant-%d-%s", id, file.Filename), contentType)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = file.Filename
+	}
+	document := model.TenantDocument{TenantID: id, Name: name, ContentType: contentType, Size: file.Size, Key: key}
+	if err := h.tenantService.AddDocument(c.Request.Context(), &document); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// DownloadDocument godoc
+// @Summary Download a tenant document
+// @Description Redirect to a short-lived link to a tenant's document
+// @Tags Tenants
+// @Param id path int true "Tenant ID"
+// @Param document_id path int true "Document ID"
+// @Success 307 "Temporary Redirect"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id}/documents/{document_id} [get]
+func (h *TenantHandler) DownloadDocument(c *gin.Context) {
+	id, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.tenantService.GetDocument(c.Request.Context(), id, documentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	url, err := h.s3Service.PresignGetURL(c.Request.Context(), document.Key, documentLinkTTL)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// DeleteDocument godoc
+// @Summary Delete a tenant document
+// @Description Delete a tenant's document and its stored file
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tenant ID"
+// @Param document_id path int true "Document ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /tenants/{id}/documents/{document_id} [delete]
+func (h *TenantHandler) DeleteDocument(c *gin.Context) {
+	id, documentID, ok := parseDocumentPath(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.tenantService.GetDocument(c.Request.Context(), id, documentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Delete the stored file first, so that a failure leaves the document
+	// listed and the deletion can be retried.
+	if err := h.s3Service.DeleteDocument(c.Request.Context(), document.Key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.tenantService.DeleteDocument(c.Request.Context(), id, documentID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// parseDocumentPath parses the tenant and document IDs of a document route.
+func parseDocumentPath(c *gin.Context) (uint, uint, bool) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	documentID, ok := parseID(c, "document_id")
+	return id, documentID, ok
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+	"propmanager/internal/config"
+)
+
+// fakeS3 records the object requests it receives. It refuses deletes while
+// denyDeletes is set.
+type fakeS3 struct {
+	mu          sync.Mutex
+	requests    []string
+	contentType string
+	denyDeletes bool
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	switch {
+	case r.Method == http.MethodPut:
+		s.contentType = r.Header.Get("Content-Type")
+	case r.Method == http.MethodDelete && s.denyDeletes:
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+		return
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+}
+
+func (s *fakeS3) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func upload(r *gin.Engine, target string, filename string, declaredType string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", declaredType)
+	part, _ := form.CreatePart(header)
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestTenantDocuments uploads, downloads and deletes a tenant's documents
+// against a fake S3 endpoint.
+func TestTenantDocuments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s3 := &fakeS3{}
+	s3Server := httptest.NewServer(s3)
+	t.Cleanup(s3Server.Close)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Property{}, &model.Unit{}, &model.Tenant{}, &model.EmergencyContact{}, &model.TenantDocument{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&model.Tenant{FirstName: "Ada", LastName: "Lovelace"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{S3Endpoint: s3Server.URL, S3Region: "us-east-1", S3Bucket: "documents", S3AccessKey: "AKIATEST", S3SecretKey: "secret"}
+	h := NewTenantHandler(service.NewTenantService(repository.NewTenantRepository(db)), service.NewS3Service(&cfg))
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/tenants/:id/documents", h.UploadDocument)
+	r.GET("/tenants/:id/documents/:document_id", h.DownloadDocument)
+	r.DELETE("/tenants/:id/documents/:document_id", h.DeleteDocument)
+
+	// The declared type is ignored in favour of the detected one.
+	w := upload(r, "/tenants/1/documents", "lease.pdf", "text/html", pdfData)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upload = %d, want 201: %s", w.Code, w.Body)
+	}
+	var document model.TenantDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.ContentType != "application/pdf" || s3.contentType != "application/pdf" {
+		t.Errorf("stored content type = %q, sent to S3 as %q; want application/pdf", document.ContentType, s3.contentType)
+	}
+	put := s3.take()
+	if len(put) != 1 || !strings.HasPrefix(put[0], "PUT /documents/") || !strings.HasSuffix(put[0], "-tenant-1-lease.pdf") {
+		t.Fatalf("S3 requests = %v, want one PUT of the document", put)
+	}
+	key := strings.TrimPrefix(put[0], "PUT /documents/")
+
+	for _, tt := range []struct {
+		name   string
+		target string
+		data   []byte
+		want   int
+	}{
+		{"HTML disguised as a PDF", "/tenants/1/documents", htmlData, http.StatusUnsupportedMediaType},
+		{"missing tenant", "/tenants/9/documents", pdfData, http.StatusNotFound},
+	} {
+		if w := upload(r, tt.target, "lease.pdf", "application/pdf", tt.data); w.Code != tt.want {
+			t.Errorf("upload of %s = %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if requests := s3.take(); len(requests) != 0 {
+			t.Errorf("upload of %s reached S3: %v", tt.name, requests)
+		}
+	}
+
+	w = serve(r, http.MethodGet, "/tenants/1/documents/1", "")
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusTemporaryRedirect || !strings.Contains(location, "/documents/"+key) || !strings.Contains(location, "X-Amz-Signature=") {
+		t.Errorf("download = %d to %q, want a redirect to a presigned link", w.Code, location)
+	}
+	if w := serve(r, http.MethodGet, "/tenants/2/documents/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("download through another tenant = %d, want 404", w.Code)
+	}
+
+	s3.denyDeletes = true
+	if w := serve(r, http.MethodDelete, "/tenants/1/documents/1", ""); w.Code < http.StatusInternalServerError {
+		t.Errorf("delete while S3 refuses = %d, want it to fail", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/tenants/1/documents/1", ""); w.Code != http.StatusTemporaryRedirect {
+		t.Errorf("document after a failed delete = %d, want it kept for a retry", w.Code)
+	}
+
+	s3.denyDeletes = false
+	s3.take()
+	if w := serve(r, http.MethodDelete, "/tenants/1/documents/1", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete = %d, want 204: %s", w.Code, w.Body)
+	}
+	if requests := s3.take(); len(requests) != 1 || requests[0] != "DELETE /documents/"+key {
+		t.Errorf("S3 requests = %v, want the stored file deleted", requests)
+	}
+	if w := serve(r, http.MethodGet, "/tenants/1/documents/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("download after delete = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/service"
+)
+
+// UnitHandler represents the handler for the units of a property.
+type UnitHandler struct {
+	unitService *service.UnitService
+	s3Service   *service.S3Service
+}
+
+// NewUnitHandler returns a new unit handler.
+func NewUnitHandler(unitService *service.UnitService, s3Service *service.S3Service) *UnitHandler {
+	return &UnitHandler{unitService: unitService, s3Service: s3Service}
+}
+
+// GetUnits godoc
+// @Summary List units
+// @Description Get the units of a property ordered by number
+// @Tags Units
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param status query string false "Only units with this status" Enums(vacant, occupied, reserved, unavailable)
+// @Success 200 {array} model.Unit
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /properties/{id}/units [get]
+func (h *UnitHandler) GetUnits(c *gin.Context) {
+	propertyID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", model.UnitStatusVacant, model.UnitStatusOccupied, model.UnitStatusReserved, model.UnitStatusUnavailable:
+	default:
+		c.Error(apperror.Validation([]apperror.FieldError{{Field: "status", Reason: "must be one of: vacant, occupied, reserved, unavailable"}}))
+		return
+	}
+
+	units, err := h.unitService.GetUnits(c.Request.Context(), propertyID, status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, units)
+}
+
+// GetUnit godoc
+// @Summary Get a unit
+// @Description Get a unit of a property by ID
+// @Tags Units
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id path int true "Unit ID"
+// @Success 200 {object} model.Unit
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Router /properties/{id}/units/{unit_id} [get]
+func (h *UnitHandler) GetUnit(c *gin.Context) {
+	propertyID, unitID, ok := parseUnitPath(c)
+	if !ok {
+		return
+	}
+
+	unit, err := h.unitService.GetUnit(c.Request.Context(), propertyID, unitID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, unit)
+}
+
+// CreateUnit godoc
+// @Summary Create a unit
+// @Description Add a unit to a property
+// @Tags Units
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit body dto.CreateUnitRequest true "Unit"
+// @Success 201 {object} model.Unit
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/units [post]
+func (h *UnitHandler) CreateUnit(c *gin.Context) {
+	propertyID, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.CreateUnitRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	unit := request.ToModel(propertyID)
+	if err := h.unitService.CreateUnit(c.Request.Context(), &unit); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, unit)
+}
+
+// UpdateUnit godoc
+// @Summary Update a unit
+// @Description Replace the editable fields of a unit
+// @Tags Units
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id path int true "Unit ID"
+// @Param unit body dto.UpdateUnitRequest true "Unit"
+// @Success 200 {object} model.Unit
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/units/{unit_id} [put]
+func (h *UnitHandler) UpdateUnit(c *gin.Context) {
+	propertyID, unitID, ok := parseUnitPath(c)
+	if !ok {
+		return
+	}
+
+	var request dto.UpdateUnitRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	unit := request.ToModel(propertyID, unitID)
+	if err := h.unitService.UpdateUnit(c.Request.Context(), &unit); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, unit)
+}
+
+// DeleteUnit godoc
+// @Summary Delete a unit
+// @Description Delete a unit of a property and its stored images. Units with a lease in force cannot be deleted.
+// @Tags Units
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id path int true "Unit ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/units/{unit_id} [delete]
+func (h *UnitHandler) DeleteUnit(c *gin.Context) {
+	propertyID, unitID, ok := parseUnitPath(c)
+	if !ok {
+		return
+	}
+
+	unit, err := h.unitService.CheckDeleteUnit(c.Request.Context(), propertyID, unitID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	for _, image := range unit.Images {
+		if err := h.s3Service.DeleteImage(c.Request.Context(), image.URL); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := h.unitService.DeleteUnit(c.Request.Context(), propertyID, unitID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// UploadUnitImage godoc
+// @Summary Upload a unit image
+// @Description Upload an image for a unit
+// @Tags Units
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id path int true "Unit ID"
+// @Param file formData file true "Image file"
+// @Success 201 {object} model.UnitImage
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/units/{unit_id}/images [post]
+func (h *UnitHandler) UploadUnitImage(c *gin.Context) {
+	propertyID, unitID, ok := parseUnitPath(c)
+	if !ok {
+		return
+	}
+
+	fileBytes, file, ok := readFormFile(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.unitService.GetUnit(c.Request.Context(), propertyID, unitID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	fileName := fmt.Sprintf("%d-%d-%s", propertyID, unitID, file.Filename)
+	url, err := h.s3Service.UploadImage(c.Request.Context(), fileBytes, fileName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	image, err := h.unitService.AddImage(c.Request.Context(), propertyID, unitID, url, file.Size)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// DeleteUnitImage godoc
+// @Summary Delete a unit image
+// @Description Delete an image associated with a unit
+// @Tags Units
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param unit_id path int true "Unit ID"
+// @Param image_id path int true "Image ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/units/{unit_id}/images/{image_id} [delete]
+func (h *UnitHandler) DeleteUnitImage(c *gin.Context) {
+	propertyID, unitID, ok := parseUnitPath(c)
+	if !ok {
+		return
+	}
+
+	imageID, ok := parseID(c, "image_id")
+	if !ok {
+		return
+	}
+
+	image, err := h.unitService.GetImage(c.Request.Context(), propertyID, unitID, imageID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Delete the stored file first, so that a failure leaves the image
+	// listed and the deletion can be retried.
+	if err := h.s3Service.DeleteImage(c.Request.Context(), image.URL); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.unitService.DeleteImage(c.Request.Context(), propertyID, unitID, imageID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
+
+// parseUnitPath parses the property and unit IDs of a unit route.
+func parseUnitPath(c *gin.Context) (uint, uint, bool) {
+	propertyID, ok := parseID(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	unitID, ok := parseID(c, "unit_id")
+	return propertyID, unitID, ok
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// VendorHandler represents the vendor handler.
+type VendorHandler struct {
+	vendorService *service.VendorService
+}
+
+// NewVendorHandler returns a new vendor handler.
+func NewVendorHandler(vendorService *service.VendorService) *VendorHandler {
+	return &VendorHandler{vendorService: vendorService}
+}
+
+// SearchVendors godoc
+// @Summary Search vendors
+// @Description List vendors ordered by name, optionally matching part of their name or contact name, by trade or by service area
+// @Tags Vendors
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Part of a name or contact name"
+// @Param trade query string false "Only vendors of this trade" Enums(plumbing, electrical, hvac, appliance, structural, pest, locks, grounds, general)
+// @Param service_area query string false "Only vendors serving this area"
+// @Success 200 {array} model.Vendor
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /vendors [get]
+func (h *VendorHandler) SearchVendors(c *gin.Context) {
+	filter := repository.VendorFilter{
+		Query:       c.Query("q"),
+		Trade:       c.Query("trade"),
+		ServiceArea: c.Query("service_area"),
+	}
+	if fields := checkQueryOneOf(nil, "trade", filter.Trade, "plumbing", "electrical", "hvac", "appliance", "structural", "pest", "locks", "grounds", "general"); len(fields) > 0 {
+		c.Error(apperror.Validation(fields))
+		return
+	}
+
+	vendors, err := h.vendorService.SearchVendors(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vendors)
+}
+
+// GetVendor godoc
+// @Summary Get a vendor
+// @Description Get a vendor by ID
+// @Tags Vendors
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Vendor ID"
+// @Success 200 {object} model.Vendor
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /vendors/{id} [get]
+func (h *VendorHandler) GetVendor(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	vendor, err := h.vendorService.GetVendor(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vendor)
+}
+
+// CreateVendor godoc
+// @Summary Create a vendor
+// @Description Create a vendor that work orders can be issued to
+// @Tags Vendors
+// @Accept  json
+// @Produce  json
+// @Param vendor body dto.VendorRequest true "Vendor"
+// @Success 201 {object} model.Vendor
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /vendors [post]
+func (h *VendorHandler) CreateVendor(c *gin.Context) {
+	var request dto.VendorRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	vendor := request.ToModel(0)
+	if err := h.vendorService.CreateVendor(c.Request.Context(), &vendor); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, vendor)
+}
+
+// UpdateVendor godoc
+// @Summary Update a vendor
+// @Description Replace a vendor's details
+// @Tags Vendors
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Vendor ID"
+// @Param vendor body dto.VendorRequest true "Vendor"
+// @Success 200 {object} model.Vendor
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /vendors/{id} [put]
+func (h *VendorHandler) UpdateVendor(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.VendorRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	vendor := request.ToModel(id)
+	if err := h.vendorService.UpdateVendor(c.Request.Context(), &vendor); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vendor)
+}
+
+// DeleteVendor godoc
+// @Summary Delete a vendor
+// @Description Delete a vendor. Its work orders keep referring to it.
+// @Tags Vendors
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Vendor ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /vendors/{id} [delete]
+func (h *VendorHandler) DeleteVendor(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.vendorService.DeleteVendor(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{})
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+	"propmanager/internal/app/middleware"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/app/service"
+)
+
+// WorkOrderHandler represents the handler for work orders and the property
+// expenses they incur.
+type WorkOrderHandler struct {
+	workOrderService *service.WorkOrderService
+}
+
+// NewWorkOrderHandler returns a new work order handler.
+func NewWorkOrderHandler(workOrderService *service.WorkOrderService) *WorkOrderHandler {
+	return &WorkOrderHandler{workOrderService: workOrderService}
+}
+
+// SearchWorkOrders godoc
+// @Summary List work orders
+// @Description List work orders, newest first, optionally of a property or vendor or with a status
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param property_id query int false "Only work orders at this property"
+// @Param vendor_id query int false "Only work orders issued to this vendor"
+// @Param status query string false "Only work orders with this status" Enums(pending_approval, approved, rejected, invoiced, completed, cancelled)
+// @Success 200 {array} model.WorkOrder
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders [get]
+func (h *WorkOrderHandler) SearchWorkOrders(c *gin.Context) {
+	propertyID, ok := parseIDQuery(c, "property_id")
+	if !ok {
+		return
+	}
+	vendorID, ok := parseIDQuery(c, "vendor_id")
+	if !ok {
+		return
+	}
+	status := c.Query("status")
+	if fields := checkQueryOneOf(nil, "status", status, "pending_approval", "approved", "rejected", "invoiced", "completed", "cancelled"); len(fields) > 0 {
+		c.Error(apperror.Validation(fields))
+		return
+	}
+
+	orders, err := h.workOrderService.SearchWorkOrders(c.Request.Context(), repository.WorkOrderFilter{
+		PropertyID: propertyID,
+		VendorID:   vendorID,
+		Status:     status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// GetRequestWorkOrders godoc
+// @Summary List a maintenance request's work orders
+// @Description List the work orders issued for a maintenance request, newest first
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Success 200 {array} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/work-orders [get]
+func (h *WorkOrderHandler) GetRequestWorkOrders(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	orders, err := h.workOrderService.GetRequestWorkOrders(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// IssueWorkOrder godoc
+// @Summary Issue a work order
+// @Description Issue a work order for a maintenance request that is not closed to a vendor whose insurance has not lapsed. Orders estimated above the approval threshold wait for approval; others are approved straight away. An unassigned maintenance request is assigned to the vendor.
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Maintenance request ID"
+// @Param order body dto.WorkOrderRequest true "Work order"
+// @Success 201 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /maintenance-requests/{id}/work-orders [post]
+func (h *WorkOrderHandler) IssueWorkOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.WorkOrderRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	order := request.ToModel(id)
+	if err := h.workOrderService.IssueWorkOrder(c.Request.Context(), &order, c.GetString(middleware.UsernameKey)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// GetWorkOrder godoc
+// @Summary Get a work order
+// @Description Get a work order by ID with its vendor
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Work order ID"
+// @Success 200 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders/{id} [get]
+func (h *WorkOrderHandler) GetWorkOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.workOrderService.GetWorkOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ApproveWorkOrder godoc
+// @Summary Approve a work order
+// @Description Approve a work order pending approval so that the vendor can start work. An order sent back for approval by an invoice above its estimate becomes invoiced. Orders cannot be approved by the user who issued them.
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Work order ID"
+// @Success 200 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders/{id}/approve [post]
+func (h *WorkOrderHandler) ApproveWorkOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.workOrderService.ApproveWorkOrder(c.Request.Context(), id, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// RejectWorkOrder godoc
+// @Summary Reject a work order
+// @Description Reject a work order pending approval
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Work order ID"
+// @Param rejection body dto.WorkOrderReasonRequest true "Rejection"
+// @Success 200 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders/{id}/reject [post]
+func (h *WorkOrderHandler) RejectWorkOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.WorkOrderReasonRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	order, err := h.workOrderService.RejectWorkOrder(c.Request.Context(), id, request.Reason, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelWorkOrder godoc
+// @Summary Cancel a work order
+// @Description Cancel a work order that has not been invoiced. An order whose invoice is waiting for approval can only be rejected.
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Work order ID"
+// @Param cancellation body dto.WorkOrderReasonRequest true "Cancellation"
+// @Success 200 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders/{id}/cancel [post]
+func (h *WorkOrderHandler) CancelWorkOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.WorkOrderReasonRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	order, err := h.workOrderService.CancelWorkOrder(c.Request.Context(), id, request.Reason, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// RecordInvoice godoc
+// @Summary Record a work order invoice
+// @Description Record the vendor's invoice for an approved work order. An invoice above both the estimate and the approval threshold sends the order back for approval.
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Work order ID"
+// @Param invoice body dto.InvoiceRequest true "Invoice"
+// @Success 200 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders/{id}/invoice [post]
+func (h *WorkOrderHandler) RecordInvoice(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.InvoiceRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	order, err := h.workOrderService.RecordInvoice(c.Request.Context(), id, request.Number, request.InvoiceDate(), request.Amount, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CompleteWorkOrder godoc
+// @Summary Sign off a work order
+// @Description Sign off the work of an invoiced work order as complete. The invoice is recorded as an expense of the property.
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Work order ID"
+// @Param signoff body dto.SignOffRequest true "Sign-off"
+// @Success 200 {object} model.WorkOrder
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /work-orders/{id}/complete [post]
+func (h *WorkOrderHandler) CompleteWorkOrder(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request dto.SignOffRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	order, err := h.workOrderService.CompleteWorkOrder(c.Request.Context(), id, request.Notes, c.GetString(middleware.UsernameKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// GetExpenseHistory godoc
+// @Summary Get a property's expense history
+// @Description List the expenses of a property over a period, such as the invoices of completed work orders, with totals by category
+// @Tags Work Orders
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Property ID"
+// @Param from query string false "First day of the period (YYYY-MM-DD); defaults to the first day of the year of to"
+// @Param to query string false "Last day of the period (YYYY-MM-DD); defaults to today"
+// @Success 200 {object} model.ExpenseHistory
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security ApiKeyAuth
+// @Router /properties/{id}/expenses [get]
+func (h *WorkOrderHandler) GetExpenseHistory(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	from, to, ok := parsePeriodQuery(c, func(to time.Time) time.Time {
+		return time.Date(to.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	})
+	if !ok {
+		return
+	}
+
+	history, err := h.workOrderService.GetExpenseHistory(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
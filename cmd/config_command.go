@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"propmanager/internal/config"
+)
+
+// configCommand runs "propmanager config <subcommand>" and returns the exit
+// code. "config print [flags]" writes the effective configuration, with
+// secrets masked, and lists any problems that would stop the server.
+func configCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: propmanager config print [flags]")
+		return 2
+	}
+
+	cfg, err := config.Load(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	var configErr *config.Error
+	if err != nil && !errors.As(err, &configErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := config.Print(os.Stdout, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if configErr != nil {
+		fmt.Fprintln(os.Stderr, configErr)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"propmanager/internal/app/service"
+)
+
+// postLedgerCharges charges leases the rent that has fallen due, and late
+// fees on rent left unpaid, and then ends renewed leases whose term is over,
+// at startup and then every interval, until ctx is done.
+func postLedgerCharges(ctx context.Context, interval time.Duration, ledgerService *service.LedgerService, lateFeeService *service.LateFeeService, leaseService *service.LeaseService) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if posted, err := ledgerService.PostDueRent(ctx, today); err != nil {
+			slog.ErrorContext(ctx, "Failed to post rent", "error", err, "posted", posted)
+		} else if posted > 0 {
+			slog.InfoContext(ctx, "Posted rent", "charges", posted)
+		}
+		if posted, err := lateFeeService.AssessLateFees(ctx, today); err != nil {
+			slog.ErrorContext(ctx, "Failed to assess late fees", "error", err, "posted", posted)
+		} else if posted > 0 {
+			slog.InfoContext(ctx, "Posted late fees", "fees", posted)
+		}
+		// Rent is posted first, so that a renewed lease is billed up to its
+		// end date before it is ended.
+		if ended, err := leaseService.EndRenewedLeases(ctx, today); err != nil {
+			slog.ErrorContext(ctx, "Failed to end renewed leases", "error", err)
+		} else if ended > 0 {
+			slog.InfoContext(ctx, "Ended renewed leases", "leases", ended)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,34 +1,80 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"propmanager/api"
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/metrics"
 	"propmanager/internal/app/middleware"
 	"propmanager/internal/app/model"
 	"propmanager/internal/app/repository"
 	"propmanager/internal/app/service"
 	"propmanager/internal/config"
 	"propmanager/internal/db"
+	"propmanager/internal/logging"
+	"propmanager/internal/tlsconfig"
+	"propmanager/internal/tracing"
 )
 
 //go:embed docs/swagger.json
 var swaggerJson string
 
 func main() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(configCommand(args[1:]))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := loadConfig(ctx, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 
-	cfg := config.LoadConfig()
+	logging.RegisterSecrets(config.SecretValues(cfg)...)
+	slog.SetDefault(logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat))
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter)
+	if err != nil {
+		log.Fatal("Failed to set up tracing:", err)
+	}
 
 	db := db.ConnectDB()
 
-	err := db.AutoMigrate(&model.Property{}, &model.Image{})
+	err = db.AutoMigrate(&model.Property{}, &model.Image{}, &model.PropertyVersion{}, &model.Unit{}, &model.UnitImage{},
+		&model.Tenant{}, &model.EmergencyContact{}, &model.TenantDocument{}, &model.Lease{},
+		&model.LedgerEntry{}, &model.PaymentIntent{}, &model.Payout{}, &model.WebhookEvent{},
+		&model.MaintenanceRequest{}, &model.MaintenancePhoto{}, &model.MaintenanceComment{},
+		&model.Vendor{}, &model.WorkOrder{}, &model.Expense{},
+		&model.Owner{}, &model.Ownership{}, &model.ManagementAgreement{},
+		&model.InspectionTemplate{}, &model.Inspection{}, &model.InspectionResult{}, &model.InspectionPhoto{})
 	if err != nil {
 		log.Fatal("Failed to auto-migrate database:", err)
 	}
@@ -39,36 +85,199 @@ func main() {
 
 	propertyHandler := api.NewPropertyHandler(propertyService, s3Service)
 
-	statsService := service.NewStatsService()
+	unitRepository := repository.NewUnitRepository(db)
+	unitService := service.NewUnitService(unitRepository)
+	unitHandler := api.NewUnitHandler(unitService, s3Service)
+
+	tenantRepository := repository.NewTenantRepository(db)
+	tenantService := service.NewTenantService(tenantRepository)
+	tenantHandler := api.NewTenantHandler(tenantService, s3Service)
+
+	leaseRepository := repository.NewLeaseRepository(db)
+	leaseService := service.NewLeaseService(leaseRepository)
+	leaseHandler := api.NewLeaseHandler(leaseService)
+
+	ledgerRepository := repository.NewLedgerRepository(db)
+	ledgerService := service.NewLedgerService(ledgerRepository)
+	ledgerHandler := api.NewLedgerHandler(ledgerService)
+	lateFeeService := service.NewLateFeeService(ledgerRepository, &cfg.LateFees)
+	delinquencyHandler := api.NewDelinquencyHandler(lateFeeService)
+
+	paymentProvider := cfg.Payments.NewProvider()
+	paymentRepository := repository.NewPaymentRepository(db)
+	paymentService := service.NewPaymentService(paymentRepository, paymentProvider, cfg.Payments.Currency)
+	paymentHandler := api.NewPaymentHandler(paymentService, paymentProvider)
+
+	maintenanceRepository := repository.NewMaintenanceRepository(db)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepository, &cfg.Maintenance)
+	maintenanceHandler := api.NewMaintenanceHandler(maintenanceService, s3Service)
+
+	vendorRepository := repository.NewVendorRepository(db)
+	vendorService := service.NewVendorService(vendorRepository)
+	vendorHandler := api.NewVendorHandler(vendorService)
+
+	workOrderRepository := repository.NewWorkOrderRepository(db)
+	workOrderService := service.NewWorkOrderService(workOrderRepository, &cfg.WorkOrders)
+	workOrderHandler := api.NewWorkOrderHandler(workOrderService)
+
+	ownerRepository := repository.NewOwnerRepository(db)
+	ownerService := service.NewOwnerService(ownerRepository)
+	ownerHandler := api.NewOwnerHandler(ownerService)
+
+	inspectionRepository := repository.NewInspectionRepository(db)
+	inspectionService := service.NewInspectionService(inspectionRepository)
+	inspectionHandler := api.NewInspectionHandler(inspectionService, s3Service)
+
+	statsRepository := repository.NewStatsRepository(db)
+	statsService := service.NewStatsService(statsRepository, cfg.StatsCacheTTL)
 	statsHandler := api.NewStatsHandler(statsService)
 
-	authConfig := config.LoadAuthConfig()
-	authService := service.NewAuthService(&authConfig)
+	healthService := service.NewHealthService(map[string]service.HealthCheck{
+		"database": service.DatabaseCheck(db),
+		"s3":       s3Service.CheckBucket,
+	}, 2*time.Second)
+	healthHandler := api.NewHealthHandler(healthService)
+
+	authService := service.NewAuthService(&cfg.Auth)
 	authHandler := api.NewAuthHandler(authService)
 
 	r := gin.New()
 	r.Use(gin.Recovery())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.HSTS(cfg.HSTSMaxAge))
 	r.Use(middleware.StripTrailingSlash())
+	r.Use(middleware.Metrics())
 	r.Use(middleware.Logger())
 	r.Use(middleware.CORS())
 	r.Use(middleware.ErrorHandler())
 
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+
 	r.GET("/properties", propertyHandler.GetAllProperties)
 	r.GET("/properties/:id", propertyHandler.GetProperty)
+	r.GET("/properties/:id/units", unitHandler.GetUnits)
+	r.GET("/properties/:id/units/:unit_id", unitHandler.GetUnit)
+
+	// The payment provider authenticates webhooks by their signature.
+	r.POST("/webhooks/payments", paymentHandler.HandleWebhook)
 
 	authGroup := r.Group("/")
-	authGroup.Use(authConfig.AuthMiddleware())
+	authGroup.Use(cfg.Auth.AuthMiddleware())
 	{
 		authGroup.POST("/properties", propertyHandler.CreateProperty)
 		authGroup.PUT("/properties/:id", propertyHandler.UpdateProperty)
+		authGroup.PATCH("/properties/:id", propertyHandler.PatchProperty)
 		authGroup.DELETE("/properties/:id", propertyHandler.DeleteProperty)
 		authGroup.POST("/properties/:id/images", propertyHandler.UploadImage)
 		authGroup.DELETE("/properties/:id/images/:image_id", propertyHandler.DeleteImage)
+		authGroup.GET("/properties/:id/history", propertyHandler.GetPropertyHistory)
+		authGroup.GET("/properties/:id/history/:version", propertyHandler.GetPropertyVersion)
+		authGroup.POST("/properties/:id/history/:version/restore", propertyHandler.RestorePropertyVersion)
+		authGroup.POST("/properties/:id/units", unitHandler.CreateUnit)
+		authGroup.PUT("/properties/:id/units/:unit_id", unitHandler.UpdateUnit)
+		authGroup.DELETE("/properties/:id/units/:unit_id", unitHandler.DeleteUnit)
+		authGroup.POST("/properties/:id/units/:unit_id/images", unitHandler.UploadUnitImage)
+		authGroup.DELETE("/properties/:id/units/:unit_id/images/:image_id", unitHandler.DeleteUnitImage)
+		authGroup.GET("/tenants", tenantHandler.SearchTenants)
+		authGroup.POST("/tenants", tenantHandler.CreateTenant)
+		authGroup.GET("/tenants/:id", tenantHandler.GetTenant)
+		authGroup.PUT("/tenants/:id", tenantHandler.UpdateTenant)
+		authGroup.DELETE("/tenants/:id", tenantHandler.DeleteTenant)
+		authGroup.POST("/tenants/:id/documents", tenantHandler.UploadDocument)
+		authGroup.GET("/tenants/:id/documents/:document_id", tenantHandler.DownloadDocument)
+		authGroup.DELETE("/tenants/:id/documents/:document_id", tenantHandler.DeleteDocument)
+		authGroup.GET("/leases", leaseHandler.SearchLeases)
+		authGroup.POST("/leases", leaseHandler.CreateLease)
+		authGroup.GET("/leases/:id", leaseHandler.GetLease)
+		authGroup.PUT("/leases/:id", leaseHandler.UpdateLease)
+		authGroup.DELETE("/leases/:id", leaseHandler.DeleteLease)
+		authGroup.POST("/leases/:id/activate", leaseHandler.ActivateLease)
+		authGroup.POST("/leases/:id/renew", leaseHandler.RenewLease)
+		authGroup.POST("/leases/:id/end", leaseHandler.EndLease)
+		authGroup.POST("/leases/:id/terminate", leaseHandler.TerminateLease)
+		authGroup.GET("/leases/:id/ledger", ledgerHandler.GetLedger)
+		authGroup.POST("/leases/:id/charges", ledgerHandler.PostCharge)
+		authGroup.POST("/leases/:id/payments", ledgerHandler.PostPayment)
+		authGroup.POST("/leases/:id/credits", ledgerHandler.PostCredit)
+		authGroup.POST("/leases/:id/payment-intents", paymentHandler.CreatePaymentIntent)
+		authGroup.GET("/leases/:id/payment-intents", paymentHandler.GetPaymentIntents)
+		authGroup.GET("/payouts", paymentHandler.GetPayouts)
+		authGroup.GET("/payouts/:id", paymentHandler.GetPayout)
+		authGroup.GET("/tenants/:id/statement", ledgerHandler.GetStatement)
+		authGroup.GET("/properties/:id/maintenance-requests", maintenanceHandler.GetPropertyRequests)
+		authGroup.POST("/maintenance-requests", maintenanceHandler.CreateRequest)
+		authGroup.GET("/maintenance-requests/:id", maintenanceHandler.GetRequest)
+		authGroup.PUT("/maintenance-requests/:id", maintenanceHandler.UpdateRequest)
+		authGroup.DELETE("/maintenance-requests/:id", maintenanceHandler.DeleteRequest)
+		authGroup.POST("/maintenance-requests/:id/assign", maintenanceHandler.AssignRequest)
+		authGroup.POST("/maintenance-requests/:id/status", maintenanceHandler.ChangeStatus)
+		authGroup.POST("/maintenance-requests/:id/comments", maintenanceHandler.AddComment)
+		authGroup.POST("/maintenance-requests/:id/photos", maintenanceHandler.UploadPhoto)
+		authGroup.GET("/maintenance-requests/:id/photos/:photo_id", maintenanceHandler.DownloadPhoto)
+		authGroup.DELETE("/maintenance-requests/:id/photos/:photo_id", maintenanceHandler.DeletePhoto)
+		authGroup.GET("/maintenance-requests/:id/work-orders", workOrderHandler.GetRequestWorkOrders)
+		authGroup.POST("/maintenance-requests/:id/work-orders", workOrderHandler.IssueWorkOrder)
+		authGroup.GET("/vendors", vendorHandler.SearchVendors)
+		authGroup.POST("/vendors", vendorHandler.CreateVendor)
+		authGroup.GET("/vendors/:id", vendorHandler.GetVendor)
+		authGroup.PUT("/vendors/:id", vendorHandler.UpdateVendor)
+		authGroup.DELETE("/vendors/:id", vendorHandler.DeleteVendor)
+		authGroup.GET("/work-orders", workOrderHandler.SearchWorkOrders)
+		authGroup.GET("/work-orders/:id", workOrderHandler.GetWorkOrder)
+		authGroup.POST("/work-orders/:id/approve", workOrderHandler.ApproveWorkOrder)
+		authGroup.POST("/work-orders/:id/reject", workOrderHandler.RejectWorkOrder)
+		authGroup.POST("/work-orders/:id/cancel", workOrderHandler.CancelWorkOrder)
+		authGroup.POST("/work-orders/:id/invoice", workOrderHandler.RecordInvoice)
+		authGroup.POST("/work-orders/:id/complete", workOrderHandler.CompleteWorkOrder)
+		authGroup.GET("/properties/:id/expenses", workOrderHandler.GetExpenseHistory)
+		authGroup.GET("/owners", ownerHandler.SearchOwners)
+		authGroup.POST("/owners", ownerHandler.CreateOwner)
+		authGroup.GET("/owners/:id", ownerHandler.GetOwner)
+		authGroup.PUT("/owners/:id", ownerHandler.UpdateOwner)
+		authGroup.DELETE("/owners/:id", ownerHandler.DeleteOwner)
+		authGroup.GET("/owners/:id/statements/:month", ownerHandler.GetOwnerStatement)
+		authGroup.GET("/properties/:id/owners", ownerHandler.GetPropertyOwners)
+		authGroup.PUT("/properties/:id/owners", ownerHandler.SetPropertyOwners)
+		authGroup.GET("/properties/:id/management-agreements", ownerHandler.GetAgreements)
+		authGroup.POST("/properties/:id/management-agreements", ownerHandler.CreateAgreement)
+		authGroup.PUT("/properties/:id/management-agreements/:agreement_id", ownerHandler.UpdateAgreement)
+		authGroup.DELETE("/properties/:id/management-agreements/:agreement_id", ownerHandler.DeleteAgreement)
+		authGroup.GET("/inspection-templates", inspectionHandler.GetTemplates)
+		authGroup.POST("/inspection-templates", inspectionHandler.CreateTemplate)
+		authGroup.GET("/inspection-templates/:id", inspectionHandler.GetTemplate)
+		authGroup.PUT("/inspection-templates/:id", inspectionHandler.UpdateTemplate)
+		authGroup.DELETE("/inspection-templates/:id", inspectionHandler.DeleteTemplate)
+		authGroup.GET("/properties/:id/inspections", inspectionHandler.GetPropertyInspections)
+		authGroup.POST("/inspections", inspectionHandler.ScheduleInspection)
+		authGroup.GET("/inspections/:id", inspectionHandler.GetInspection)
+		authGroup.PUT("/inspections/:id", inspectionHandler.RescheduleInspection)
+		authGroup.POST("/inspections/:id/cancel", inspectionHandler.CancelInspection)
+		authGroup.POST("/inspections/:id/submit", inspectionHandler.SubmitInspection)
+		authGroup.POST("/inspections/:id/photos", inspectionHandler.UploadPhoto)
+		authGroup.GET("/inspections/:id/photos/:photo_id", inspectionHandler.DownloadPhoto)
+		authGroup.DELETE("/inspections/:id/photos/:photo_id", inspectionHandler.DeletePhoto)
+		authGroup.GET("/inspections/:id/comparison", inspectionHandler.GetComparison)
+		authGroup.GET("/inspections/:id/report", inspectionHandler.GetReport)
+		authGroup.GET("/reports/delinquency", delinquencyHandler.GetDelinquencyReport)
 		authGroup.GET("/stats", statsHandler.GetStats)
 	}
 
 	r.POST("/login", authHandler.Login)
 
+	// Metrics are served on the internal listener when one is configured,
+	// and otherwise only to authenticated callers.
+	metrics.Registry.MustRegister(metrics.NewBusinessCollector(db))
+	metricsHandler := promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})
+	if cfg.MetricsAddr == "" {
+		authGroup.GET("/metrics", gin.WrapH(metricsHandler))
+	}
+
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperror.NotFound("route_not_found", "No route matches "+c.Request.Method+" "+c.Request.URL.Path+"."))
+	})
+
 	// Serve Swagger UI with custom swagger.json endpoint
 	url := ginSwagger.URL("/swagger.json") // The url pointing to API definition
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
@@ -76,5 +285,129 @@ func main() {
 		c.Data(http.StatusOK, "application/json", []byte(swaggerJson))
 	})
 
-	log.Fatal(r.Run(":" + cfg.Port))
+	// Background workers run until workerCtx is cancelled, once the server
+	// has finished draining.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	if cfg.SecretsRefreshInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			refreshSecrets(workerCtx, args, cfg.SecretsRefreshInterval, s3Service)
+		}()
+	}
+
+	if cfg.LedgerPostingInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			postLedgerCharges(workerCtx, cfg.LedgerPostingInterval, ledgerService, lateFeeService, leaseService)
+		}()
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLSEnabled() {
+		certReloader, err := tlsconfig.NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			log.Fatal("Failed to load TLS certificate:", err)
+		}
+		tlsConfig, err = tlsconfig.New(certReloader, cfg.TLSClientAuth, cfg.TLSClientCAFile)
+		if err != nil {
+			log.Fatal("Failed to configure TLS:", err)
+		}
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			certReloader.Watch(workerCtx, cfg.TLSReloadInterval)
+		}()
+	}
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           r,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		TLSConfig:         tlsConfig,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	serverErr := make(chan error, 3)
+	go func() {
+		slog.Info("Server listening", "addr", srv.Addr, "tls", cfg.TLSEnabled())
+		if cfg.TLSEnabled() {
+			// The certificate comes from TLSConfig.GetCertificate.
+			serverErr <- srv.ListenAndServeTLS("", "")
+		} else {
+			serverErr <- srv.ListenAndServe()
+		}
+	}()
+
+	var redirectSrv *http.Server
+	if cfg.TLSRedirectPort != 0 {
+		redirectSrv = &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.TLSRedirectPort),
+			Handler:           middleware.RedirectToHTTPS(cfg.Port),
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		}
+		go func() {
+			slog.Info("Redirecting HTTP to HTTPS", "addr", redirectSrv.Addr)
+			serverErr <- redirectSrv.ListenAndServe()
+		}()
+	}
+
+	var metricsSrv *http.Server
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsHandler)
+		metricsSrv = &http.Server{
+			Addr:              cfg.MetricsAddr,
+			Handler:           mux,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		}
+		go func() {
+			slog.Info("Serving metrics", "addr", metricsSrv.Addr)
+			serverErr <- metricsSrv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		log.Fatal("Server failed:", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	slog.Info("Shutting down", "drain_delay", cfg.ShutdownDrainDelay.String(), "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+cfg.ShutdownTimeout)
+	defer cancel()
+	servers := []*http.Server{srv}
+	if redirectSrv != nil {
+		servers = append([]*http.Server{redirectSrv}, servers...)
+	}
+	if metricsSrv != nil {
+		servers = append(servers, metricsSrv)
+	}
+	drain(shutdownCtx, cfg.ShutdownDrainDelay, healthService, servers...)
+
+	stopWorkers()
+	workers.Wait()
+
+	// Flush the spans still queued in the trace exporter's background
+	// worker before the database pool goes away.
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close database", "error", err)
+		}
+	}
+	slog.Info("Server stopped")
 }
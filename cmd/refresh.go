@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"propmanager/internal/app/service"
+	"propmanager/internal/config"
+	"propmanager/internal/logging"
+)
+
+// refreshSecrets reloads the configuration from the same sources as at
+// startup every interval, re-reading _FILE secrets and secret stores, and
+// hands rotated S3 credentials to s3Service. It returns when ctx is done.
+func refreshSecrets(ctx context.Context, args []string, interval time.Duration, s3Service *service.S3Service) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cfg, err := loadConfig(ctx, args)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to refresh secrets", "error", err)
+			continue
+		}
+		logging.RegisterSecrets(config.SecretValues(cfg)...)
+		if s3Service.SetCredentials(cfg.S3AccessKey, cfg.S3SecretKey) {
+			slog.InfoContext(ctx, "Rotated S3 credentials")
+		}
+	}
+}
+
+// loadConfig loads the configuration from args and resolves its references
+// to secret stores.
+func loadConfig(ctx context.Context, args []string) (config.Config, error) {
+	cfg, err := config.Load(args)
+	if err != nil {
+		return cfg, err
+	}
+	err = config.ResolveSecrets(ctx, &cfg, config.NewSecretResolver(cfg))
+	return cfg, err
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"propmanager/internal/app/service"
+)
+
+// drain shuts the servers down gracefully. It reports not-ready first, for
+// drainDelay, so that load balancers stop routing new requests here, then
+// stops the servers accepting connections and lets in-flight requests
+// finish until ctx is done, when it closes whatever is still open.
+func drain(ctx context.Context, drainDelay time.Duration, healthService *service.HealthService, servers ...*http.Server) {
+	healthService.SetShuttingDown()
+	time.Sleep(drainDelay)
+
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("In-flight requests did not finish before the shutdown timeout", "addr", srv.Addr, "error", err)
+			srv.Close()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/service"
+)
+
+// startServer serves handler on a free local port until the test ends.
+func startServer(t *testing.T, handler http.Handler) (*http.Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Addr: listener.Addr().String(), Handler: handler}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+	return srv, "http://" + listener.Addr().String()
+}
+
+// TestDrainFinishesInFlightRequests shuts a server down while a request is
+// in flight, and checks that the server reports not-ready during the drain
+// delay, lets the request finish, and then refuses new connections.
+func TestDrainFinishesInFlightRequests(t *testing.T) {
+	healthService := service.NewHealthService(nil, time.Second)
+	started, release := make(chan struct{}), make(chan struct{})
+	srv, url := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "uploaded")
+	}))
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+	<-started
+
+	drained := make(chan struct{})
+	go func() {
+		drain(context.Background(), 100*time.Millisecond, healthService, srv)
+		close(drained)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if report := healthService.Readiness(context.Background()); report.Status != model.HealthStatusUnavailable || !report.ShuttingDown {
+		t.Errorf("readiness while draining = %+v, want unavailable and shutting down", report)
+	}
+	select {
+	case <-drained:
+		t.Fatal("drain returned with a request in flight")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	close(release)
+	if got := <-response; got != "uploaded" {
+		t.Errorf("in-flight request got %q, want it to finish", got)
+	}
+	<-drained
+
+	if _, err := http.Get(url); err == nil {
+		t.Error("server accepted a request after draining")
+	}
+}
+
+// TestDrainClosesRequestsAfterTimeout checks that requests still running
+// when the shutdown deadline passes are cut off rather than waited for.
+func TestDrainClosesRequestsAfterTimeout(t *testing.T) {
+	healthService := service.NewHealthService(nil, time.Second)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	srv, url := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		close(started)
+		<-release
+	}))
+
+	failed := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		failed <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	drain(ctx, 0, healthService, srv)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("drain took %s, want it to give up at the deadline", elapsed)
+	}
+
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Error("request still in flight at the deadline completed normally, want it cut off")
+		}
+	case <-time.After(time.Second):
+		t.Error("request still in flight at the deadline was not closed")
+	}
+}
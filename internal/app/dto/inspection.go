@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"time"
+
+	"propmanager/internal/app/model"
+)
+
+// DateTimeLayout is the format of points in time in request bodies.
+const DateTimeLayout = time.RFC3339
+
+// InspectionTemplateRequest is the body accepted when creating or
+// replacing an inspection template. Without ratings, items are rated
+// excellent, good, fair, poor or damaged.
+type InspectionTemplateRequest struct {
+	Name        string                  `json:"name" validate:"required,max=200"`
+	Description string                  `json:"description" validate:"max=5000"`
+	Ratings     []string                `json:"ratings" validate:"omitempty,min=2,max=10,dive,required,max=50" example:"good,fair,poor"`
+	Rooms       []InspectionRoomRequest `json:"rooms" validate:"required,min=1,max=50,dive"`
+}
+
+// InspectionRoomRequest is a room of an inspection template and the items
+// to rate in it.
+type InspectionRoomRequest struct {
+	Name  string   `json:"name" validate:"required,max=100" example:"Kitchen"`
+	Items []string `json:"items" validate:"required,min=1,max=100,dive,required,max=200" example:"Walls,Floor,Oven"`
+}
+
+// ToModel returns the inspection template described by the request.
+func (r InspectionTemplateRequest) ToModel(id uint) model.InspectionTemplate {
+	template := model.InspectionTemplate{
+		ID:          id,
+		Name:        r.Name,
+		Description: r.Description,
+		Ratings:     r.Ratings,
+		Rooms:       make([]model.InspectionRoom, 0, len(r.Rooms)),
+	}
+	for _, room := range r.Rooms {
+		template.Rooms = append(template.Rooms, model.InspectionRoom{Name: room.Name, Items: room.Items})
+	}
+	return template
+}
+
+// InspectionRequest is the body accepted when scheduling an inspection. It
+// needs a property, a unit or a lease; a unit implies its property and a
+// lease its unit.
+type InspectionRequest struct {
+	TemplateID   uint   `json:"template_id" validate:"required,gt=0"`
+	PropertyID   *uint  `json:"property_id" validate:"omitempty,gt=0"`
+	UnitID       *uint  `json:"unit_id" validate:"omitempty,gt=0"`
+	LeaseID      *uint  `json:"lease_id" validate:"omitempty,gt=0"`
+	Type         string `json:"type" validate:"required,oneof=move_in move_out routine"`
+	ScheduledFor string `json:"scheduled_for" validate:"required,datetime=2006-01-02T15:04:05Z07:00" example:"2025-03-01T10:00:00Z"`
+	Inspector    string `json:"inspector" validate:"max=100"`
+}
+
+// ToModel returns the inspection described by the request.
+func (r InspectionRequest) ToModel() model.Inspection {
+	inspection := model.Inspection{
+		TemplateID:   r.TemplateID,
+		UnitID:       r.UnitID,
+		LeaseID:      r.LeaseID,
+		Type:         r.Type,
+		ScheduledFor: parseDateTime(r.ScheduledFor),
+		Inspector:    r.Inspector,
+	}
+	if r.PropertyID != nil {
+		inspection.PropertyID = *r.PropertyID
+	}
+	return inspection
+}
+
+// RescheduleInspectionRequest is the body accepted when moving a scheduled
+// inspection or handing it to another inspector.
+type RescheduleInspectionRequest struct {
+	ScheduledFor string `json:"scheduled_for" validate:"required,datetime=2006-01-02T15:04:05Z07:00" example:"2025-03-01T10:00:00Z"`
+	Inspector    string `json:"inspector" validate:"max=100"`
+}
+
+// Time returns the new time of the inspection.
+func (r RescheduleInspectionRequest) Time() time.Time {
+	return parseDateTime(r.ScheduledFor)
+}
+
+// InspectionSubmissionRequest is the body accepted when submitting the
+// results of an inspection: a rating for every item of its checklist and
+// any notes. Photos are uploaded separately beforehand.
+type InspectionSubmissionRequest struct {
+	Notes   string                    `json:"notes" validate:"max=5000"`
+	Results []InspectionResultRequest `json:"results" validate:"required,min=1,max=5000,dive"`
+}
+
+// InspectionResultRequest is the condition an item was found in.
+type InspectionResultRequest struct {
+	Room   string `json:"room" validate:"required,max=100" example:"Kitchen"`
+	Item   string `json:"item" validate:"required,max=200" example:"Oven"`
+	Rating string `json:"rating" validate:"required,max=50" example:"good"`
+	Notes  string `json:"notes" validate:"max=2000"`
+}
+
+// ToModel returns the results described by the request.
+func (r InspectionSubmissionRequest) ToModel(inspectionID uint) []model.InspectionResult {
+	results := make([]model.InspectionResult, 0, len(r.Results))
+	for _, result := range r.Results {
+		results = append(results, model.InspectionResult{
+			InspectionID: inspectionID,
+			Room:         result.Room,
+			Item:         result.Item,
+			Rating:       result.Rating,
+			Notes:        result.Notes,
+		})
+	}
+	return results
+}
+
+func parseDateTime(value string) time.Time {
+	t, _ := time.Parse(DateTimeLayout, value)
+	return t.UTC()
+}
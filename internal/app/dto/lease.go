@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+// DateLayout is the format of calendar dates in request bodies.
+const DateLayout = "2006-01-02"
+
+// LeaseRequest is the body accepted when drafting a lease or replacing a
+// draft. It needs a property, a unit, or both; a unit implies its property.
+type LeaseRequest struct {
+	PropertyID *uint           `json:"property_id" validate:"omitempty,gt=0"`
+	UnitID     *uint           `json:"unit_id" validate:"omitempty,gt=0"`
+	TenantIDs  []uint          `json:"tenant_ids" validate:"required,min=1,max=10,dive,gt=0"`
+	StartDate  string          `json:"start_date" validate:"required,datetime=2006-01-02" example:"2025-01-01"`
+	EndDate    string          `json:"end_date" validate:"required,datetime=2006-01-02" example:"2025-12-31"`
+	Rent       decimal.Decimal `json:"rent" validate:"required" swaggertype:"string" example:"950.00"`
+	Deposit    decimal.Decimal `json:"deposit" swaggertype:"string" example:"950.00"`
+	BillingDay int             `json:"billing_day" validate:"min=1,max=28"`
+	Terms      string          `json:"terms" validate:"max=20000"`
+}
+
+// ToModel returns the draft lease described by the request.
+func (r LeaseRequest) ToModel(id uint) model.Lease {
+	tenants := make([]model.Tenant, 0, len(r.TenantIDs))
+	for _, tenantID := range r.TenantIDs {
+		tenants = append(tenants, model.Tenant{ID: tenantID})
+	}
+
+	lease := model.Lease{
+		ID:         id,
+		UnitID:     r.UnitID,
+		Status:     model.LeaseStatusDraft,
+		StartDate:  parseDate(r.StartDate),
+		EndDate:    parseDate(r.EndDate),
+		Rent:       r.Rent,
+		Deposit:    r.Deposit,
+		BillingDay: r.BillingDay,
+		Terms:      r.Terms,
+		Tenants:    tenants,
+	}
+	if r.PropertyID != nil {
+		lease.PropertyID = *r.PropertyID
+	}
+	return lease
+}
+
+// RenewLeaseRequest is the body accepted when renewing a lease. The renewal
+// starts the day after the current lease ends and keeps its tenants.
+type RenewLeaseRequest struct {
+	EndDate    string          `json:"end_date" validate:"required,datetime=2006-01-02" example:"2026-12-31"`
+	Rent       decimal.Decimal `json:"rent" validate:"required" swaggertype:"string" example:"950.00"`
+	Deposit    decimal.Decimal `json:"deposit" swaggertype:"string" example:"950.00"`
+	BillingDay int             `json:"billing_day" validate:"min=1,max=28"`
+	Terms      string          `json:"terms" validate:"max=20000"`
+}
+
+// ToModel returns the renewal terms described by the request.
+func (r RenewLeaseRequest) ToModel() model.Lease {
+	return model.Lease{
+		Status:     model.LeaseStatusDraft,
+		EndDate:    parseDate(r.EndDate),
+		Rent:       r.Rent,
+		Deposit:    r.Deposit,
+		BillingDay: r.BillingDay,
+		Terms:      r.Terms,
+	}
+}
+
+// TerminateLeaseRequest is the body accepted when ending a lease early.
+type TerminateLeaseRequest struct {
+	// Date is the tenants' last day, which becomes the lease's end date.
+	Date   string `json:"date" validate:"required,datetime=2006-01-02" example:"2025-06-30"`
+	Reason string `json:"reason" validate:"required,max=2000"`
+}
+
+// EndDate returns the parsed termination date.
+func (r TerminateLeaseRequest) EndDate() time.Time {
+	return parseDate(r.Date)
+}
+
+// parseDate parses a date that has already passed validation.
+func parseDate(value string) time.Time {
+	date, _ := time.Parse(DateLayout, value)
+	return date
+}
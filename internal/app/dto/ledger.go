@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+// ChargeRequest is the body accepted when charging a lease a one-off amount,
+// such as a utility bill.
+type ChargeRequest struct {
+	Date        string          `json:"date" validate:"required,datetime=2006-01-02" example:"2025-03-15"`
+	Category    string          `json:"category" validate:"required,oneof=rent late_fee utilities deposit refund other"`
+	Description string          `json:"description" validate:"required,max=500"`
+	Amount      decimal.Decimal `json:"amount" validate:"required" swaggertype:"string" example:"120.50"`
+}
+
+// ToModel returns the ledger entry described by the request.
+func (r ChargeRequest) ToModel(leaseID uint) model.LedgerEntry {
+	return model.LedgerEntry{
+		LeaseID:     leaseID,
+		Type:        model.LedgerEntryCharge,
+		Category:    r.Category,
+		Date:        parseDate(r.Date),
+		Description: r.Description,
+		Amount:      r.Amount,
+	}
+}
+
+// PaymentRequest is the body accepted when recording a payment received.
+type PaymentRequest struct {
+	Date        string          `json:"date" validate:"required,datetime=2006-01-02" example:"2025-03-01"`
+	Description string          `json:"description" validate:"max=500"`
+	Amount      decimal.Decimal `json:"amount" validate:"required" swaggertype:"string" example:"950.00"`
+	Reference   string          `json:"reference" validate:"max=100"`
+}
+
+// ToModel returns the ledger entry described by the request.
+func (r PaymentRequest) ToModel(leaseID uint) model.LedgerEntry {
+	return model.LedgerEntry{
+		LeaseID:     leaseID,
+		Type:        model.LedgerEntryPayment,
+		Date:        parseDate(r.Date),
+		Description: r.Description,
+		Amount:      r.Amount,
+		Reference:   r.Reference,
+	}
+}
+
+// CreditRequest is the body accepted when crediting a lease, for example to
+// waive a fee.
+type CreditRequest struct {
+	Date        string          `json:"date" validate:"required,datetime=2006-01-02" example:"2025-03-20"`
+	Description string          `json:"description" validate:"required,max=500"`
+	Amount      decimal.Decimal `json:"amount" validate:"required" swaggertype:"string" example:"25.00"`
+}
+
+// ToModel returns the ledger entry described by the request.
+func (r CreditRequest) ToModel(leaseID uint) model.LedgerEntry {
+	return model.LedgerEntry{
+		LeaseID:     leaseID,
+		Type:        model.LedgerEntryCredit,
+		Date:        parseDate(r.Date),
+		Description: r.Description,
+		Amount:      r.Amount,
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "propmanager/internal/app/model"
+
+// MaintenanceTicketRequest is the body accepted when reporting a
+// maintenance request or replacing its details. It needs a property, a
+// unit, or both; a unit implies its property.
+type MaintenanceTicketRequest struct {
+	PropertyID  *uint  `json:"property_id" validate:"omitempty,gt=0"`
+	UnitID      *uint  `json:"unit_id" validate:"omitempty,gt=0"`
+	TenantID    *uint  `json:"tenant_id" validate:"omitempty,gt=0"`
+	Category    string `json:"category" validate:"required,oneof=plumbing electrical hvac appliance structural pest locks grounds other"`
+	Priority    string `json:"priority" validate:"required,oneof=emergency high normal low"`
+	Description string `json:"description" validate:"required,max=5000"`
+}
+
+// ToModel returns the maintenance request described by the request.
+func (r MaintenanceTicketRequest) ToModel(id uint) model.MaintenanceRequest {
+	ticket := model.MaintenanceRequest{
+		ID:          id,
+		UnitID:      r.UnitID,
+		TenantID:    r.TenantID,
+		Category:    r.Category,
+		Priority:    r.Priority,
+		Description: r.Description,
+	}
+	if r.PropertyID != nil {
+		ticket.PropertyID = *r.PropertyID
+	}
+	return ticket
+}
+
+// AssignMaintenanceRequest is the body accepted when assigning a
+// maintenance request. An empty assignee unassigns it.
+type AssignMaintenanceRequest struct {
+	Assignee string `json:"assignee" validate:"max=100"`
+	Note     string `json:"note" validate:"max=5000"`
+}
+
+// MaintenanceStatusRequest is the body accepted when moving a maintenance
+// request through its workflow.
+type MaintenanceStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=open assigned in_progress waiting_on_parts resolved closed"`
+	Note   string `json:"note" validate:"max=5000"`
+}
+
+// MaintenanceCommentRequest is the body accepted when commenting on a
+// maintenance request.
+type MaintenanceCommentRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
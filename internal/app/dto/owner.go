@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+// OwnerRequest is the body accepted when creating or replacing an owner.
+type OwnerRequest struct {
+	Name           string `json:"name" validate:"required,max=200"`
+	Email          string `json:"email" validate:"omitempty,email,max=254"`
+	Phone          string `json:"phone" validate:"omitempty,max=30"`
+	MailingAddress string `json:"mailing_address" validate:"max=500"`
+	Notes          string `json:"notes" validate:"max=5000"`
+}
+
+// ToModel returns the owner described by the request.
+func (r OwnerRequest) ToModel(id uint) model.Owner {
+	return model.Owner{
+		ID:             id,
+		Name:           r.Name,
+		Email:          r.Email,
+		Phone:          r.Phone,
+		MailingAddress: r.MailingAddress,
+		Notes:          r.Notes,
+	}
+}
+
+// PropertyOwnersRequest is the body accepted when replacing the owners of
+// a property. The percentages add up to at most 100.
+type PropertyOwnersRequest struct {
+	Owners []OwnerShareRequest `json:"owners" validate:"max=50,dive"`
+}
+
+type OwnerShareRequest struct {
+	OwnerID uint            `json:"owner_id" validate:"required,gt=0"`
+	Percent decimal.Decimal `json:"percent" validate:"required" swaggertype:"string" example:"50.00"`
+}
+
+// ToModel returns the property's shares described by the request.
+func (r PropertyOwnersRequest) ToModel(propertyID uint) []model.Ownership {
+	ownerships := make([]model.Ownership, 0, len(r.Owners))
+	for _, share := range r.Owners {
+		ownerships = append(ownerships, model.Ownership{OwnerID: share.OwnerID, PropertyID: propertyID, Percent: share.Percent})
+	}
+	return ownerships
+}
+
+// ManagementAgreementRequest is the body accepted when creating or
+// replacing a management agreement.
+type ManagementAgreementRequest struct {
+	StartDate  string          `json:"start_date" validate:"required,datetime=2006-01-02" example:"2025-01-01"`
+	EndDate    string          `json:"end_date" validate:"omitempty,datetime=2006-01-02" example:"2025-12-31"`
+	FeePercent decimal.Decimal `json:"fee_percent" swaggertype:"string" example:"8.00"`
+	FlatFee    decimal.Decimal `json:"flat_fee" swaggertype:"string" example:"0.00"`
+	Notes      string          `json:"notes" validate:"max=5000"`
+}
+
+// ToModel returns the management agreement described by the request.
+func (r ManagementAgreementRequest) ToModel(id uint, propertyID uint) model.ManagementAgreement {
+	agreement := model.ManagementAgreement{
+		ID:         id,
+		PropertyID: propertyID,
+		StartDate:  parseDate(r.StartDate),
+		FeePercent: r.FeePercent,
+		FlatFee:    r.FlatFee,
+		Notes:      r.Notes,
+	}
+	if r.EndDate != "" {
+		end := parseDate(r.EndDate)
+		agreement.EndDate = &end
+	}
+	return agreement
+}
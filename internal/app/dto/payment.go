@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// PaymentIntentRequest is the body accepted when starting an online payment
+// towards a lease. Without a charge it pays the lease's balance; without an
+// amount it pays all that is due.
+type PaymentIntentRequest struct {
+	ChargeID *uint            `json:"charge_id" validate:"omitempty,gt=0"`
+	Amount   *decimal.Decimal `json:"amount" swaggertype:"string" example:"950.00"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import "propmanager/internal/app/model"
+
+// CreatePropertyRequest is the body accepted when creating a property.
+type CreatePropertyRequest struct {
+	Name        string   `json:"name" validate:"required,min=1,max=200"`
+	Description string   `json:"description" validate:"max=5000"`
+	Price       *float64 `json:"price" validate:"required,gte=0,lte=1000000000"`
+	Location    string   `json:"location" validate:"required,max=200"`
+	Status      string   `json:"status" validate:"omitempty,oneof=available rented sold off_market"`
+}
+
+// ToModel returns the property described by the request.
+func (r CreatePropertyRequest) ToModel() model.Property {
+	return model.Property{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       *r.Price,
+		Location:    r.Location,
+		Status:      statusOrDefault(r.Status),
+	}
+}
+
+// UpdatePropertyRequest is the body accepted when replacing a property's
+// editable fields, and the document a patch is applied to.
+type UpdatePropertyRequest struct {
+	Name        string   `json:"name" validate:"required,min=1,max=200"`
+	Description string   `json:"description" validate:"max=5000"`
+	Price       *float64 `json:"price" validate:"required,gte=0,lte=1000000000"`
+	Location    string   `json:"location" validate:"required,max=200"`
+	Status      string   `json:"status" validate:"omitempty,oneof=available rented sold off_market"`
+}
+
+// NewUpdatePropertyRequest returns the editable fields of an existing property.
+func NewUpdatePropertyRequest(property model.Property) UpdatePropertyRequest {
+	price := property.Price
+	return UpdatePropertyRequest{
+		Name:        property.Name,
+		Description: property.Description,
+		Price:       &price,
+		Location:    property.Location,
+		Status:      property.Status,
+	}
+}
+
+// ToModel returns the property with the given ID described by the request.
+func (r UpdatePropertyRequest) ToModel(id uint) model.Property {
+	return model.Property{
+		ID:          id,
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       *r.Price,
+		Location:    r.Location,
+		Status:      statusOrDefault(r.Status),
+	}
+}
+
+func statusOrDefault(status string) string {
+	if status == "" {
+		return model.PropertyStatusAvailable
+	}
+	return status
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "propmanager/internal/app/model"
+
+// TenantRequest is the body accepted when creating or replacing a tenant.
+// Emergency contacts replace the tenant's existing ones.
+type TenantRequest struct {
+	FirstName           string                    `json:"first_name" validate:"required,max=100"`
+	LastName            string                    `json:"last_name" validate:"required,max=100"`
+	Email               string                    `json:"email" validate:"omitempty,email,max=254"`
+	Phone               string                    `json:"phone" validate:"omitempty,max=30"`
+	MailingAddress      string                    `json:"mailing_address" validate:"max=500"`
+	IdentificationNotes string                    `json:"identification_notes" validate:"max=2000"`
+	PropertyID          *uint                     `json:"property_id" validate:"omitempty,gt=0"`
+	UnitID              *uint                     `json:"unit_id" validate:"omitempty,gt=0"`
+	EmergencyContacts   []EmergencyContactRequest `json:"emergency_contacts" validate:"max=10,dive"`
+}
+
+type EmergencyContactRequest struct {
+	Name         string `json:"name" validate:"required,max=200"`
+	Relationship string `json:"relationship" validate:"max=100"`
+	Phone        string `json:"phone" validate:"required_without=Email,max=30"`
+	Email        string `json:"email" validate:"omitempty,email,max=254"`
+}
+
+// ToModel returns the tenant described by the request.
+func (r TenantRequest) ToModel(id uint) model.Tenant {
+	contacts := make([]model.EmergencyContact, 0, len(r.EmergencyContacts))
+	for _, contact := range r.EmergencyContacts {
+		contacts = append(contacts, model.EmergencyContact{
+			TenantID:     id,
+			Name:         contact.Name,
+			Relationship: contact.Relationship,
+			Phone:        contact.Phone,
+			Email:        contact.Email,
+		})
+	}
+
+	return model.Tenant{
+		ID:                  id,
+		FirstName:           r.FirstName,
+		LastName:            r.LastName,
+		Email:               r.Email,
+		Phone:               r.Phone,
+		MailingAddress:      r.MailingAddress,
+		IdentificationNotes: r.IdentificationNotes,
+		PropertyID:          r.PropertyID,
+		UnitID:              r.UnitID,
+		EmergencyContacts:   contacts,
+	}
+}
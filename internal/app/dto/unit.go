@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+// CreateUnitRequest is the body accepted when adding a unit to a property.
+type CreateUnitRequest struct {
+	Number     string           `json:"number" validate:"required,min=1,max=20"`
+	Floor      int              `json:"floor" validate:"gte=-10,lte=200"`
+	Bedrooms   int              `json:"bedrooms" validate:"gte=0,lte=50"`
+	Bathrooms  float64          `json:"bathrooms" validate:"gte=0,lte=50"`
+	SquareFeet int              `json:"square_feet" validate:"gte=0,lte=1000000"`
+	Rent       *decimal.Decimal `json:"rent" validate:"required" swaggertype:"string" example:"950.00"`
+	Status     string           `json:"status" validate:"omitempty,oneof=vacant occupied reserved unavailable"`
+}
+
+// ToModel returns the unit of the given property described by the request.
+func (r CreateUnitRequest) ToModel(propertyID uint) model.Unit {
+	return model.Unit{
+		PropertyID: propertyID,
+		Number:     r.Number,
+		Floor:      r.Floor,
+		Bedrooms:   r.Bedrooms,
+		Bathrooms:  r.Bathrooms,
+		SquareFeet: r.SquareFeet,
+		Rent:       *r.Rent,
+		Status:     unitStatusOrDefault(r.Status),
+	}
+}
+
+// UpdateUnitRequest is the body accepted when replacing a unit's editable fields.
+type UpdateUnitRequest CreateUnitRequest
+
+// ToModel returns the unit with the given IDs described by the request.
+func (r UpdateUnitRequest) ToModel(propertyID uint, unitID uint) model.Unit {
+	unit := CreateUnitRequest(r).ToModel(propertyID)
+	unit.ID = unitID
+	return unit
+}
+
+func unitStatusOrDefault(status string) string {
+	if status == "" {
+		return model.UnitStatusVacant
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+// VendorRequest is the body accepted when creating or replacing a vendor.
+type VendorRequest struct {
+	Name               string          `json:"name" validate:"required,max=200"`
+	Trade              string          `json:"trade" validate:"required,oneof=plumbing electrical hvac appliance structural pest locks grounds general"`
+	ContactName        string          `json:"contact_name" validate:"max=200"`
+	Phone              string          `json:"phone" validate:"omitempty,max=30"`
+	Email              string          `json:"email" validate:"omitempty,email,max=254"`
+	ServiceAreas       []string        `json:"service_areas" validate:"max=50,dive,required,max=100"`
+	HourlyRate         decimal.Decimal `json:"hourly_rate" swaggertype:"string" example:"85.00"`
+	CalloutFee         decimal.Decimal `json:"callout_fee" swaggertype:"string" example:"60.00"`
+	InsuranceExpiresOn string          `json:"insurance_expires_on" validate:"omitempty,datetime=2006-01-02" example:"2026-12-31"`
+	Notes              string          `json:"notes" validate:"max=5000"`
+}
+
+// ToModel returns the vendor described by the request.
+func (r VendorRequest) ToModel(id uint) model.Vendor {
+	vendor := model.Vendor{
+		ID:           id,
+		Name:         r.Name,
+		Trade:        r.Trade,
+		ContactName:  r.ContactName,
+		Phone:        r.Phone,
+		Email:        r.Email,
+		ServiceAreas: r.ServiceAreas,
+		HourlyRate:   r.HourlyRate,
+		CalloutFee:   r.CalloutFee,
+		Notes:        r.Notes,
+	}
+	if vendor.ServiceAreas == nil {
+		vendor.ServiceAreas = []string{}
+	}
+	if r.InsuranceExpiresOn != "" {
+		expires := parseDate(r.InsuranceExpiresOn)
+		vendor.InsuranceExpiresOn = &expires
+	}
+	return vendor
+}
+
+// WorkOrderRequest is the body accepted when issuing a work order for a
+// maintenance request.
+type WorkOrderRequest struct {
+	VendorID    uint            `json:"vendor_id" validate:"required,gt=0"`
+	Description string          `json:"description" validate:"required,max=5000"`
+	Estimate    decimal.Decimal `json:"estimate" validate:"required" swaggertype:"string" example:"750.00"`
+}
+
+// ToModel returns the work order described by the request.
+func (r WorkOrderRequest) ToModel(maintenanceRequestID uint) model.WorkOrder {
+	return model.WorkOrder{
+		MaintenanceRequestID: maintenanceRequestID,
+		VendorID:             r.VendorID,
+		Description:          r.Description,
+		Estimate:             r.Estimate,
+	}
+}
+
+// WorkOrderReasonRequest is the body accepted when rejecting or cancelling
+// a work order.
+type WorkOrderReasonRequest struct {
+	Reason string `json:"reason" validate:"required,max=2000"`
+}
+
+// InvoiceRequest is the body accepted when recording a vendor's invoice
+// for a work order.
+type InvoiceRequest struct {
+	Number string          `json:"number" validate:"required,max=100"`
+	Date   string          `json:"date" validate:"required,datetime=2006-01-02" example:"2025-03-20"`
+	Amount decimal.Decimal `json:"amount" validate:"required" swaggertype:"string" example:"720.00"`
+}
+
+// InvoiceDate returns the parsed invoice date.
+func (r InvoiceRequest) InvoiceDate() time.Time {
+	return parseDate(r.Date)
+}
+
+// SignOffRequest is the body accepted when signing off the work of a work
+// order as complete.
+type SignOffRequest struct {
+	Notes string `json:"notes" validate:"max=5000"`
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+var (
+	propertiesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "properties"),
+		"Number of properties that are not deleted, by status.",
+		[]string{"status"}, nil,
+	)
+	imagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "images"),
+		"Number of property images that are not deleted.",
+		nil, nil,
+	)
+)
+
+// BusinessCollector reports portfolio gauges, queried from the database on every scrape.
+type BusinessCollector struct {
+	db *gorm.DB
+}
+
+func NewBusinessCollector(db *gorm.DB) *BusinessCollector {
+	return &BusinessCollector{db: db}
+}
+
+func (c *BusinessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- propertiesDesc
+	ch <- imagesDesc
+}
+
+func (c *BusinessCollector) Collect(ch chan<- prometheus.Metric) {
+	var statusCounts []struct {
+		Status string
+		Count  int64
+	}
+	err := c.db.Model(&model.Property{}).Select("status, COUNT(*) AS count").Group("status").Scan(&statusCounts).Error
+	if err != nil {
+		slog.Error("Error collecting property metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(propertiesDesc, err)
+	} else {
+		for _, statusCount := range statusCounts {
+			ch <- prometheus.MustNewConstMetric(propertiesDesc, prometheus.GaugeValue, float64(statusCount.Count), statusCount.Status)
+		}
+	}
+
+	var images int64
+	if err := c.db.Model(&model.Image{}).Count(&images).Error; err != nil {
+		slog.Error("Error collecting image metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(imagesDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(imagesDesc, prometheus.GaugeValue, float64(images))
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// GormPlugin records the duration of every query issued through a gorm.DB.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// registrar is satisfied by the callbacks returned from gorm's processors.
+type registrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	hooks := []struct {
+		operation string
+		before    registrar
+		after     registrar
+	}{
+		{"create", callback.Create().Before("gorm:create"), callback.Create().After("gorm:create")},
+		{"query", callback.Query().Before("gorm:query"), callback.Query().After("gorm:query")},
+		{"update", callback.Update().Before("gorm:update"), callback.Update().After("gorm:update")},
+		{"delete", callback.Delete().Before("gorm:delete"), callback.Delete().After("gorm:delete")},
+		{"row", callback.Row().Before("gorm:row"), callback.Row().After("gorm:row")},
+		{"raw", callback.Raw().Before("gorm:raw"), callback.Raw().After("gorm:raw")},
+	}
+
+	for _, hook := range hooks {
+		if err := hook.before.Register("metrics:before_"+hook.operation, before); err != nil {
+			return err
+		}
+		if err := hook.after.Register("metrics:after_"+hook.operation, after(hook.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbQueryErrorsTotal.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
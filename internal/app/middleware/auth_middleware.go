@@ -6,13 +6,30 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/logging"
 )
 
+// UsernameKey is the context key under which the authenticated username is stored.
+const UsernameKey = "username"
+
+var errInvalidToken = apperror.Unauthorized("invalid_token", "Authorization token is invalid or expired.")
+
+// AuthMiddleware authenticates requests by a bearer JWT signed with
+// secretKey or, for internal callers using mutual TLS, by a client
+// certificate the server has verified, whose common name is the username.
 func AuthMiddleware(secretKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if username, ok := clientCertificateUser(c.Request); ok {
+			setUser(c, username)
+			c.Next()
+			return
+		}
+
 		token := c.GetHeader("Authorization")
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized, missing token"})
+			c.Error(apperror.Unauthorized("missing_token", "Authorization token is missing."))
 			c.Abort()
 			return
 		}
@@ -24,17 +41,38 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 		})
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized, invalid token - " + err.Error()})
+			c.Error(errInvalidToken.Wrap(err))
 			c.Abort()
 			return
 		}
 
 		if !claims.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized, invalid token"})
+			c.Error(errInvalidToken)
 			c.Abort()
 			return
 		}
 
+		if mapClaims, ok := claims.Claims.(jwt.MapClaims); ok {
+			if username, ok := mapClaims["username"].(string); ok {
+				setUser(c, username)
+			}
+		}
+
 		c.Next()
 	}
 }
+
+func setUser(c *gin.Context, username string) {
+	c.Set(UsernameKey, username)
+	c.Request = c.Request.WithContext(logging.WithUser(c.Request.Context(), username))
+}
+
+// clientCertificateUser returns the common name of the request's client
+// certificate if the TLS handshake verified it against the client CAs.
+func clientCertificateUser(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	username := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	return username, username != ""
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response. Code is a stable,
+// machine-readable identifier for the error.
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Code     string                `json:"code"`
+	Errors   []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem maps err to a problem response. Only domain errors expose
+// their message; anything else is reported as an internal error.
+func NewProblem(err error, instance string) Problem {
+	appErr, ok := apperror.As(err)
+	if !ok {
+		return Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(http.StatusInternalServerError),
+			Status:   http.StatusInternalServerError,
+			Detail:   "An unexpected error occurred.",
+			Instance: instance,
+			Code:     "internal_error",
+		}
+	}
+
+	status := appErr.HTTPStatus()
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   appErr.Message,
+		Instance: instance,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	}
+}
+
+// ErrorHandler logs every error attached to the request, including internal
+// details, and if the handler has not written a response, renders the last
+// one as problem+json.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		for _, e := range c.Errors {
+			level := slog.LevelError
+			if appErr, ok := apperror.As(e.Err); ok && appErr.HTTPStatus() < http.StatusInternalServerError {
+				level = slog.LevelInfo
+			}
+			slog.Log(c.Request.Context(), level, "Request error", "method", c.Request.Method, "path", c.Request.URL.Path, "error", e.Error())
+		}
+
+		if c.Writer.Written() {
+			return
+		}
+
+		problem := NewProblem(c.Errors.Last().Err, c.Request.URL.Path)
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/app/apperror"
+)
+
+// TestErrorHandlerRendersProblems checks that errors attached by handlers
+// are rendered as problem+json with the status and code of their kind, and
+// that internal details are logged but never returned.
+func TestErrorHandlerRendersProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	internal := errors.New("sql: database is locked")
+	errNotFound := apperror.NotFound("property_not_found", "Property not found.")
+	fields := []apperror.FieldError{{Field: "price", Reason: "must be greater than or equal to 0"}}
+
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/not-found", func(c *gin.Context) { c.Error(errNotFound.Wrap(internal)) })
+	r.GET("/invalid", func(c *gin.Context) { c.Error(apperror.Validation(fields)) })
+	r.GET("/internal", func(c *gin.Context) { c.Error(internal) })
+	r.GET("/written", func(c *gin.Context) {
+		c.Error(internal)
+		c.String(http.StatusAccepted, "accepted")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+		fields []apperror.FieldError
+	}{
+		{"/not-found", http.StatusNotFound, "property_not_found", nil},
+		{"/invalid", http.StatusUnprocessableEntity, "validation_failed", fields},
+		{"/internal", http.StatusInternalServerError, "internal_error", nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.status)
+		}
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, ProblemContentType) {
+			t.Errorf("%s: Content-Type = %q, want %s", tt.path, got, ProblemContentType)
+		}
+		var problem Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if problem.Status != tt.status || problem.Code != tt.code || problem.Instance != tt.path || problem.Title != http.StatusText(tt.status) {
+			t.Errorf("%s: problem = %+v", tt.path, problem)
+		}
+		if !reflect.DeepEqual(problem.Errors, tt.fields) {
+			t.Errorf("%s: errors = %+v, want %+v", tt.path, problem.Errors, tt.fields)
+		}
+		if strings.Contains(w.Body.String(), "database is locked") {
+			t.Errorf("%s: response exposes the internal error: %s", tt.path, w.Body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "accepted" {
+		t.Errorf("response already written: got %d %q, want it left as written", w.Code, w.Body)
+	}
+
+	if n := strings.Count(logs.String(), "database is locked"); n != 3 {
+		t.Errorf("internal error logged %d times, want 3:\n%s", n, logs.String())
+	}
+}
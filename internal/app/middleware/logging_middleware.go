@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/logging"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID accepts the caller's X-Request-ID, or generates one, returns it
+// in the response and stores it in the request context for logging.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs made of URL-safe characters, so that a
+// caller cannot inject arbitrary text into the logs.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// maxLoggedBodyBytes caps how much of a request body is logged at debug level.
+const maxLoggedBodyBytes = 4096
+
+// Logger writes one access log line per request. The request ID and user
+// are added from the request context. At debug level the request headers
+// and body are logged too, with credentials masked.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		if slog.Default().Enabled(c.Request.Context(), slog.LevelDebug) {
+			logRequestDetails(c)
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		slog.Log(c.Request.Context(), level, "HTTP request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
+
+func logRequestDetails(c *gin.Context) {
+	attrs := []any{"headers", logging.RedactHeaders(c.Request.Header)}
+
+	contentType := c.GetHeader("Content-Type")
+	switch {
+	case c.Request.Body == nil || c.Request.ContentLength == 0:
+	case strings.HasPrefix(contentType, "multipart/"):
+		attrs = append(attrs, "body", "[multipart body omitted]")
+	default:
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBodyBytes+1))
+		if err != nil {
+			break
+		}
+		// Put back what was read so the handler sees the whole body.
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+
+		truncated := len(body) > maxLoggedBodyBytes
+		if truncated {
+			body = body[:maxLoggedBodyBytes]
+		}
+		attrs = append(attrs, "body", logging.RedactBody(contentType, body), "body_truncated", truncated)
+	}
+
+	slog.DebugContext(c.Request.Context(), "HTTP request details", attrs...)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"propmanager/internal/logging"
+)
+
+// TestRequestIDPropagation checks that the request ID is accepted from the
+// caller, or generated, and that it is returned in the response and carried
+// by both the access log and whatever the handler logs with the request context.
+func TestRequestIDPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&logs, "info", "json"))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	r := gin.New()
+	r.Use(RequestID(), Logger())
+	r.GET("/properties/:id", func(c *gin.Context) {
+		slog.InfoContext(c.Request.Context(), "Loading property")
+		c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+	})
+
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"accepted", "lb-7f3a.42:1", true},
+		{"missing", "", false},
+		{"unsafe", "abc\n{\"level\":\"ERROR\"}", false},
+		{"too long", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/properties/7", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			switch {
+			case tt.keep && requestID != tt.incoming:
+				t.Errorf("%s = %q, want the caller's %q", RequestIDHeader, requestID, tt.incoming)
+			case !tt.keep && !generated.MatchString(requestID):
+				t.Errorf("%s = %q, want a generated ID", RequestIDHeader, requestID)
+			}
+			if w.Body.String() != requestID {
+				t.Errorf("request ID in the handler's context = %q, want %q", w.Body.String(), requestID)
+			}
+
+			var lines []map[string]interface{}
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				var record map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("log line %q: %v", line, err)
+				}
+				lines = append(lines, record)
+			}
+			if len(lines) != 2 || lines[0]["msg"] != "Loading property" || lines[1]["msg"] != "HTTP request" {
+				t.Fatalf("log = %s, want the handler's line then the access log", logs.String())
+			}
+			for _, record := range lines {
+				if record["request_id"] != requestID {
+					t.Errorf("%q logged with request_id %v, want %q", record["msg"], record["request_id"], requestID)
+				}
+			}
+			access := lines[1]
+			if access["route"] != "/properties/:id" || access["status"] != float64(http.StatusOK) || access["bytes"] != float64(len(requestID)) {
+				t.Errorf("access log = %v", access)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"propmanager/internal/tracing"
+)
+
+// TestTracingContinuesInboundTrace checks that a request's server span
+// continues the caller's W3C trace, parents the spans started while handling
+// it, and records the route, status and request ID.
+func TestTracingContinuesInboundTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	r := gin.New()
+	r.Use(RequestID(), Tracing())
+	r.GET("/properties/:id", func(c *gin.Context) {
+		_, span := tracing.Tracer().Start(c.Request.Context(), "s3.PutObject")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+	r.GET("/broken", func(c *gin.Context) {
+		c.Error(errors.New("bucket unreachable"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/properties/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(RequestIDHeader, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/broken", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+	child, server, broken := spans[0], spans[1], spans[2]
+
+	if server.Name() != "GET /properties/:id" || server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span = %s (%s), want GET /properties/:id (server)", server.Name(), server.SpanKind())
+	}
+	if got := server.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the caller's", got)
+	}
+	if got := server.Parent().SpanID().String(); got != "00f067aa0ba902b7" || !server.Parent().IsRemote() {
+		t.Errorf("server span parent = %s, want the caller's remote span", got)
+	}
+	if child.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("handler span is not a child of the server span")
+	}
+
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range server.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["http.response.status_code"].AsInt64() != http.StatusOK || attributes["http.request_id"].AsString() != "req-1" {
+		t.Errorf("server span attributes = %v", server.Attributes())
+	}
+
+	if broken.SpanContext().TraceID() == server.SpanContext().TraceID() || broken.Parent().IsValid() {
+		t.Errorf("a request without traceparent should start a new trace")
+	}
+	if broken.Status().Code != codes.Error || len(broken.Events()) != 1 {
+		t.Errorf("failed request span: status %v with %d events, want an error with the recorded error", broken.Status(), len(broken.Events()))
+	}
+}
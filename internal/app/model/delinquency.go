@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AgingBuckets splits unpaid amounts by how many days past due they are.
+type AgingBuckets struct {
+	Days0To30   decimal.Decimal `json:"days_0_30" swaggertype:"string" example:"950.00"`
+	Days31To60  decimal.Decimal `json:"days_31_60" swaggertype:"string" example:"0.00"`
+	Days61To90  decimal.Decimal `json:"days_61_90" swaggertype:"string" example:"0.00"`
+	DaysOver90  decimal.Decimal `json:"days_over_90" swaggertype:"string" example:"0.00"`
+	TotalUnpaid decimal.Decimal `json:"total_unpaid" swaggertype:"string" example:"950.00"`
+}
+
+// Add puts amount, which is daysPastDue days past due, in its bucket.
+func (b *AgingBuckets) Add(amount decimal.Decimal, daysPastDue int) {
+	switch {
+	case daysPastDue <= 30:
+		b.Days0To30 = b.Days0To30.Add(amount)
+	case daysPastDue <= 60:
+		b.Days31To60 = b.Days31To60.Add(amount)
+	case daysPastDue <= 90:
+		b.Days61To90 = b.Days61To90.Add(amount)
+	default:
+		b.DaysOver90 = b.DaysOver90.Add(amount)
+	}
+	b.TotalUnpaid = b.TotalUnpaid.Add(amount)
+}
+
+// DelinquentLease is a lease with unpaid charges and their aging.
+type DelinquentLease struct {
+	LeaseID    uint     `json:"lease_id"`
+	PropertyID uint     `json:"property_id"`
+	UnitID     *uint    `json:"unit_id"`
+	Status     string   `json:"status"`
+	Tenants    []string `json:"tenants"`
+	// OldestDueDate is when the oldest unpaid charge fell due.
+	OldestDueDate time.Time `json:"oldest_due_date"`
+	AgingBuckets
+}
+
+// DelinquencyReport lists the leases with unpaid charges as of a day, most
+// overdue first, with totals across them.
+type DelinquencyReport struct {
+	AsOf        time.Time         `json:"as_of"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Leases      []DelinquentLease `json:"leases"`
+	Totals      AgingBuckets      `json:"totals"`
+}
@@ -0,0 +1,22 @@
+package model
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusUp          = "up"
+	HealthStatusDown        = "down"
+)
+
+// HealthReport is the result of a liveness or readiness probe.
+type HealthReport struct {
+	Status       string                      `json:"status" example:"ok"`
+	ShuttingDown bool                        `json:"shutting_down,omitempty"`
+	Checks       map[string]DependencyHealth `json:"checks,omitempty"`
+}
+
+// DependencyHealth is the result of checking a single dependency.
+type DependencyHealth struct {
+	Status    string  `json:"status" example:"up"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,298 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Inspection types. Move-in and move-out inspections record the condition
+// of a unit at the start and end of a tenancy, so that damage can be told
+// from wear; routine inspections happen during it.
+const (
+	InspectionTypeMoveIn  = "move_in"
+	InspectionTypeMoveOut = "move_out"
+	InspectionTypeRoutine = "routine"
+)
+
+// Inspection statuses. A scheduled inspection is completed when its results
+// are submitted, or cancelled.
+const (
+	InspectionStatusScheduled = "scheduled"
+	InspectionStatusCompleted = "completed"
+	InspectionStatusCancelled = "cancelled"
+)
+
+// Changes in the condition of an item between two inspections.
+const (
+	ConditionBetter = "better"
+	ConditionSame   = "same"
+	ConditionWorse  = "worse"
+)
+
+// DefaultConditionRatings is the scale of templates that do not set their
+// own, best condition first.
+var DefaultConditionRatings = []string{"excellent", "good", "fair", "poor", "damaged"}
+
+// InspectionTemplate is a checklist of the rooms to inspect and the items to
+// rate in each, on a scale of condition ratings.
+type InspectionTemplate struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `json:"description"`
+	// Ratings is the scale items are rated on, best condition first.
+	Ratings []string         `gorm:"serializer:json" json:"ratings"`
+	Rooms   []InspectionRoom `gorm:"serializer:json" json:"rooms"`
+}
+
+// InspectionRoom is a room of a checklist and the items rated in it, such
+// as the walls, the floor and the windows of a bedroom.
+type InspectionRoom struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+// Inspection is an inspection of a property, or one of its units, scheduled
+// from a template. The checklist and rating scale are copied from the
+// template, so that later changes to the template do not affect it.
+type Inspection struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	TemplateID uint           `gorm:"not null;index" json:"template_id"`
+	PropertyID uint           `gorm:"not null;index" json:"property_id"`
+	UnitID     *uint          `gorm:"index" json:"unit_id"`
+	// LeaseID is the tenancy a move-in or move-out inspection belongs to,
+	// if known.
+	LeaseID      *uint              `gorm:"index" json:"lease_id"`
+	Type         string             `gorm:"not null;index" json:"type"`
+	Status       string             `gorm:"not null;default:scheduled;index" json:"status"`
+	ScheduledFor time.Time          `gorm:"not null;index" json:"scheduled_for"`
+	Inspector    string             `gorm:"index" json:"inspector"`
+	Ratings      []string           `gorm:"serializer:json" json:"ratings"`
+	Rooms        []InspectionRoom   `gorm:"serializer:json" json:"rooms"`
+	Notes        string             `json:"notes"`
+	CompletedAt  *time.Time         `json:"completed_at"`
+	CompletedBy  string             `json:"completed_by"`
+	Results      []InspectionResult `gorm:"foreignKey:InspectionID" json:"results"`
+	Photos       []InspectionPhoto  `gorm:"foreignKey:InspectionID" json:"photos"`
+}
+
+// HasRoom reports whether room is on the inspection's checklist.
+func (i Inspection) HasRoom(room string) bool {
+	for _, r := range i.Rooms {
+		if r.Name == room {
+			return true
+		}
+	}
+	return false
+}
+
+// HasItem reports whether item of room is on the inspection's checklist.
+func (i Inspection) HasItem(room, item string) bool {
+	for _, r := range i.Rooms {
+		if r.Name != room {
+			continue
+		}
+		for _, it := range r.Items {
+			if it == item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RatingRank returns the position of rating on the inspection's scale, zero
+// being the best condition, or -1 if it is not on the scale.
+func (i Inspection) RatingRank(rating string) int {
+	for rank, r := range i.Ratings {
+		if r == rating {
+			return rank
+		}
+	}
+	return -1
+}
+
+// Result returns the result recorded for item of room, if any.
+func (i Inspection) Result(room, item string) (InspectionResult, bool) {
+	for _, result := range i.Results {
+		if result.Room == room && result.Item == item {
+			return result, true
+		}
+	}
+	return InspectionResult{}, false
+}
+
+// InspectionResult is the condition an item was found in.
+type InspectionResult struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	InspectionID uint   `gorm:"not null;uniqueIndex:idx_inspection_result_item" json:"inspection_id"`
+	Room         string `gorm:"not null;uniqueIndex:idx_inspection_result_item" json:"room"`
+	Item         string `gorm:"not null;uniqueIndex:idx_inspection_result_item" json:"item"`
+	Rating       string `gorm:"not null" json:"rating"`
+	Notes        string `json:"notes"`
+}
+
+// InspectionPhoto is photo evidence of the condition of a property, taken
+// during an inspection and optionally tied to an item of its checklist. Like
+// maintenance photos, they are stored privately and viewed through
+// short-lived links.
+type InspectionPhoto struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	InspectionID uint           `gorm:"not null;index" json:"inspection_id"`
+	Room         string         `json:"room"`
+	Item         string         `json:"item"`
+	Name         string         `json:"name"`
+	ContentType  string         `json:"content_type"`
+	Size         int64          `json:"size"`
+	UploadedBy   string         `json:"uploaded_by"`
+	Key          string         `json:"-"`
+}
+
+// InspectionComparison sets the results of an inspection beside those of an
+// earlier baseline, typically a move-out inspection beside the move-in
+// inspection of the same unit.
+type InspectionComparison struct {
+	Baseline   ComparedInspection `json:"baseline"`
+	Inspection ComparedInspection `json:"inspection"`
+	Rooms      []ComparedRoom     `json:"rooms"`
+	// Worsened counts the items found in worse condition than at the
+	// baseline.
+	Worsened int `json:"worsened"`
+}
+
+// ComparedInspection identifies one side of a comparison.
+type ComparedInspection struct {
+	ID          uint       `json:"id"`
+	Type        string     `json:"type"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CompletedBy string     `json:"completed_by"`
+	Notes       string     `json:"notes"`
+}
+
+// ComparedRoom is a room of a comparison.
+type ComparedRoom struct {
+	Name  string         `json:"name"`
+	Items []ComparedItem `json:"items"`
+}
+
+// ComparedItem is the condition of an item at both inspections. Change is
+// empty if the item was not rated at both, or its ratings are not on the
+// same scale.
+type ComparedItem struct {
+	Item             string `json:"item"`
+	BaselineRating   string `json:"baseline_rating"`
+	BaselineNotes    string `json:"baseline_notes"`
+	BaselinePhotoIDs []uint `json:"baseline_photo_ids"`
+	Rating           string `json:"rating"`
+	Notes            string `json:"notes"`
+	PhotoIDs         []uint `json:"photo_ids"`
+	Change           string `json:"change"`
+}
+
+// NewInspectionComparison compares inspection with baseline, room by room
+// and item by item, in the order of inspection's checklist followed by
+// anything only on baseline's. Ratings are ranked on inspection's scale.
+func NewInspectionComparison(baseline, inspection Inspection) InspectionComparison {
+	comparison := InspectionComparison{
+		Baseline:   summarize(baseline),
+		Inspection: summarize(inspection),
+		Rooms:      []ComparedRoom{},
+	}
+	for _, room := range mergeRooms(inspection.Rooms, baseline.Rooms) {
+		compared := ComparedRoom{Name: room.Name, Items: make([]ComparedItem, 0, len(room.Items))}
+		for _, item := range room.Items {
+			c := ComparedItem{
+				Item:             item,
+				BaselinePhotoIDs: photoIDs(baseline.Photos, room.Name, item),
+				PhotoIDs:         photoIDs(inspection.Photos, room.Name, item),
+			}
+			before, hasBefore := baseline.Result(room.Name, item)
+			after, hasAfter := inspection.Result(room.Name, item)
+			c.BaselineRating, c.BaselineNotes = before.Rating, before.Notes
+			c.Rating, c.Notes = after.Rating, after.Notes
+			if hasBefore && hasAfter {
+				c.Change = conditionChange(inspection.RatingRank(before.Rating), inspection.RatingRank(after.Rating), before.Rating == after.Rating)
+			}
+			if c.Change == ConditionWorse {
+				comparison.Worsened++
+			}
+			compared.Items = append(compared.Items, c)
+		}
+		comparison.Rooms = append(comparison.Rooms, compared)
+	}
+	return comparison
+}
+
+func summarize(inspection Inspection) ComparedInspection {
+	return ComparedInspection{
+		ID:          inspection.ID,
+		Type:        inspection.Type,
+		CompletedAt: inspection.CompletedAt,
+		CompletedBy: inspection.CompletedBy,
+		Notes:       inspection.Notes,
+	}
+}
+
+func conditionChange(before, after int, equal bool) string {
+	switch {
+	case equal:
+		return ConditionSame
+	case before < 0 || after < 0:
+		return ""
+	case after > before:
+		return ConditionWorse
+	case after < before:
+		return ConditionBetter
+	}
+	return ConditionSame
+}
+
+// mergeRooms returns the rooms and items of first followed by those only
+// in second.
+func mergeRooms(first, second []InspectionRoom) []InspectionRoom {
+	merged := make([]InspectionRoom, 0, len(first))
+	index := map[string]int{}
+	for _, rooms := range [][]InspectionRoom{first, second} {
+		for _, room := range rooms {
+			i, ok := index[room.Name]
+			if !ok {
+				i = len(merged)
+				index[room.Name] = i
+				merged = append(merged, InspectionRoom{Name: room.Name})
+			}
+			for _, item := range room.Items {
+				if !contains(merged[i].Items, item) {
+					merged[i].Items = append(merged[i].Items, item)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func photoIDs(photos []InspectionPhoto, room, item string) []uint {
+	ids := []uint{}
+	for _, photo := range photos {
+		if photo.Room == room && photo.Item == item {
+			ids = append(ids, photo.ID)
+		}
+	}
+	return ids
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
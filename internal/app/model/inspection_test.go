@@ -0,0 +1,67 @@
+package model
+
+import "testing"
+
+func TestInspectionComparisonRanksChanges(t *testing.T) {
+	ratings := []string{"good", "fair", "damaged"}
+	moveIn := Inspection{
+		ID:      1,
+		Type:    InspectionTypeMoveIn,
+		Ratings: ratings,
+		Rooms:   []InspectionRoom{{Name: "Kitchen", Items: []string{"Walls", "Oven", "Floor"}}},
+		Results: []InspectionResult{
+			{Room: "Kitchen", Item: "Walls", Rating: "good"},
+			{Room: "Kitchen", Item: "Oven", Rating: "fair"},
+			{Room: "Kitchen", Item: "Floor", Rating: "scuffed"},
+		},
+	}
+	moveOut := Inspection{
+		ID:      2,
+		Type:    InspectionTypeMoveOut,
+		Ratings: ratings,
+		Rooms: []InspectionRoom{
+			{Name: "Kitchen", Items: []string{"Oven", "Walls", "Floor"}},
+			{Name: "Hall", Items: []string{"Door"}},
+		},
+		Results: []InspectionResult{
+			{Room: "Kitchen", Item: "Oven", Rating: "good"},
+			{Room: "Kitchen", Item: "Walls", Rating: "damaged", Notes: "hole"},
+			{Room: "Kitchen", Item: "Floor", Rating: "fair"},
+			{Room: "Hall", Item: "Door", Rating: "good"},
+		},
+		Photos: []InspectionPhoto{{ID: 9, Room: "Kitchen", Item: "Walls"}},
+	}
+
+	comparison := NewInspectionComparison(moveIn, moveOut)
+
+	want := []struct{ room, item, change string }{
+		{"Kitchen", "Oven", ConditionBetter},
+		{"Kitchen", "Walls", ConditionWorse},
+		// A rating off the scale cannot be ranked.
+		{"Kitchen", "Floor", ""},
+		// Nothing to compare with at move-in.
+		{"Hall", "Door", ""},
+	}
+	var got []struct{ room, item, change string }
+	for _, room := range comparison.Rooms {
+		for _, item := range room.Items {
+			got = append(got, struct{ room, item, change string }{room.Name, item.Item, item.Change})
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("compared %d items, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if comparison.Worsened != 1 {
+		t.Errorf("Worsened = %d, want 1", comparison.Worsened)
+	}
+	walls := comparison.Rooms[0].Items[1]
+	if walls.BaselineRating != "good" || walls.Notes != "hole" || len(walls.PhotoIDs) != 1 || walls.PhotoIDs[0] != 9 {
+		t.Errorf("walls = %+v", walls)
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Lease statuses. A lease is drafted, becomes active once signed and may be
+// renewing while its successor is negotiated. It finishes either ended, at
+// or after its end date, or terminated early.
+const (
+	LeaseStatusDraft      = "draft"
+	LeaseStatusActive     = "active"
+	LeaseStatusRenewing   = "renewing"
+	LeaseStatusEnded      = "ended"
+	LeaseStatusTerminated = "terminated"
+)
+
+// leaseTransitions lists the statuses each lease status may change to.
+var leaseTransitions = map[string][]string{
+	LeaseStatusDraft:    {LeaseStatusActive},
+	LeaseStatusActive:   {LeaseStatusRenewing, LeaseStatusEnded, LeaseStatusTerminated},
+	LeaseStatusRenewing: {LeaseStatusActive, LeaseStatusEnded, LeaseStatusTerminated},
+}
+
+// Lease is an agreement letting a property, or one of its units, to one or
+// more tenants for a fixed term. Dates are calendar days at midnight UTC;
+// the lease covers its end date.
+type Lease struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `gorm:"index" json:"deleted_at"`
+	PropertyID uint            `gorm:"not null;index" json:"property_id"`
+	UnitID     *uint           `gorm:"index" json:"unit_id"`
+	Status     string          `gorm:"not null;default:draft;index" json:"status"`
+	StartDate  time.Time       `gorm:"not null" json:"start_date"`
+	EndDate    time.Time       `gorm:"not null" json:"end_date"`
+	Rent       decimal.Decimal `gorm:"type:decimal(14,2);not null" json:"rent" swaggertype:"string" example:"950.00"`
+	Deposit    decimal.Decimal `gorm:"type:decimal(14,2);not null" json:"deposit" swaggertype:"string" example:"950.00"`
+	// BillingDay is the day of the month on which rent falls due.
+	BillingDay int    `json:"billing_day"`
+	Terms      string `json:"terms"`
+	// RenewalOfID is the lease this one renews, if any.
+	RenewalOfID       *uint      `gorm:"index" json:"renewal_of_id"`
+	TerminatedAt      *time.Time `json:"terminated_at"`
+	TerminationReason string     `json:"termination_reason"`
+	Tenants           []Tenant   `gorm:"many2many:lease_tenants" json:"tenants"`
+}
+
+// CanTransition reports whether the lease may change to status.
+func (l Lease) CanTransition(status string) bool {
+	for _, next := range leaseTransitions[l.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// InForce reports whether the lease binds its tenants, so that no other
+// lease may cover the same unit over an overlapping period.
+func (l Lease) InForce() bool {
+	return l.Status == LeaseStatusActive || l.Status == LeaseStatusRenewing
+}
@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+func TestLeaseCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{LeaseStatusDraft, LeaseStatusActive, true},
+		{LeaseStatusDraft, LeaseStatusRenewing, false},
+		{LeaseStatusDraft, LeaseStatusEnded, false},
+		{LeaseStatusDraft, LeaseStatusTerminated, false},
+		{LeaseStatusActive, LeaseStatusRenewing, true},
+		{LeaseStatusActive, LeaseStatusEnded, true},
+		{LeaseStatusActive, LeaseStatusTerminated, true},
+		{LeaseStatusActive, LeaseStatusDraft, false},
+		{LeaseStatusRenewing, LeaseStatusActive, true},
+		{LeaseStatusRenewing, LeaseStatusEnded, true},
+		{LeaseStatusRenewing, LeaseStatusTerminated, true},
+		{LeaseStatusEnded, LeaseStatusActive, false},
+		{LeaseStatusTerminated, LeaseStatusActive, false},
+		{"unknown", LeaseStatusActive, false},
+	}
+	for _, tt := range tests {
+		if got := (Lease{Status: tt.from}).CanTransition(tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s -> %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ledger entry types. Charges increase what the tenants owe; payments and
+// credits reduce it.
+const (
+	LedgerEntryCharge  = "charge"
+	LedgerEntryPayment = "payment"
+	LedgerEntryCredit  = "credit"
+)
+
+// Charge categories.
+const (
+	ChargeCategoryRent      = "rent"
+	ChargeCategoryLateFee   = "late_fee"
+	ChargeCategoryUtilities = "utilities"
+	ChargeCategoryDeposit   = "deposit"
+	ChargeCategoryOther     = "other"
+	// ChargeCategoryRefund reverses a payment refunded to the tenant.
+	ChargeCategoryRefund = "refund"
+)
+
+// LedgerEntry is a charge, payment or credit on a lease's account. Entries
+// are never changed or deleted; mistakes are reversed by further entries.
+type LedgerEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	LeaseID   uint      `gorm:"not null;index;uniqueIndex:idx_ledger_entries_posting_key,priority:1" json:"lease_id"`
+	Type      string    `gorm:"not null" json:"type"`
+	// Category classifies charges; it is empty for payments and credits.
+	Category string `json:"category,omitempty"`
+	// Date is when a charge falls due or a payment or credit was received.
+	Date        time.Time       `gorm:"not null;index" json:"date"`
+	Description string          `json:"description"`
+	Amount      decimal.Decimal `gorm:"type:decimal(14,2);not null" json:"amount" swaggertype:"string" example:"950.00"`
+	// Reference identifies a payment outside propmanager, such as a
+	// cheque number or transfer ID.
+	Reference string `json:"reference,omitempty"`
+	CreatedBy string `json:"created_by"`
+	// ChargeID is the charge a late fee was assessed on.
+	ChargeID *uint `gorm:"index" json:"charge_id,omitempty"`
+	// PostingKey identifies an entry posted automatically, such as the rent
+	// for one billing date, so that posting it again has no effect.
+	PostingKey *string `gorm:"uniqueIndex:idx_ledger_entries_posting_key,priority:2" json:"-"`
+	// Balance is what the tenants owe after this entry. It is only set in
+	// ledgers and statements.
+	Balance *decimal.Decimal `gorm:"-" json:"balance,omitempty" swaggertype:"string" example:"950.00"`
+}
+
+// SignedAmount returns the entry's effect on the balance.
+func (e LedgerEntry) SignedAmount() decimal.Decimal {
+	if e.Type == LedgerEntryCharge {
+		return e.Amount
+	}
+	return e.Amount.Neg()
+}
+
+// Ledger is the account of a lease: its entries in date order with running
+// balances, and their totals.
+type Ledger struct {
+	LeaseID       uint            `json:"lease_id"`
+	Entries       []LedgerEntry   `json:"entries"`
+	TotalCharges  decimal.Decimal `json:"total_charges" swaggertype:"string" example:"1900.00"`
+	TotalPayments decimal.Decimal `json:"total_payments" swaggertype:"string" example:"950.00"`
+	TotalCredits  decimal.Decimal `json:"total_credits" swaggertype:"string" example:"0.00"`
+	Balance       decimal.Decimal `json:"balance" swaggertype:"string" example:"950.00"`
+}
+
+// NewLedger returns the ledger of a lease given its entries in date order,
+// starting from openingBalance, and fills in their running balances.
+func NewLedger(leaseID uint, openingBalance decimal.Decimal, entries []LedgerEntry) Ledger {
+	ledger := Ledger{LeaseID: leaseID, Entries: entries, Balance: openingBalance}
+	for i := range ledger.Entries {
+		entry := &ledger.Entries[i]
+		switch entry.Type {
+		case LedgerEntryCharge:
+			ledger.TotalCharges = ledger.TotalCharges.Add(entry.Amount)
+		case LedgerEntryPayment:
+			ledger.TotalPayments = ledger.TotalPayments.Add(entry.Amount)
+		case LedgerEntryCredit:
+			ledger.TotalCredits = ledger.TotalCredits.Add(entry.Amount)
+		}
+		ledger.Balance = ledger.Balance.Add(entry.SignedAmount())
+		balance := ledger.Balance
+		entry.Balance = &balance
+	}
+	return ledger
+}
+
+// OutstandingCharge is a charge and the part of it not yet paid or credited.
+type OutstandingCharge struct {
+	Charge LedgerEntry
+	Unpaid decimal.Decimal
+}
+
+// OutstandingCharges applies the payments and credits dated on or before
+// day to the charges dated on or before day, oldest charge first, and
+// returns the charges left wholly or partly unpaid in date order. entries
+// must be in date order.
+func OutstandingCharges(entries []LedgerEntry, day time.Time) []OutstandingCharge {
+	available := decimal.Zero
+	for _, entry := range entries {
+		if entry.Type != LedgerEntryCharge && !entry.Date.After(day) {
+			available = available.Add(entry.Amount)
+		}
+	}
+
+	var outstanding []OutstandingCharge
+	for _, entry := range entries {
+		if entry.Type != LedgerEntryCharge || entry.Date.After(day) {
+			continue
+		}
+		applied := decimal.Min(available, entry.Amount)
+		available = available.Sub(applied)
+		if unpaid := entry.Amount.Sub(applied); unpaid.IsPositive() {
+			outstanding = append(outstanding, OutstandingCharge{Charge: entry, Unpaid: unpaid})
+		}
+	}
+	return outstanding
+}
+
+// Statement summarises a tenant's accounts over a period: for each of
+// their leases, the balance brought forward, the entries in the period and
+// the balance carried forward.
+type Statement struct {
+	TenantID    uint             `json:"tenant_id"`
+	TenantName  string           `json:"tenant_name"`
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Leases      []LeaseStatement `json:"leases"`
+	// AmountDue is the sum of the closing balances.
+	AmountDue decimal.Decimal `json:"amount_due" swaggertype:"string" example:"950.00"`
+}
+
+// LeaseStatement is the part of a statement covering one lease.
+type LeaseStatement struct {
+	LeaseID        uint            `json:"lease_id"`
+	PropertyID     uint            `json:"property_id"`
+	UnitID         *uint           `json:"unit_id"`
+	OpeningBalance decimal.Decimal `json:"opening_balance" swaggertype:"string" example:"0.00"`
+	Entries        []LedgerEntry   `json:"entries"`
+	TotalCharges   decimal.Decimal `json:"total_charges" swaggertype:"string" example:"950.00"`
+	TotalPayments  decimal.Decimal `json:"total_payments" swaggertype:"string" example:"0.00"`
+	TotalCredits   decimal.Decimal `json:"total_credits" swaggertype:"string" example:"0.00"`
+	ClosingBalance decimal.Decimal `json:"closing_balance" swaggertype:"string" example:"950.00"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewLedgerRunningBalance(t *testing.T) {
+	entries := []LedgerEntry{
+		{Type: LedgerEntryCharge, Amount: decimal.RequireFromString("0.10")},
+		{Type: LedgerEntryCharge, Amount: decimal.RequireFromString("0.20")},
+		{Type: LedgerEntryPayment, Amount: decimal.RequireFromString("0.25")},
+		{Type: LedgerEntryCredit, Amount: decimal.RequireFromString("0.05")},
+	}
+
+	ledger := NewLedger(1, decimal.RequireFromString("100"), entries)
+
+	want := []string{"100.1", "100.3", "100.05", "100"}
+	for i, entry := range ledger.Entries {
+		if entry.Balance == nil || entry.Balance.String() != want[i] {
+			t.Errorf("entry %d balance = %v, want %s", i, entry.Balance, want[i])
+		}
+	}
+	if got := ledger.Balance.String(); got != "100" {
+		t.Errorf("Balance = %s, want 100", got)
+	}
+	if got := ledger.TotalCharges.String(); got != "0.3" {
+		t.Errorf("TotalCharges = %s, want 0.3", got)
+	}
+	if got := ledger.TotalPayments.String(); got != "0.25" {
+		t.Errorf("TotalPayments = %s, want 0.25", got)
+	}
+	if got := ledger.TotalCredits.String(); got != "0.05" {
+		t.Errorf("TotalCredits = %s, want 0.05", got)
+	}
+}
+
+func TestOutstandingChargesSettlesOldestFirst(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, time.January, d, 0, 0, 0, 0, time.UTC) }
+	entries := []LedgerEntry{
+		{ID: 1, Type: LedgerEntryCharge, Date: day(1), Amount: decimal.RequireFromString("100")},
+		{ID: 2, Type: LedgerEntryCharge, Date: day(2), Amount: decimal.RequireFromString("50")},
+		{ID: 3, Type: LedgerEntryPayment, Date: day(3), Amount: decimal.RequireFromString("120")},
+		{ID: 4, Type: LedgerEntryCharge, Date: day(4), Amount: decimal.RequireFromString("30")},
+		{ID: 5, Type: LedgerEntryCredit, Date: day(5), Amount: decimal.RequireFromString("10")},
+	}
+
+	outstanding := OutstandingCharges(entries, day(4))
+	if len(outstanding) != 2 || outstanding[0].Charge.ID != 2 || outstanding[0].Unpaid.String() != "30" ||
+		outstanding[1].Charge.ID != 4 || outstanding[1].Unpaid.String() != "30" {
+		t.Errorf("OutstandingCharges on day 4 = %+v, want charge 2 with 30 and charge 4 with 30 unpaid", outstanding)
+	}
+
+	outstanding = OutstandingCharges(entries, day(5))
+	if len(outstanding) != 2 || outstanding[0].Unpaid.String() != "20" {
+		t.Errorf("OutstandingCharges on day 5 = %+v, want charge 2 with 20 unpaid", outstanding)
+	}
+}
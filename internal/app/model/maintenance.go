@@ -0,0 +1,105 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Maintenance request statuses. A request is open until someone is assigned
+// to it, in progress while the work is done, possibly waiting on parts, and
+// resolved once fixed. It is closed when the resolution is confirmed, or
+// straight away if no work is needed.
+const (
+	MaintenanceStatusOpen           = "open"
+	MaintenanceStatusAssigned       = "assigned"
+	MaintenanceStatusInProgress     = "in_progress"
+	MaintenanceStatusWaitingOnParts = "waiting_on_parts"
+	MaintenanceStatusResolved       = "resolved"
+	MaintenanceStatusClosed         = "closed"
+)
+
+// Maintenance request priorities, most urgent first.
+const (
+	MaintenancePriorityEmergency = "emergency"
+	MaintenancePriorityHigh      = "high"
+	MaintenancePriorityNormal    = "normal"
+	MaintenancePriorityLow       = "low"
+)
+
+// maintenanceTransitions lists the statuses each maintenance request status
+// may change to. Resolved requests reopen by going back in progress.
+var maintenanceTransitions = map[string][]string{
+	MaintenanceStatusOpen:           {MaintenanceStatusAssigned, MaintenanceStatusClosed},
+	MaintenanceStatusAssigned:       {MaintenanceStatusOpen, MaintenanceStatusInProgress, MaintenanceStatusClosed},
+	MaintenanceStatusInProgress:     {MaintenanceStatusWaitingOnParts, MaintenanceStatusResolved},
+	MaintenanceStatusWaitingOnParts: {MaintenanceStatusInProgress, MaintenanceStatusResolved},
+	MaintenanceStatusResolved:       {MaintenanceStatusInProgress, MaintenanceStatusClosed},
+}
+
+// MaintenanceRequest is a ticket for a repair at a property, or one of its
+// units, such as a broken heater.
+type MaintenanceRequest struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	PropertyID uint           `gorm:"not null;index" json:"property_id"`
+	UnitID     *uint          `gorm:"index" json:"unit_id"`
+	// TenantID is the tenant who reported the problem, if one did.
+	TenantID    *uint  `gorm:"index" json:"tenant_id"`
+	Category    string `gorm:"not null;index" json:"category"`
+	Priority    string `gorm:"not null;index" json:"priority"`
+	Description string `gorm:"not null" json:"description"`
+	Status      string `gorm:"not null;default:open;index" json:"status"`
+	Assignee    string `gorm:"index" json:"assignee"`
+	// DueAt is when the request should be resolved by under the service
+	// level for its priority.
+	DueAt      time.Time            `gorm:"not null;index" json:"due_at"`
+	ResolvedAt *time.Time           `json:"resolved_at"`
+	ClosedAt   *time.Time           `json:"closed_at"`
+	Overdue    bool                 `gorm:"-" json:"overdue"`
+	Photos     []MaintenancePhoto   `gorm:"foreignKey:RequestID" json:"photos"`
+	Comments   []MaintenanceComment `gorm:"foreignKey:RequestID" json:"comments,omitempty"`
+}
+
+// CanTransition reports whether the request may change to status.
+func (r MaintenanceRequest) CanTransition(status string) bool {
+	for _, next := range maintenanceTransitions[r.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOverdue reports whether the request is still unresolved after its due
+// date at now.
+func (r MaintenanceRequest) IsOverdue(now time.Time) bool {
+	return r.Status != MaintenanceStatusResolved && r.Status != MaintenanceStatusClosed && now.After(r.DueAt)
+}
+
+// MaintenancePhoto is a photo of the problem or the repair. Photos are
+// taken inside tenants' homes, so they are stored privately and viewed
+// through short-lived links.
+type MaintenancePhoto struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	RequestID   uint           `gorm:"not null;index" json:"request_id"`
+	Name        string         `json:"name"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	UploadedBy  string         `json:"uploaded_by"`
+	Key         string         `json:"-"`
+}
+
+// MaintenanceComment is an entry in the discussion of a request. Status
+// changes and assignments are recorded as comments too.
+type MaintenanceComment struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	RequestID uint      `gorm:"not null;index" json:"request_id"`
+	Author    string    `gorm:"not null" json:"author"`
+	Body      string    `gorm:"not null" json:"body"`
+}
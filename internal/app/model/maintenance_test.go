@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceRequestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{MaintenanceStatusOpen, MaintenanceStatusAssigned, true},
+		{MaintenanceStatusOpen, MaintenanceStatusClosed, true},
+		{MaintenanceStatusOpen, MaintenanceStatusInProgress, false},
+		{MaintenanceStatusOpen, MaintenanceStatusResolved, false},
+		{MaintenanceStatusAssigned, MaintenanceStatusOpen, true},
+		{MaintenanceStatusAssigned, MaintenanceStatusInProgress, true},
+		{MaintenanceStatusAssigned, MaintenanceStatusClosed, true},
+		{MaintenanceStatusAssigned, MaintenanceStatusResolved, false},
+		{MaintenanceStatusInProgress, MaintenanceStatusWaitingOnParts, true},
+		{MaintenanceStatusInProgress, MaintenanceStatusResolved, true},
+		{MaintenanceStatusInProgress, MaintenanceStatusClosed, false},
+		{MaintenanceStatusWaitingOnParts, MaintenanceStatusInProgress, true},
+		{MaintenanceStatusWaitingOnParts, MaintenanceStatusResolved, true},
+		{MaintenanceStatusResolved, MaintenanceStatusInProgress, true},
+		{MaintenanceStatusResolved, MaintenanceStatusClosed, true},
+		{MaintenanceStatusResolved, MaintenanceStatusOpen, false},
+		{MaintenanceStatusClosed, MaintenanceStatusOpen, false},
+		{MaintenanceStatusClosed, MaintenanceStatusInProgress, false},
+	}
+	for _, tt := range tests {
+		if got := (MaintenanceRequest{Status: tt.from}).CanTransition(tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s -> %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceRequestIsOverdue(t *testing.T) {
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		status string
+		now    time.Time
+		want   bool
+	}{
+		{MaintenanceStatusOpen, due, false},
+		{MaintenanceStatusOpen, due.Add(time.Second), true},
+		{MaintenanceStatusWaitingOnParts, due.Add(time.Hour), true},
+		{MaintenanceStatusResolved, due.Add(time.Hour), false},
+		{MaintenanceStatusClosed, due.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		request := MaintenanceRequest{Status: tt.status, DueAt: due}
+		if got := request.IsOverdue(tt.now); got != tt.want {
+			t.Errorf("IsOverdue(%s at %v) = %v, want %v", tt.status, tt.now, got, tt.want)
+		}
+	}
+}
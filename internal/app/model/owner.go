@@ -0,0 +1,140 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Owner is a landlord on whose behalf properties are managed.
+type Owner struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Name           string         `gorm:"not null;index" json:"name"`
+	Email          string         `gorm:"index" json:"email"`
+	Phone          string         `json:"phone"`
+	MailingAddress string         `json:"mailing_address"`
+	Notes          string         `json:"notes"`
+	Ownerships     []Ownership    `gorm:"foreignKey:OwnerID" json:"ownerships"`
+}
+
+// Ownership is an owner's share of a property. The shares of a property
+// add up to at most 100 percent.
+type Ownership struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	OwnerID    uint            `gorm:"not null;uniqueIndex:idx_ownership" json:"owner_id"`
+	PropertyID uint            `gorm:"not null;uniqueIndex:idx_ownership;index" json:"property_id"`
+	Percent    decimal.Decimal `gorm:"type:decimal(5,2)" json:"percent" swaggertype:"string" example:"50.00"`
+	Owner      *Owner          `json:"owner,omitempty"`
+}
+
+// ManagementAgreement sets the fee charged for managing a property over a
+// period: FeePercent of the rent collected plus FlatFee, each month. Dates
+// are calendar days at midnight UTC; an agreement without an end date runs
+// until ended.
+type ManagementAgreement struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	PropertyID uint            `gorm:"not null;index" json:"property_id"`
+	StartDate  time.Time       `gorm:"not null" json:"start_date"`
+	EndDate    *time.Time      `json:"end_date"`
+	FeePercent decimal.Decimal `gorm:"type:decimal(5,2)" json:"fee_percent" swaggertype:"string" example:"8.00"`
+	FlatFee    decimal.Decimal `gorm:"type:decimal(14,2)" json:"flat_fee" swaggertype:"string" example:"0.00"`
+	Notes      string          `json:"notes"`
+}
+
+// Overlaps reports whether the agreement is in force on any day from from
+// to to inclusive.
+func (a ManagementAgreement) Overlaps(from, to time.Time) bool {
+	return !a.StartDate.After(to) && (a.EndDate == nil || !a.EndDate.Before(from))
+}
+
+// Fee returns the management fee for a month in which collected was
+// collected.
+func (a ManagementAgreement) Fee(collected decimal.Decimal) decimal.Decimal {
+	return percentOf(collected, a.FeePercent).Add(a.FlatFee)
+}
+
+// OwnerStatement accounts to an owner for a month: for each property they
+// own a share of, the rent collected, the expenses paid and the management
+// fee, and the owner's share of what is left.
+type OwnerStatement struct {
+	OwnerID    uint                     `json:"owner_id"`
+	OwnerName  string                   `json:"owner_name"`
+	Month      string                   `json:"month" example:"2025-03"`
+	From       time.Time                `json:"from"`
+	To         time.Time                `json:"to"`
+	Properties []OwnerPropertyStatement `json:"properties"`
+	// The totals are of the owner's shares.
+	RentCollected decimal.Decimal `json:"rent_collected" swaggertype:"string" example:"1900.00"`
+	Expenses      decimal.Decimal `json:"expenses" swaggertype:"string" example:"360.00"`
+	ManagementFee decimal.Decimal `json:"management_fee" swaggertype:"string" example:"152.00"`
+	Net           decimal.Decimal `json:"net" swaggertype:"string" example:"1388.00"`
+}
+
+// OwnerPropertyStatement is one property of an owner statement. The
+// amounts are the property's; the owner's share of each is Percent of it.
+type OwnerPropertyStatement struct {
+	PropertyID    uint            `json:"property_id"`
+	PropertyName  string          `json:"property_name"`
+	Percent       decimal.Decimal `json:"percent" swaggertype:"string" example:"50.00"`
+	RentCollected decimal.Decimal `json:"rent_collected" swaggertype:"string" example:"3800.00"`
+	Expenses      []Expense       `json:"expenses"`
+	TotalExpenses decimal.Decimal `json:"total_expenses" swaggertype:"string" example:"720.00"`
+	// ManagementFee is charged under the agreement in force during the
+	// month, if any.
+	ManagementFee decimal.Decimal `json:"management_fee" swaggertype:"string" example:"304.00"`
+	AgreementID   *uint           `json:"agreement_id"`
+	Net           decimal.Decimal `json:"net" swaggertype:"string" example:"2776.00"`
+	OwnerShare    decimal.Decimal `json:"owner_share" swaggertype:"string" example:"1388.00"`
+}
+
+// NewOwnerPropertyStatement accounts for a property over a month from the
+// payments received from its leases, less the refunds charged back to
+// them, its expenses and its management agreement, which may be nil, for
+// an owner of percent of it.
+func NewOwnerPropertyStatement(property Property, percent decimal.Decimal, payments []LedgerEntry, expenses []Expense, agreement *ManagementAgreement) OwnerPropertyStatement {
+	statement := OwnerPropertyStatement{
+		PropertyID:   property.ID,
+		PropertyName: property.Name,
+		Percent:      percent,
+		Expenses:     expenses,
+	}
+	// Refunds are charges, so they take back what was collected.
+	for _, payment := range payments {
+		statement.RentCollected = statement.RentCollected.Sub(payment.SignedAmount())
+	}
+	for _, expense := range expenses {
+		statement.TotalExpenses = statement.TotalExpenses.Add(expense.Amount)
+	}
+	if agreement != nil {
+		statement.ManagementFee = agreement.Fee(statement.RentCollected)
+		statement.AgreementID = &agreement.ID
+	}
+	statement.Net = statement.RentCollected.Sub(statement.TotalExpenses).Sub(statement.ManagementFee)
+	// The owner's share is worked out from the shares of each amount, so
+	// that the shares on a statement add up to the cent.
+	statement.OwnerShare = percentOf(statement.RentCollected, percent).
+		Sub(percentOf(statement.TotalExpenses, percent)).
+		Sub(percentOf(statement.ManagementFee, percent))
+	return statement
+}
+
+// Add adds a property to the statement and its owner's share of the
+// amounts to the totals.
+func (s *OwnerStatement) Add(property OwnerPropertyStatement) {
+	s.Properties = append(s.Properties, property)
+	s.RentCollected = s.RentCollected.Add(percentOf(property.RentCollected, property.Percent))
+	s.Expenses = s.Expenses.Add(percentOf(property.TotalExpenses, property.Percent))
+	s.ManagementFee = s.ManagementFee.Add(percentOf(property.ManagementFee, property.Percent))
+	s.Net = s.Net.Add(property.OwnerShare)
+}
+
+// percentOf returns percent percent of amount, rounded to the cent.
+func percentOf(amount, percent decimal.Decimal) decimal.Decimal {
+	return amount.Mul(percent).Div(decimal.NewFromInt(100)).Round(2)
+}
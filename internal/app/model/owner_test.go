@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestOwnerStatementSharesAddUp(t *testing.T) {
+	payments := []LedgerEntry{
+		{Type: LedgerEntryPayment, Amount: decimal.RequireFromString("1000.00")},
+		{Type: LedgerEntryPayment, Amount: decimal.RequireFromString("333.33")},
+	}
+	expenses := []Expense{{Amount: decimal.RequireFromString("100.01")}}
+	agreement := &ManagementAgreement{ID: 7, FeePercent: decimal.RequireFromString("8"), FlatFee: decimal.RequireFromString("25")}
+
+	property := NewOwnerPropertyStatement(Property{ID: 1, Name: "Elm"}, decimal.RequireFromString("33.33"), payments, expenses, agreement)
+
+	if got := property.RentCollected.String(); got != "1333.33" {
+		t.Errorf("RentCollected = %s, want 1333.33", got)
+	}
+	// 8% of 1333.33 is 106.67 after rounding, plus the flat 25.
+	if got := property.ManagementFee.String(); got != "131.67" {
+		t.Errorf("ManagementFee = %s, want 131.67", got)
+	}
+	if got := property.Net.String(); got != "1101.65" {
+		t.Errorf("Net = %s, want 1101.65", got)
+	}
+
+	var statement OwnerStatement
+	statement.Add(property)
+	sum := statement.RentCollected.Sub(statement.Expenses).Sub(statement.ManagementFee)
+	if !sum.Equal(statement.Net) {
+		t.Errorf("shares %s - %s - %s = %s, want Net %s", statement.RentCollected, statement.Expenses, statement.ManagementFee, sum, statement.Net)
+	}
+	if got := statement.Net.String(); got != "367.18" {
+		t.Errorf("Net = %s, want 367.18", got)
+	}
+}
+
+func TestOwnerStatementNetsRefunds(t *testing.T) {
+	payments := []LedgerEntry{
+		{Type: LedgerEntryPayment, Amount: decimal.RequireFromString("950.00")},
+		{Type: LedgerEntryCharge, Category: ChargeCategoryRefund, Amount: decimal.RequireFromString("200.00")},
+	}
+	agreement := &ManagementAgreement{ID: 7, FeePercent: decimal.RequireFromString("10")}
+
+	property := NewOwnerPropertyStatement(Property{ID: 1, Name: "Elm"}, decimal.RequireFromString("100"), payments, nil, agreement)
+
+	if got := property.RentCollected.String(); got != "750" {
+		t.Errorf("RentCollected = %s, want 750", got)
+	}
+	// The fee is charged on what was kept, not what was first received.
+	if got := property.ManagementFee.String(); got != "75" {
+		t.Errorf("ManagementFee = %s, want 75", got)
+	}
+}
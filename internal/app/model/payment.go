@@ -0,0 +1,82 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Payment intent statuses.
+const (
+	PaymentStatusPending           = "pending"
+	PaymentStatusSucceeded         = "succeeded"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+)
+
+// Payout reconciliation statuses. A payout is reconciled when it matches
+// the payments it settles that propmanager recorded.
+const (
+	PayoutStatusReconciled  = "reconciled"
+	PayoutStatusDiscrepancy = "discrepancy"
+)
+
+// PaymentIntent is a payment a tenant makes online through the payment
+// provider towards a lease, optionally against one of its charges. It is
+// posted to the lease's ledger when the provider reports that it succeeded.
+type PaymentIntent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	LeaseID   uint      `gorm:"not null;index" json:"lease_id"`
+	ChargeID  *uint     `json:"charge_id"`
+	Provider  string    `gorm:"not null" json:"provider"`
+	// ProviderID is the provider's payment intent ID.
+	ProviderID     string          `gorm:"not null;uniqueIndex" json:"provider_id"`
+	Amount         decimal.Decimal `gorm:"type:decimal(14,2);not null" json:"amount" swaggertype:"string" example:"950.00"`
+	Currency       string          `json:"currency"`
+	Status         string          `gorm:"not null;index" json:"status"`
+	FailureReason  string          `json:"failure_reason,omitempty"`
+	RefundedAmount decimal.Decimal `gorm:"type:decimal(14,2)" json:"refunded_amount" swaggertype:"string" example:"0.00"`
+	// LedgerEntryID is the payment posted to the ledger.
+	LedgerEntryID *uint `json:"ledger_entry_id"`
+	PayoutID      *uint `gorm:"index" json:"payout_id"`
+	// ClientSecret lets the payer complete the payment. It is only
+	// returned when the payment is created.
+	ClientSecret string `gorm:"-" json:"client_secret,omitempty"`
+}
+
+// Payout is a transfer from the payment provider to the bank account and
+// how it reconciles with the payments recorded in propmanager.
+type Payout struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Provider    string          `gorm:"not null" json:"provider"`
+	ProviderID  string          `gorm:"not null;uniqueIndex" json:"provider_id"`
+	Amount      decimal.Decimal `gorm:"type:decimal(14,2);not null" json:"amount" swaggertype:"string" example:"1900.00"`
+	Currency    string          `json:"currency"`
+	ArrivalDate time.Time       `json:"arrival_date"`
+	Status      string          `gorm:"not null;index" json:"status"`
+	// MatchedAmount is the net amount of the settled payments found in
+	// propmanager, and Fees the provider's fees on them.
+	MatchedAmount decimal.Decimal `gorm:"type:decimal(14,2)" json:"matched_amount" swaggertype:"string" example:"1900.00"`
+	Fees          decimal.Decimal `gorm:"type:decimal(14,2)" json:"fees" swaggertype:"string" example:"0.00"`
+	// Discrepancy is Amount less MatchedAmount.
+	Discrepancy decimal.Decimal `gorm:"type:decimal(14,2)" json:"discrepancy" swaggertype:"string" example:"0.00"`
+	// UnmatchedPayments are the provider's IDs of settled payments that
+	// propmanager has not recorded as succeeded.
+	UnmatchedPayments []string        `gorm:"serializer:json" json:"unmatched_payments"`
+	Payments          []PaymentIntent `gorm:"foreignKey:PayoutID" json:"payments,omitempty"`
+}
+
+// WebhookEvent records a payment provider event that has been handled, so
+// that redelivered events are ignored.
+type WebhookEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_webhook_events_provider_event" json:"provider"`
+	EventID   string    `gorm:"not null;uniqueIndex:idx_webhook_events_provider_event" json:"event_id"`
+	Type      string    `json:"type"`
+}
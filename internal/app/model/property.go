@@ -6,6 +6,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// Property statuses.
+const (
+	PropertyStatusAvailable = "available"
+	PropertyStatusRented    = "rented"
+	PropertyStatusSold      = "sold"
+	PropertyStatusOffMarket = "off_market"
+)
+
 type Property struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -15,7 +23,12 @@ type Property struct {
 	Description string         `json:"description"`
 	Price       float64        `json:"price"`
 	Location    string         `json:"location"`
+	Status      string         `gorm:"not null;default:available" json:"status"`
+	Version     uint           `gorm:"not null;default:1" json:"version"`
 	Images      []Image        `gorm:"foreignKey:PropertyID" json:"images"`
+	// Occupancy aggregates the property's units. It is computed on read
+	// and not stored.
+	Occupancy *Occupancy `gorm:"-" json:"occupancy,omitempty"`
 }
 
 type Image struct {
@@ -25,4 +38,5 @@ type Image struct {
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 	PropertyID uint           `json:"property_id"`
 	URL        string         `json:"url"`
+	Size       int64          `json:"size"`
 }
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Actions recorded in the property history.
+const (
+	ActionCreate       = "create"
+	ActionUpdate       = "update"
+	ActionDelete       = "delete"
+	ActionImageAdded   = "image_added"
+	ActionImageDeleted = "image_deleted"
+	ActionRestore      = "restore"
+)
+
+// PropertyVersion is an immutable snapshot of a property taken after every change.
+type PropertyVersion struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PropertyID uint            `gorm:"uniqueIndex:idx_property_version" json:"property_id"`
+	Version    uint            `gorm:"uniqueIndex:idx_property_version" json:"version"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	Changes    FieldChanges    `gorm:"type:text" json:"changes"`
+	Snapshot   json.RawMessage `gorm:"type:text" json:"snapshot" swaggertype:"object"`
+}
+
+// FieldChange describes the old and new value of a single field.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// FieldChanges is stored as a JSON document.
+type FieldChanges []FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *FieldChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	default:
+		return errors.New("unsupported type for FieldChanges")
+	}
+}
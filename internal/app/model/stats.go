@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// PortfolioStats summarises the properties created within a date range.
+type PortfolioStats struct {
+	From            *time.Time       `json:"from"`
+	To              *time.Time       `json:"to"`
+	GeneratedAt     time.Time        `json:"generated_at"`
+	Properties      PropertyCounts   `json:"properties"`
+	Price           PriceStats       `json:"price"`
+	ListingsPerWeek []WeeklyListings `json:"listings_per_week"`
+	Storage         StorageStats     `json:"storage"`
+}
+
+type PropertyCounts struct {
+	Total         int64            `json:"total"`
+	ByStatus      map[string]int64 `json:"by_status"`
+	ByLocation    map[string]int64 `json:"by_location"`
+	WithoutImages int64            `json:"without_images"`
+}
+
+type PriceStats struct {
+	Average float64 `json:"average"`
+	Median  float64 `json:"median"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+}
+
+// WeeklyListings counts the properties created in the week starting on WeekStart (a Monday, UTC).
+type WeeklyListings struct {
+	WeekStart time.Time `json:"week_start"`
+	Count     int64     `json:"count"`
+}
+
+type StorageStats struct {
+	Images int64 `json:"images"`
+	Bytes  int64 `json:"bytes"`
+}
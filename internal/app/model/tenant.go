@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tenant is a person who rents, or has rented, a property or unit.
+type Tenant struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	FirstName string         `gorm:"not null" json:"first_name"`
+	LastName  string         `gorm:"not null;index" json:"last_name"`
+	Email     string         `gorm:"index" json:"email"`
+	Phone     string         `json:"phone"`
+	// MailingAddress is where correspondence goes when it differs from the
+	// rented property.
+	MailingAddress string `json:"mailing_address"`
+	// IdentificationNotes records how the tenant's identity was verified,
+	// such as the type of document seen, not the document number itself.
+	IdentificationNotes string             `json:"identification_notes"`
+	PropertyID          *uint              `gorm:"index" json:"property_id"`
+	UnitID              *uint              `gorm:"index" json:"unit_id"`
+	EmergencyContacts   []EmergencyContact `gorm:"foreignKey:TenantID" json:"emergency_contacts"`
+	Documents           []TenantDocument   `gorm:"foreignKey:TenantID" json:"documents"`
+}
+
+// EmergencyContact is someone to call on a tenant's behalf.
+type EmergencyContact struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	TenantID     uint   `gorm:"not null;index" json:"tenant_id"`
+	Name         string `gorm:"not null" json:"name"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+}
+
+// TenantDocument is a file kept on record for a tenant, such as a signed
+// application. Documents are stored privately and downloaded through
+// short-lived links.
+type TenantDocument struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	TenantID    uint           `gorm:"not null;index" json:"tenant_id"`
+	Name        string         `json:"name"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	Key         string         `json:"-"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Unit statuses. Reserved units are held for an incoming tenant and
+// unavailable units cannot be let, for example during renovation.
+const (
+	UnitStatusVacant      = "vacant"
+	UnitStatusOccupied    = "occupied"
+	UnitStatusReserved    = "reserved"
+	UnitStatusUnavailable = "unavailable"
+)
+
+// Unit is a lettable apartment or suite within a multi-unit property.
+type Unit struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `gorm:"index" json:"deleted_at"`
+	PropertyID uint            `gorm:"not null;index" json:"property_id"`
+	Number     string          `gorm:"not null" json:"number"`
+	Floor      int             `json:"floor"`
+	Bedrooms   int             `json:"bedrooms"`
+	Bathrooms  float64         `json:"bathrooms"`
+	SquareFeet int             `json:"square_feet"`
+	Rent       decimal.Decimal `gorm:"type:decimal(14,2);not null" json:"rent" swaggertype:"string" example:"950.00"`
+	Status     string          `gorm:"not null;default:vacant" json:"status"`
+	Images     []UnitImage     `gorm:"foreignKey:UnitID" json:"images"`
+}
+
+type UnitImage struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	UnitID    uint           `gorm:"not null;index" json:"unit_id"`
+	URL       string         `json:"url"`
+	Size      int64          `json:"size"`
+}
+
+// Occupancy summarises the units of a property by status.
+type Occupancy struct {
+	TotalUnits       int64   `json:"total_units"`
+	OccupiedUnits    int64   `json:"occupied_units"`
+	VacantUnits      int64   `json:"vacant_units"`
+	ReservedUnits    int64   `json:"reserved_units"`
+	UnavailableUnits int64   `json:"unavailable_units"`
+	OccupancyRate    float64 `json:"occupancy_rate" example:"0.75"`
+	VacancyRate      float64 `json:"vacancy_rate" example:"0.25"`
+}
+
+// NewOccupancy returns the occupancy for the given unit counts by status.
+func NewOccupancy(countsByStatus map[string]int64) Occupancy {
+	occupancy := Occupancy{
+		OccupiedUnits:    countsByStatus[UnitStatusOccupied],
+		VacantUnits:      countsByStatus[UnitStatusVacant],
+		ReservedUnits:    countsByStatus[UnitStatusReserved],
+		UnavailableUnits: countsByStatus[UnitStatusUnavailable],
+	}
+	for _, count := range countsByStatus {
+		occupancy.TotalUnits += count
+	}
+	if occupancy.TotalUnits > 0 {
+		occupancy.OccupancyRate = float64(occupancy.OccupiedUnits) / float64(occupancy.TotalUnits)
+		occupancy.VacancyRate = float64(occupancy.VacantUnits) / float64(occupancy.TotalUnits)
+	}
+	return occupancy
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Vendor is a contractor, such as a plumber or electrician, that work
+// orders are issued to.
+type Vendor struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	Name        string         `gorm:"not null;index" json:"name"`
+	Trade       string         `gorm:"not null;index" json:"trade"`
+	ContactName string         `json:"contact_name"`
+	Phone       string         `json:"phone"`
+	Email       string         `json:"email"`
+	// ServiceAreas are the places the vendor works in, such as towns or
+	// postcodes.
+	ServiceAreas []string        `gorm:"serializer:json" json:"service_areas"`
+	HourlyRate   decimal.Decimal `gorm:"type:decimal(14,2)" json:"hourly_rate" swaggertype:"string" example:"85.00"`
+	CalloutFee   decimal.Decimal `gorm:"type:decimal(14,2)" json:"callout_fee" swaggertype:"string" example:"60.00"`
+	// InsuranceExpiresOn is the last day the vendor's liability insurance
+	// covers, if known.
+	InsuranceExpiresOn *time.Time `json:"insurance_expires_on"`
+	InsuranceExpired   bool       `gorm:"-" json:"insurance_expired"`
+	Notes              string     `json:"notes"`
+}
+
+// InsuranceLapsed reports whether the vendor's insurance is known to have
+// expired before day.
+func (v Vendor) InsuranceLapsed(day time.Time) bool {
+	return v.InsuranceExpiresOn != nil && v.InsuranceExpiresOn.Before(day)
+}
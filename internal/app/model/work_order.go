@@ -0,0 +1,112 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Work order statuses. A work order estimated above the approval threshold
+// is pending approval when issued and approved or rejected; one below it is
+// approved straight away. The vendor's invoice is recorded against an
+// approved order, and the order is completed when the work is signed off.
+// An invoice above both the estimate and the threshold sends the order back
+// to pending approval, and approving it then makes it invoiced; rejecting
+// it is the only way to refuse the invoice. Orders not yet invoiced may be
+// cancelled.
+const (
+	WorkOrderStatusPendingApproval = "pending_approval"
+	WorkOrderStatusApproved        = "approved"
+	WorkOrderStatusRejected        = "rejected"
+	WorkOrderStatusInvoiced        = "invoiced"
+	WorkOrderStatusCompleted       = "completed"
+	WorkOrderStatusCancelled       = "cancelled"
+)
+
+// workOrderTransitions lists the statuses each work order status may
+// change to.
+var workOrderTransitions = map[string][]string{
+	WorkOrderStatusPendingApproval: {WorkOrderStatusApproved, WorkOrderStatusRejected, WorkOrderStatusCancelled},
+	WorkOrderStatusApproved:        {WorkOrderStatusInvoiced, WorkOrderStatusCancelled},
+	WorkOrderStatusInvoiced:        {WorkOrderStatusCompleted},
+}
+
+// WorkOrder instructs a vendor to carry out the work for a maintenance
+// request at an estimated cost.
+type WorkOrder struct {
+	ID                   uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+	MaintenanceRequestID uint            `gorm:"not null;index" json:"maintenance_request_id"`
+	PropertyID           uint            `gorm:"not null;index" json:"property_id"`
+	UnitID               *uint           `gorm:"index" json:"unit_id"`
+	VendorID             uint            `gorm:"not null;index" json:"vendor_id"`
+	Vendor               *Vendor         `json:"vendor,omitempty"`
+	Description          string          `gorm:"not null" json:"description"`
+	Estimate             decimal.Decimal `gorm:"type:decimal(14,2)" json:"estimate" swaggertype:"string" example:"750.00"`
+	Status               string          `gorm:"not null;index" json:"status"`
+	IssuedBy             string          `json:"issued_by"`
+	ApprovedBy           string          `json:"approved_by"`
+	ApprovedAt           *time.Time      `json:"approved_at"`
+	// StatusReason is why the order was rejected or cancelled.
+	StatusReason  string           `json:"status_reason"`
+	InvoiceNumber string           `json:"invoice_number"`
+	InvoiceDate   *time.Time       `json:"invoice_date"`
+	InvoiceAmount *decimal.Decimal `gorm:"type:decimal(14,2)" json:"invoice_amount" swaggertype:"string" example:"720.00"`
+	CompletedBy   string           `json:"completed_by"`
+	CompletedAt   *time.Time       `json:"completed_at"`
+	SignOffNotes  string           `json:"sign_off_notes"`
+}
+
+// CanTransition reports whether the work order may change to status.
+func (o WorkOrder) CanTransition(status string) bool {
+	for _, next := range workOrderTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Expense is money spent on a property, such as a vendor's invoice for a
+// completed work order. Dates are calendar days at midnight UTC.
+type Expense struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	PropertyID  uint            `gorm:"not null;index" json:"property_id"`
+	UnitID      *uint           `gorm:"index" json:"unit_id"`
+	Date        time.Time       `gorm:"not null;index" json:"date"`
+	Category    string          `gorm:"not null;index" json:"category"`
+	Description string          `json:"description"`
+	Amount      decimal.Decimal `gorm:"type:decimal(14,2)" json:"amount" swaggertype:"string" example:"720.00"`
+	VendorID    *uint           `gorm:"index" json:"vendor_id"`
+	WorkOrderID *uint           `gorm:"uniqueIndex" json:"work_order_id"`
+	CreatedBy   string          `json:"created_by"`
+}
+
+// ExpenseHistory lists the expenses of a property over a period, oldest
+// first, with their total and the total of each category.
+type ExpenseHistory struct {
+	PropertyID uint                       `json:"property_id"`
+	From       time.Time                  `json:"from"`
+	To         time.Time                  `json:"to"`
+	Expenses   []Expense                  `json:"expenses"`
+	ByCategory map[string]decimal.Decimal `json:"by_category" swaggertype:"object,string"`
+	Total      decimal.Decimal            `json:"total" swaggertype:"string" example:"1820.00"`
+}
+
+// NewExpenseHistory totals the expenses of a property over a period.
+func NewExpenseHistory(propertyID uint, from, to time.Time, expenses []Expense) ExpenseHistory {
+	history := ExpenseHistory{
+		PropertyID: propertyID,
+		From:       from,
+		To:         to,
+		Expenses:   expenses,
+		ByCategory: map[string]decimal.Decimal{},
+	}
+	for _, expense := range expenses {
+		history.ByCategory[expense.Category] = history.ByCategory[expense.Category].Add(expense.Amount)
+		history.Total = history.Total.Add(expense.Amount)
+	}
+	return history
+}
@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+func TestWorkOrderCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{WorkOrderStatusPendingApproval, WorkOrderStatusApproved, true},
+		{WorkOrderStatusPendingApproval, WorkOrderStatusRejected, true},
+		{WorkOrderStatusPendingApproval, WorkOrderStatusCancelled, true},
+		{WorkOrderStatusPendingApproval, WorkOrderStatusInvoiced, false},
+		{WorkOrderStatusApproved, WorkOrderStatusInvoiced, true},
+		{WorkOrderStatusApproved, WorkOrderStatusCancelled, true},
+		{WorkOrderStatusApproved, WorkOrderStatusCompleted, false},
+		{WorkOrderStatusApproved, WorkOrderStatusRejected, false},
+		{WorkOrderStatusInvoiced, WorkOrderStatusCompleted, true},
+		{WorkOrderStatusInvoiced, WorkOrderStatusCancelled, false},
+		{WorkOrderStatusRejected, WorkOrderStatusApproved, false},
+		{WorkOrderStatusCompleted, WorkOrderStatusCancelled, false},
+		{WorkOrderStatusCancelled, WorkOrderStatusApproved, false},
+		{"unknown", WorkOrderStatusApproved, false},
+	}
+	for _, tt := range tests {
+		if got := (WorkOrder{Status: tt.from}).CanTransition(tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s -> %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"propmanager/internal/app/model"
+)
+
+// Inspection writes the report of an inspection at place as PDF to w, with
+// the photos in photos, keyed by photo ID, embedded below the checklist.
+// Photos missing from photos are listed by name.
+func Inspection(w io.Writer, inspection model.Inspection, place string, photos map[uint][]byte) error {
+	d := newDocument(fmt.Sprintf("%s inspection %d - %s", inspectionType(inspection.Type), inspection.ID, place))
+	d.heading(inspectionType(inspection.Type) + " inspection")
+	d.field("Property", place)
+	d.field("Scheduled for", inspection.ScheduledFor.Format("2006-01-02 15:04 MST"))
+	d.field("Inspector", inspection.Inspector)
+	d.field("Status", inspection.Status)
+	if inspection.CompletedAt != nil {
+		d.field("Completed", inspection.CompletedAt.Format(time.DateOnly)+" by "+inspection.CompletedBy)
+	}
+	d.field("Rating scale", strings.Join(inspection.Ratings, ", ")+" (best first)")
+	if inspection.Notes != "" {
+		d.section("Notes")
+		d.text(inspection.Notes)
+	}
+
+	for _, room := range inspection.Rooms {
+		d.section(room.Name)
+		rows := make([][]string, 0, len(room.Items))
+		for _, item := range room.Items {
+			result, _ := inspection.Result(room.Name, item)
+			rows = append(rows, []string{item, result.Rating, result.Notes})
+		}
+		d.table([]column{
+			{"Item", 0.3, "L"},
+			{"Rating", 0.15, "L"},
+			{"Notes", 0.55, "L"},
+		}, rows)
+	}
+
+	if len(inspection.Photos) > 0 {
+		d.section("Photos")
+		for _, photo := range inspection.Photos {
+			caption := photoCaption(photo)
+			data, ok := photos[photo.ID]
+			if !ok {
+				d.text(caption)
+				continue
+			}
+			d.image("photo-"+strconv.FormatUint(uint64(photo.ID), 10), data, caption, 120, 90)
+		}
+	}
+	return d.write(w)
+}
+
+// InspectionComparison writes a comparison of two inspections at place as
+// PDF to w, with the condition of each item side by side.
+func InspectionComparison(w io.Writer, comparison model.InspectionComparison, place string) error {
+	baseline, inspection := inspectionType(comparison.Baseline.Type), inspectionType(comparison.Inspection.Type)
+	if baseline == inspection {
+		baseline, inspection = "Before", "After"
+	}
+	d := newDocument(fmt.Sprintf("Inspection comparison %d and %d - %s", comparison.Baseline.ID, comparison.Inspection.ID, place))
+	d.heading("Inspection comparison")
+	d.field("Property", place)
+	d.field(baseline, comparedSummary(comparison.Baseline))
+	d.field(inspection, comparedSummary(comparison.Inspection))
+	d.field("Items worse", strconv.Itoa(comparison.Worsened))
+
+	for _, room := range comparison.Rooms {
+		d.section(room.Name)
+		rows := make([][]string, 0, len(room.Items))
+		for _, item := range room.Items {
+			rows = append(rows, []string{item.Item, item.BaselineRating, item.BaselineNotes, item.Rating, item.Notes, item.Change})
+		}
+		d.table([]column{
+			{"Item", 0.2, "L"},
+			{baseline, 0.12, "L"},
+			{"Notes", 0.22, "L"},
+			{inspection, 0.12, "L"},
+			{"Notes", 0.22, "L"},
+			{"Change", 0.12, "L"},
+		}, rows)
+	}
+	return d.write(w)
+}
+
+func comparedSummary(inspection model.ComparedInspection) string {
+	summary := "Inspection " + strconv.FormatUint(uint64(inspection.ID), 10)
+	if inspection.CompletedAt != nil {
+		summary += ", completed " + inspection.CompletedAt.Format(time.DateOnly) + " by " + inspection.CompletedBy
+	}
+	return summary
+}
+
+func photoCaption(photo model.InspectionPhoto) string {
+	caption := photo.Name
+	switch {
+	case photo.Item != "":
+		caption = photo.Room + ": " + photo.Item + " - " + caption
+	case photo.Room != "":
+		caption = photo.Room + " - " + caption
+	}
+	return caption
+}
+
+func inspectionType(t string) string {
+	switch t {
+	case model.InspectionTypeMoveIn:
+		return "Move-in"
+	case model.InspectionTypeMoveOut:
+		return "Move-out"
+	case model.InspectionTypeRoutine:
+		return "Routine"
+	}
+	return t
+}
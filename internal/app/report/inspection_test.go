@@ -0,0 +1,69 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+
+	"propmanager/internal/app/model"
+)
+
+func testImage(t *testing.T, encode func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 30; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 6), G: uint8(y * 8), B: 120, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestInspectionReportWithPhotos renders an inspection with embedded PNG
+// and JPEG photos, one that is not an image and one whose file is missing.
+func TestInspectionReportWithPhotos(t *testing.T) {
+	completed := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+	inspection := model.Inspection{
+		ID:           7,
+		Type:         model.InspectionTypeMoveIn,
+		Status:       model.InspectionStatusCompleted,
+		ScheduledFor: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		Inspector:    "alice",
+		Ratings:      []string{"good", "fair", "poor"},
+		Rooms:        []model.InspectionRoom{{Name: "Kitchen", Items: []string{"Oven", "Sink"}}},
+		Notes:        "Keys handed over.",
+		CompletedAt:  &completed,
+		CompletedBy:  "alice",
+		Results:      []model.InspectionResult{{Room: "Kitchen", Item: "Oven", Rating: "fair", Notes: "Door scratched"}},
+		Photos: []model.InspectionPhoto{
+			{ID: 1, Room: "Kitchen", Item: "Oven", Name: "oven.png"},
+			{ID: 2, Room: "Kitchen", Item: "Sink", Name: "sink.jpg"},
+			{ID: 3, Room: "Kitchen", Name: "notes.pdf"},
+			{ID: 4, Room: "Kitchen", Name: "missing.jpg"},
+		},
+	}
+	photos := map[uint][]byte{
+		1: testImage(t, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) }),
+		2: testImage(t, func(buf *bytes.Buffer, img image.Image) error { return jpeg.Encode(buf, img, nil) }),
+		3: []byte("%PDF-1.7\n"),
+	}
+
+	var out bytes.Buffer
+	if err := Inspection(&out, inspection, "Elm, unit 1A", photos); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("%PDF")) {
+		t.Fatalf("output starts with %q, want %%PDF", out.Bytes()[:min(out.Len(), 8)])
+	}
+	if n := bytes.Count(out.Bytes(), []byte("/Subtype /Image")); n != 2 {
+		t.Errorf("report embeds %d images, want 2", n)
+	}
+}
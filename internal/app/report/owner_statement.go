@@ -0,0 +1,82 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+// OwnerStatement writes an owner statement as PDF to w.
+func OwnerStatement(w io.Writer, statement model.OwnerStatement) error {
+	d := newDocument(fmt.Sprintf("Owner statement %s - %s", statement.Month, statement.OwnerName))
+	d.heading("Owner statement")
+	d.field("Owner", statement.OwnerName)
+	d.field("Period", statement.From.Format(time.DateOnly)+" to "+statement.To.Format(time.DateOnly))
+
+	d.section("Summary")
+	d.table([]column{
+		{"Property", 0.32, "L"},
+		{"Share", 0.12, "R"},
+		{"Rent collected", 0.14, "R"},
+		{"Expenses", 0.14, "R"},
+		{"Management fee", 0.14, "R"},
+		{"Your share", 0.14, "R"},
+	}, summaryRows(statement))
+
+	for _, property := range statement.Properties {
+		d.section(property.PropertyName)
+		d.field("Ownership", percent(property.Percent))
+		d.field("Rent collected", money(property.RentCollected))
+		d.field("Management fee", money(property.ManagementFee))
+		d.field("Net", money(property.Net))
+		if len(property.Expenses) == 0 {
+			d.text("No expenses this month.")
+			continue
+		}
+		rows := make([][]string, 0, len(property.Expenses))
+		for _, expense := range property.Expenses {
+			rows = append(rows, []string{expense.Date.Format(time.DateOnly), expense.Category, expense.Description, money(expense.Amount)})
+		}
+		d.pdf.Ln(2)
+		d.table([]column{
+			{"Date", 0.15, "L"},
+			{"Category", 0.15, "L"},
+			{"Description", 0.52, "L"},
+			{"Amount", 0.18, "R"},
+		}, rows)
+	}
+	return d.write(w)
+}
+
+func summaryRows(statement model.OwnerStatement) [][]string {
+	rows := make([][]string, 0, len(statement.Properties)+1)
+	for _, property := range statement.Properties {
+		rows = append(rows, []string{
+			property.PropertyName,
+			percent(property.Percent),
+			money(property.RentCollected),
+			money(property.TotalExpenses),
+			money(property.ManagementFee),
+			money(property.OwnerShare),
+		})
+	}
+	return append(rows, []string{
+		"Total (your share)", "",
+		money(statement.RentCollected),
+		money(statement.Expenses),
+		money(statement.ManagementFee),
+		money(statement.Net),
+	})
+}
+
+func money(amount decimal.Decimal) string {
+	return amount.StringFixed(2)
+}
+
+func percent(p decimal.Decimal) string {
+	return p.String() + "%"
+}
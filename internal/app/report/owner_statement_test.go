@@ -0,0 +1,43 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+func TestOwnerStatementIsPDF(t *testing.T) {
+	statement := model.OwnerStatement{
+		OwnerName: "Ada Lovelace",
+		Month:     "2026-03",
+		From:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		Properties: []model.OwnerPropertyStatement{
+			{
+				PropertyName:  "Elm",
+				Percent:       decimal.NewFromInt(50),
+				RentCollected: decimal.NewFromInt(3800),
+				Expenses: []model.Expense{
+					{Date: time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC), Category: "repairs", Description: "Boiler service", Amount: decimal.NewFromInt(720)},
+				},
+				TotalExpenses: decimal.NewFromInt(720),
+				ManagementFee: decimal.NewFromInt(304),
+				Net:           decimal.NewFromInt(2776),
+				OwnerShare:    decimal.NewFromInt(1388),
+			},
+			{PropertyName: "Oak", Percent: decimal.NewFromInt(100)},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := OwnerStatement(&out, statement); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("%PDF")) {
+		t.Errorf("output starts with %q, want %%PDF", out.Bytes()[:min(out.Len(), 8)])
+	}
+}
@@ -0,0 +1,156 @@
+// Package report renders documents such as owner statements and inspection
+// reports as PDF.
+package report
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg" // decoders for the photos embedded by image
+	_ "image/png"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ContentType is the media type of the documents rendered by this package.
+const ContentType = "application/pdf"
+
+const (
+	pageWidth   = 210.0
+	margin      = 15.0
+	lineHeight  = 6.0
+	bodyWidth   = pageWidth - 2*margin
+	footerSpace = 15.0
+)
+
+// document is an A4 page flow with the helpers the reports share. Text is
+// translated to the code page of the core fonts, which covers Western
+// European names.
+type document struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+}
+
+func newDocument(title string) *document {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, footerSpace)
+	pdf.SetTitle(title, true)
+	d := &document{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-footerSpace + 3)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, lineHeight, d.tr(title), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, lineHeight, "Page "+strconv.Itoa(pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AddPage()
+	return d
+}
+
+func (d *document) heading(text string) {
+	d.pdf.SetFont("Helvetica", "B", 16)
+	d.pdf.MultiCell(bodyWidth, 8, d.tr(text), "", "L", false)
+	d.pdf.Ln(2)
+}
+
+func (d *document) section(text string) {
+	d.pdf.Ln(3)
+	d.pdf.SetFont("Helvetica", "B", 12)
+	d.pdf.MultiCell(bodyWidth, 7, d.tr(text), "B", "L", false)
+	d.pdf.Ln(1)
+}
+
+func (d *document) text(text string) {
+	d.pdf.SetFont("Helvetica", "", 10)
+	d.pdf.MultiCell(bodyWidth, 5, d.tr(text), "", "L", false)
+}
+
+// field writes a label and its value on one line.
+func (d *document) field(label, value string) {
+	d.pdf.SetFont("Helvetica", "B", 10)
+	d.pdf.CellFormat(45, lineHeight, d.tr(label), "", 0, "L", false, 0, "")
+	d.pdf.SetFont("Helvetica", "", 10)
+	d.pdf.MultiCell(bodyWidth-45, lineHeight, d.tr(value), "", "L", false)
+}
+
+// column describes a table column by its heading, its share of the body
+// width and its alignment: "L", "C" or "R".
+type column struct {
+	heading string
+	width   float64
+	align   string
+}
+
+// table writes rows under a header row, repeating the header on each new
+// page. Cells that do not fit their column are cut short.
+func (d *document) table(columns []column, rows [][]string) {
+	header := func() {
+		d.pdf.SetFont("Helvetica", "B", 9)
+		d.pdf.SetFillColor(235, 235, 235)
+		for _, col := range columns {
+			d.pdf.CellFormat(col.width*bodyWidth, lineHeight, d.tr(col.heading), "1", 0, col.align, true, 0, "")
+		}
+		d.pdf.Ln(-1)
+		d.pdf.SetFont("Helvetica", "", 9)
+	}
+
+	header()
+	_, pageHeight := d.pdf.GetPageSize()
+	for _, row := range rows {
+		if d.pdf.GetY()+lineHeight > pageHeight-footerSpace {
+			d.pdf.AddPage()
+			header()
+		}
+		for i, col := range columns {
+			width := col.width * bodyWidth
+			d.pdf.CellFormat(width, lineHeight, d.fit(row[i], width), "1", 0, col.align, false, 0, "")
+		}
+		d.pdf.Ln(-1)
+	}
+}
+
+// fit translates s and shortens it to fit in width.
+func (d *document) fit(s string, width float64) string {
+	s = d.tr(s)
+	if d.pdf.GetStringWidth(s) <= width-2 {
+		return s
+	}
+	for len(s) > 0 && d.pdf.GetStringWidth(s+"...") > width-2 {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+// image writes a caption and below it a JPEG or PNG image, scaled to fit
+// within maxWidth by maxHeight, on a new page if there is not room for it
+// on this one. It writes a note instead if data is not such an image.
+func (d *document) image(name string, data []byte, caption string, maxWidth, maxHeight float64) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") || config.Width == 0 || config.Height == 0 {
+		d.text(caption + " (cannot be shown)")
+		return
+	}
+
+	width := maxWidth
+	height := width * float64(config.Height) / float64(config.Width)
+	if height > maxHeight {
+		height = maxHeight
+		width = height * float64(config.Width) / float64(config.Height)
+	}
+	_, pageHeight := d.pdf.GetPageSize()
+	if d.pdf.GetY()+5+height > pageHeight-footerSpace {
+		d.pdf.AddPage()
+	}
+	d.text(caption)
+	options := gofpdf.ImageOptions{ImageType: format}
+	d.pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(data))
+	d.pdf.ImageOptions(name, margin, d.pdf.GetY()+1, width, height, false, options, 0, "")
+	d.pdf.SetY(d.pdf.GetY() + height + 4)
+}
+
+func (d *document) write(w io.Writer) error {
+	return d.pdf.Output(w)
+}
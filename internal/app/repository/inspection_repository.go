@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+// InspectionFilter narrows a list of inspections. Zero fields do not
+// filter.
+type InspectionFilter struct {
+	PropertyID uint
+	UnitID     uint
+	Type       string
+	Status     string
+}
+
+type InspectionRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewInspectionRepository(db *gorm.DB) *InspectionRepository {
+	return &InspectionRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *InspectionRepository) WithContext(ctx context.Context) *InspectionRepository {
+	return NewInspectionRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *InspectionRepository) Transaction(fn func(repo *InspectionRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewInspectionRepository(tx))
+	})
+}
+
+// GetTemplates returns the inspection templates ordered by name.
+func (r *InspectionRepository) GetTemplates() ([]model.InspectionTemplate, error) {
+	var templates []model.InspectionTemplate
+	err := r.db.Order("name, id").Find(&templates).Error
+	return templates, err
+}
+
+func (r *InspectionRepository) GetTemplate(id uint) (model.InspectionTemplate, error) {
+	var template model.InspectionTemplate
+	err := r.db.First(&template, id).Error
+	return template, err
+}
+
+func (r *InspectionRepository) CreateTemplate(template *model.InspectionTemplate) error {
+	return r.db.Create(template).Error
+}
+
+// UpdateTemplate overwrites an inspection template. It reports whether a
+// row was updated.
+func (r *InspectionRepository) UpdateTemplate(template *model.InspectionTemplate) (bool, error) {
+	result := r.db.Model(template).Select("name", "description", "ratings", "rooms").Updates(template)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteTemplate soft-deletes an inspection template. Inspections scheduled
+// from it keep their copy of its checklist. It reports whether a row was
+// deleted.
+func (r *InspectionRepository) DeleteTemplate(id uint) (bool, error) {
+	result := r.db.Delete(&model.InspectionTemplate{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetInspections returns the inspections matching filter, latest scheduled
+// first, without their results and photos.
+func (r *InspectionRepository) GetInspections(filter InspectionFilter) ([]model.Inspection, error) {
+	query := r.db.Model(&model.Inspection{})
+	if filter.PropertyID != 0 {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+	if filter.UnitID != 0 {
+		query = query.Where("unit_id = ?", filter.UnitID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var inspections []model.Inspection
+	err := query.Order("scheduled_for DESC, id DESC").Find(&inspections).Error
+	return inspections, err
+}
+
+// GetInspection returns an inspection with its results and photos.
+func (r *InspectionRepository) GetInspection(id uint) (model.Inspection, error) {
+	var inspection model.Inspection
+	err := r.db.Model(&model.Inspection{}).
+		Preload("Results", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&inspection, id).Error
+	return inspection, err
+}
+
+// FindBaseline returns the latest move-in inspection completed before
+// completedAt at the same place as inspection, preferring one of the same
+// lease. The result's ID is zero if there is none.
+func (r *InspectionRepository) FindBaseline(inspection model.Inspection, completedAt time.Time) (model.Inspection, error) {
+	find := func(lease bool) (model.Inspection, error) {
+		query := r.db.Model(&model.Inspection{}).
+			Where("id <> ? AND property_id = ? AND type = ? AND status = ? AND completed_at < ?",
+				inspection.ID, inspection.PropertyID, model.InspectionTypeMoveIn, model.InspectionStatusCompleted, completedAt)
+		if inspection.UnitID != nil {
+			query = query.Where("unit_id = ?", *inspection.UnitID)
+		} else {
+			query = query.Where("unit_id IS NULL")
+		}
+		if lease {
+			query = query.Where("lease_id = ?", *inspection.LeaseID)
+		}
+		var baseline model.Inspection
+		err := query.Order("completed_at DESC, id DESC").Limit(1).Find(&baseline).Error
+		return baseline, err
+	}
+
+	if inspection.LeaseID != nil {
+		baseline, err := find(true)
+		if err != nil || baseline.ID != 0 {
+			return baseline, err
+		}
+	}
+	return find(false)
+}
+
+func (r *InspectionRepository) CreateInspection(inspection *model.Inspection) error {
+	return r.db.Omit("Results", "Photos").Create(inspection).Error
+}
+
+// UpdateInspectionFields writes the given columns of an inspection.
+func (r *InspectionRepository) UpdateInspectionFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.Inspection{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// CreateResults records the results of an inspection.
+func (r *InspectionRepository) CreateResults(results []model.InspectionResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	return r.db.Create(&results).Error
+}
+
+func (r *InspectionRepository) CreatePhoto(photo *model.InspectionPhoto) error {
+	return r.db.Create(photo).Error
+}
+
+func (r *InspectionRepository) GetPhoto(inspectionID uint, photoID uint) (model.InspectionPhoto, error) {
+	var photo model.InspectionPhoto
+	err := r.db.Where("inspection_id = ?", inspectionID).First(&photo, photoID).Error
+	return photo, err
+}
+
+// DeletePhoto soft-deletes a photo of an inspection. It reports whether a
+// row was deleted.
+func (r *InspectionRepository) DeletePhoto(inspectionID uint, photoID uint) (bool, error) {
+	result := r.db.Where("inspection_id = ? AND id = ?", inspectionID, photoID).Delete(&model.InspectionPhoto{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *InspectionRepository) GetLease(leaseID uint) (model.Lease, error) {
+	var lease model.Lease
+	err := r.db.First(&lease, leaseID).Error
+	return lease, err
+}
+
+// GetPlace returns a property and, if unitID is not nil, one of its units,
+// including deleted ones, which old inspections may refer to.
+func (r *InspectionRepository) GetPlace(propertyID uint, unitID *uint) (model.Property, *model.Unit, error) {
+	var property model.Property
+	if err := r.db.Unscoped().First(&property, propertyID).Error; err != nil {
+		return property, nil, err
+	}
+	if unitID == nil {
+		return property, nil, nil
+	}
+	var unit model.Unit
+	err := r.db.Unscoped().First(&unit, *unitID).Error
+	return property, &unit, err
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"propmanager/internal/app/model"
+
+	"gorm.io/gorm"
+)
+
+// LeaseFilter narrows a lease listing. Zero fields do not filter.
+type LeaseFilter struct {
+	PropertyID uint
+	UnitID     uint
+	TenantID   uint
+	Status     string
+}
+
+type LeaseRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewLeaseRepository(db *gorm.DB) *LeaseRepository {
+	return &LeaseRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *LeaseRepository) WithContext(ctx context.Context) *LeaseRepository {
+	return NewLeaseRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *LeaseRepository) Transaction(fn func(repo *LeaseRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewLeaseRepository(tx))
+	})
+}
+
+// GetLeases returns the leases matching filter, latest start first.
+func (r *LeaseRepository) GetLeases(filter LeaseFilter) ([]model.Lease, error) {
+	query := r.db.Model(&model.Lease{}).Preload("Tenants")
+	if filter.PropertyID != 0 {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+	if filter.UnitID != 0 {
+		query = query.Where("unit_id = ?", filter.UnitID)
+	}
+	if filter.TenantID != 0 {
+		query = query.Where("id IN (?)", r.db.Table("lease_tenants").Select("lease_id").Where("tenant_id = ?", filter.TenantID))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var leases []model.Lease
+	err := query.Order("start_date DESC, id DESC").Find(&leases).Error
+	return leases, err
+}
+
+func (r *LeaseRepository) GetLease(id uint) (model.Lease, error) {
+	var lease model.Lease
+	err := r.db.Model(&model.Lease{}).Preload("Tenants").First(&lease, id).Error
+	return lease, err
+}
+
+// CreateLease stores a lease and links it to its tenants, which must exist.
+func (r *LeaseRepository) CreateLease(lease *model.Lease) error {
+	return r.db.Omit("Tenants.*").Create(lease).Error
+}
+
+// UpdateLease writes the terms of a draft lease and replaces its tenants.
+func (r *LeaseRepository) UpdateLease(lease *model.Lease) error {
+	err := r.db.Model(&model.Lease{}).Where("id = ?", lease.ID).Updates(map[string]interface{}{
+		"property_id": lease.PropertyID,
+		"unit_id":     lease.UnitID,
+		"start_date":  lease.StartDate,
+		"end_date":    lease.EndDate,
+		"rent":        lease.Rent,
+		"deposit":     lease.Deposit,
+		"billing_day": lease.BillingDay,
+		"terms":       lease.Terms,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Model(lease).Omit("Tenants.*").Association("Tenants").Replace(lease.Tenants)
+}
+
+// UpdateLeaseFields writes the given columns of a lease.
+func (r *LeaseRepository) UpdateLeaseFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.Lease{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteLease soft-deletes a lease.
+func (r *LeaseRepository) DeleteLease(id uint) error {
+	return r.db.Delete(&model.Lease{}, id).Error
+}
+
+// DeleteDraftRenewals soft-deletes the draft leases renewing a lease.
+func (r *LeaseRepository) DeleteDraftRenewals(id uint) error {
+	return r.db.Where("renewal_of_id = ? AND status = ?", id, model.LeaseStatusDraft).Delete(&model.Lease{}).Error
+}
+
+// HasRenewalInForce reports whether a renewal of a lease has been put in
+// force.
+func (r *LeaseRepository) HasRenewalInForce(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Lease{}).
+		Where("renewal_of_id = ? AND status IN ?", id, []string{model.LeaseStatusActive, model.LeaseStatusRenewing}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// EndRenewedLeases ends the renewing leases whose end date is before day and
+// whose renewal has been put in force. It returns how many it ended.
+func (r *LeaseRepository) EndRenewedLeases(day time.Time) (int64, error) {
+	renewed := r.db.Model(&model.Lease{}).Select("renewal_of_id").
+		Where("status IN ?", []string{model.LeaseStatusActive, model.LeaseStatusRenewing})
+	result := r.db.Model(&model.Lease{}).
+		Where("status = ? AND end_date < ? AND id IN (?)", model.LeaseStatusRenewing, day, renewed).
+		Update("status", model.LeaseStatusEnded)
+	return result.RowsAffected, result.Error
+}
+
+// HasOverlappingLease reports whether a lease other than lease is in force
+// on the same unit for part of its term. A lease of a whole property
+// overlaps every lease of its units.
+func (r *LeaseRepository) HasOverlappingLease(lease model.Lease) (bool, error) {
+	query := r.db.Model(&model.Lease{}).
+		Where("id <> ?", lease.ID).
+		Where("status IN ?", []string{model.LeaseStatusActive, model.LeaseStatusRenewing}).
+		Where("property_id = ?", lease.PropertyID).
+		Where("start_date <= ? AND end_date >= ?", lease.EndDate, lease.StartDate)
+	if lease.UnitID != nil {
+		query = query.Where("unit_id IS NULL OR unit_id = ?", *lease.UnitID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// CountTenants returns how many of the given tenants exist.
+func (r *LeaseRepository) CountTenants(ids []uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Tenant{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
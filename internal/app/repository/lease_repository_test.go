@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+// newTestDB returns an empty database with the schema of the models used
+// by repository tests.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Property{}, &model.Unit{}, &model.Tenant{}, &model.Lease{}, &model.LedgerEntry{}, &model.Expense{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func date(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestHasOverlappingLease(t *testing.T) {
+	db := newTestDB(t)
+	unitA, unitB := uint(1), uint(2)
+	existing := []model.Lease{
+		{PropertyID: 1, UnitID: &unitA, Status: model.LeaseStatusActive, StartDate: date("2026-01-01"), EndDate: date("2026-12-31")},
+		{PropertyID: 1, UnitID: &unitB, Status: model.LeaseStatusEnded, StartDate: date("2026-01-01"), EndDate: date("2026-12-31")},
+		{PropertyID: 2, Status: model.LeaseStatusRenewing, StartDate: date("2026-01-01"), EndDate: date("2026-06-30")},
+	}
+	if err := db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+	repo := NewLeaseRepository(db)
+
+	tests := []struct {
+		name  string
+		lease model.Lease
+		want  bool
+	}{
+		{"same unit, overlapping", model.Lease{PropertyID: 1, UnitID: &unitA, StartDate: date("2026-12-31"), EndDate: date("2027-12-31")}, true},
+		{"same unit, after", model.Lease{PropertyID: 1, UnitID: &unitA, StartDate: date("2027-01-01"), EndDate: date("2027-12-31")}, false},
+		{"same unit, before", model.Lease{PropertyID: 1, UnitID: &unitA, StartDate: date("2025-01-01"), EndDate: date("2025-12-31")}, false},
+		{"the lease itself", existing[0], false},
+		{"unit with a lease no longer in force", model.Lease{PropertyID: 1, UnitID: &unitB, StartDate: date("2026-03-01"), EndDate: date("2026-09-30")}, false},
+		{"whole property over a unit lease", model.Lease{PropertyID: 1, StartDate: date("2026-06-01"), EndDate: date("2026-06-30")}, true},
+		{"unit of a property let whole", model.Lease{PropertyID: 2, UnitID: &unitB, StartDate: date("2026-06-30"), EndDate: date("2026-07-31")}, true},
+		{"other property", model.Lease{PropertyID: 3, UnitID: &unitA, StartDate: date("2026-01-01"), EndDate: date("2026-12-31")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.HasOverlappingLease(tt.lease)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("HasOverlappingLease = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"propmanager/internal/app/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *LedgerRepository) WithContext(ctx context.Context) *LedgerRepository {
+	return &LedgerRepository{db: r.db.WithContext(ctx)}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *LedgerRepository) Transaction(fn func(repo *LedgerRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&LedgerRepository{db: tx})
+	})
+}
+
+func (r *LedgerRepository) GetLease(id uint) (model.Lease, error) {
+	var lease model.Lease
+	err := r.db.First(&lease, id).Error
+	return lease, err
+}
+
+// GetLeasesInForce returns the active and renewing leases that started on
+// or before day.
+func (r *LedgerRepository) GetLeasesInForce(day time.Time) ([]model.Lease, error) {
+	var leases []model.Lease
+	err := r.db.Where("status IN ? AND start_date <= ?", []string{model.LeaseStatusActive, model.LeaseStatusRenewing}, day).
+		Order("id").Find(&leases).Error
+	return leases, err
+}
+
+// GetBilledLeases returns the leases other than drafts with their tenants,
+// only those of a property if propertyID is not zero.
+func (r *LedgerRepository) GetBilledLeases(propertyID uint) ([]model.Lease, error) {
+	query := r.db.Preload("Tenants").Where("status <> ?", model.LeaseStatusDraft)
+	if propertyID != 0 {
+		query = query.Where("property_id = ?", propertyID)
+	}
+
+	var leases []model.Lease
+	err := query.Order("id").Find(&leases).Error
+	return leases, err
+}
+
+// GetTenantLeases returns the leases of a tenant other than drafts.
+func (r *LedgerRepository) GetTenantLeases(tenantID uint) ([]model.Lease, error) {
+	var leases []model.Lease
+	err := r.db.Where("status <> ?", model.LeaseStatusDraft).
+		Where("id IN (?)", r.db.Table("lease_tenants").Select("lease_id").Where("tenant_id = ?", tenantID)).
+		Order("start_date, id").Find(&leases).Error
+	return leases, err
+}
+
+func (r *LedgerRepository) GetTenant(id uint) (model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.db.First(&tenant, id).Error
+	return tenant, err
+}
+
+// GetEntries returns the entries of a lease dated on or before day, or all
+// of them if day is nil, in date order.
+func (r *LedgerRepository) GetEntries(leaseID uint, day *time.Time) ([]model.LedgerEntry, error) {
+	query := r.db.Where("lease_id = ?", leaseID)
+	if day != nil {
+		query = query.Where("date <= ?", *day)
+	}
+
+	var entries []model.LedgerEntry
+	err := query.Order("date, id").Find(&entries).Error
+	return entries, err
+}
+
+func (r *LedgerRepository) CreateEntry(entry *model.LedgerEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// CreateEntryOnce stores an entry unless one with the same posting key has
+// already been posted to the lease. It reports whether the entry was stored.
+func (r *LedgerRepository) CreateEntryOnce(entry *model.LedgerEntry) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+// MaintenanceFilter narrows a list of maintenance requests. Zero fields do
+// not filter.
+type MaintenanceFilter struct {
+	PropertyID uint
+	UnitID     uint
+	Status     string
+	Priority   string
+	Category   string
+	Assignee   string
+	// OverdueAt, if not zero, keeps only the requests still unresolved
+	// after their due date at that time.
+	OverdueAt time.Time
+}
+
+type MaintenanceRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewMaintenanceRepository(db *gorm.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *MaintenanceRepository) WithContext(ctx context.Context) *MaintenanceRepository {
+	return NewMaintenanceRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *MaintenanceRepository) Transaction(fn func(repo *MaintenanceRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewMaintenanceRepository(tx))
+	})
+}
+
+// GetRequests returns the maintenance requests matching filter, soonest due
+// first, with their photos but not their comments.
+func (r *MaintenanceRepository) GetRequests(filter MaintenanceFilter) ([]model.MaintenanceRequest, error) {
+	query := r.db.Model(&model.MaintenanceRequest{}).Preload("Photos")
+	if filter.PropertyID != 0 {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+	if filter.UnitID != 0 {
+		query = query.Where("unit_id = ?", filter.UnitID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Assignee != "" {
+		query = query.Where("assignee = ?", filter.Assignee)
+	}
+	if !filter.OverdueAt.IsZero() {
+		query = query.Where("status NOT IN ? AND due_at < ?",
+			[]string{model.MaintenanceStatusResolved, model.MaintenanceStatusClosed}, filter.OverdueAt)
+	}
+
+	var requests []model.MaintenanceRequest
+	err := query.Order("due_at, id").Find(&requests).Error
+	return requests, err
+}
+
+// GetRequest returns a maintenance request with its photos and comments,
+// oldest comment first.
+func (r *MaintenanceRepository) GetRequest(id uint) (model.MaintenanceRequest, error) {
+	var request model.MaintenanceRequest
+	err := r.db.Model(&model.MaintenanceRequest{}).
+		Preload("Photos").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&request, id).Error
+	return request, err
+}
+
+func (r *MaintenanceRepository) CreateRequest(request *model.MaintenanceRequest) error {
+	return r.db.Omit("Photos", "Comments").Create(request).Error
+}
+
+// UpdateRequestFields writes the given columns of a maintenance request.
+func (r *MaintenanceRepository) UpdateRequestFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.MaintenanceRequest{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteRequest soft-deletes a maintenance request and its photos. It
+// reports whether a row was deleted.
+func (r *MaintenanceRepository) DeleteRequest(id uint) (bool, error) {
+	result := r.db.Delete(&model.MaintenanceRequest{}, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, r.db.Where("request_id = ?", id).Delete(&model.MaintenancePhoto{}).Error
+}
+
+// CountWorkOrders returns the number of work orders issued for a
+// maintenance request.
+func (r *MaintenanceRepository) CountWorkOrders(requestID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WorkOrder{}).Where("maintenance_request_id = ?", requestID).Count(&count).Error
+	return count, err
+}
+
+func (r *MaintenanceRepository) CreateComment(comment *model.MaintenanceComment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *MaintenanceRepository) CreatePhoto(photo *model.MaintenancePhoto) error {
+	return r.db.Create(photo).Error
+}
+
+func (r *MaintenanceRepository) GetPhoto(requestID uint, photoID uint) (model.MaintenancePhoto, error) {
+	var photo model.MaintenancePhoto
+	err := r.db.Where("request_id = ?", requestID).First(&photo, photoID).Error
+	return photo, err
+}
+
+// DeletePhoto soft-deletes a photo of a maintenance request. It reports
+// whether a row was deleted.
+func (r *MaintenanceRepository) DeletePhoto(requestID uint, photoID uint) (bool, error) {
+	result := r.db.Where("request_id = ? AND id = ?", requestID, photoID).Delete(&model.MaintenancePhoto{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// TenantExists reports whether a tenant exists and is not deleted.
+func (r *MaintenanceRepository) TenantExists(tenantID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Tenant{}).Where("id = ?", tenantID).Count(&count).Error
+	return count > 0, err
+}
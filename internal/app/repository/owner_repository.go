@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+type OwnerRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewOwnerRepository(db *gorm.DB) *OwnerRepository {
+	return &OwnerRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *OwnerRepository) WithContext(ctx context.Context) *OwnerRepository {
+	return NewOwnerRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *OwnerRepository) Transaction(fn func(repo *OwnerRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewOwnerRepository(tx))
+	})
+}
+
+// GetOwners returns the owners ordered by name, only those whose name or
+// email address contains query if it is not empty.
+func (r *OwnerRepository) GetOwners(query string) ([]model.Owner, error) {
+	db := r.db.Model(&model.Owner{}).Preload("Ownerships")
+	if query != "" {
+		like := "%" + escapeLike(strings.ToLower(query)) + "%"
+		db = db.Where("LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\'", like, like)
+	}
+
+	var owners []model.Owner
+	err := db.Order("name, id").Find(&owners).Error
+	return owners, err
+}
+
+func (r *OwnerRepository) GetOwner(id uint) (model.Owner, error) {
+	var owner model.Owner
+	err := r.db.Model(&model.Owner{}).Preload("Ownerships").First(&owner, id).Error
+	return owner, err
+}
+
+func (r *OwnerRepository) CreateOwner(owner *model.Owner) error {
+	return r.db.Omit("Ownerships").Create(owner).Error
+}
+
+// UpdateOwner writes the owner's editable fields. It reports whether the
+// owner exists.
+func (r *OwnerRepository) UpdateOwner(owner *model.Owner) (bool, error) {
+	result := r.db.Model(&model.Owner{}).Where("id = ?", owner.ID).
+		Select("Name", "Email", "Phone", "MailingAddress", "Notes").Updates(owner)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteOwner soft-deletes an owner and removes their shares of
+// properties. It reports whether the owner existed.
+func (r *OwnerRepository) DeleteOwner(id uint) (bool, error) {
+	result := r.db.Delete(&model.Owner{}, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, r.db.Where("owner_id = ?", id).Delete(&model.Ownership{}).Error
+}
+
+// CountOwners returns how many of the given owners exist.
+func (r *OwnerRepository) CountOwners(ids []uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Owner{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
+
+// GetOwnerships returns the shares of a property with their owners,
+// largest first.
+func (r *OwnerRepository) GetOwnerships(propertyID uint) ([]model.Ownership, error) {
+	var ownerships []model.Ownership
+	err := r.db.Preload("Owner").Where("property_id = ?", propertyID).Order("percent DESC, owner_id").Find(&ownerships).Error
+	return ownerships, err
+}
+
+// ReplaceOwnerships replaces the shares of a property.
+func (r *OwnerRepository) ReplaceOwnerships(propertyID uint, ownerships []model.Ownership) error {
+	if err := r.db.Where("property_id = ?", propertyID).Delete(&model.Ownership{}).Error; err != nil {
+		return err
+	}
+	if len(ownerships) == 0 {
+		return nil
+	}
+	return r.db.Omit("Owner").Create(&ownerships).Error
+}
+
+// GetAgreements returns the management agreements of a property, latest
+// first.
+func (r *OwnerRepository) GetAgreements(propertyID uint) ([]model.ManagementAgreement, error) {
+	var agreements []model.ManagementAgreement
+	err := r.db.Where("property_id = ?", propertyID).Order("start_date DESC, id DESC").Find(&agreements).Error
+	return agreements, err
+}
+
+func (r *OwnerRepository) GetAgreement(propertyID uint, id uint) (model.ManagementAgreement, error) {
+	var agreement model.ManagementAgreement
+	err := r.db.Where("property_id = ?", propertyID).First(&agreement, id).Error
+	return agreement, err
+}
+
+func (r *OwnerRepository) CreateAgreement(agreement *model.ManagementAgreement) error {
+	return r.db.Create(agreement).Error
+}
+
+// UpdateAgreement writes the agreement's editable fields.
+func (r *OwnerRepository) UpdateAgreement(agreement *model.ManagementAgreement) error {
+	return r.db.Model(&model.ManagementAgreement{}).Where("id = ?", agreement.ID).
+		Select("StartDate", "EndDate", "FeePercent", "FlatFee", "Notes").Updates(agreement).Error
+}
+
+// DeleteAgreement deletes a management agreement of a property. It reports
+// whether a row was deleted.
+func (r *OwnerRepository) DeleteAgreement(propertyID uint, id uint) (bool, error) {
+	result := r.db.Where("property_id = ?", propertyID).Delete(&model.ManagementAgreement{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *OwnerRepository) GetProperty(id uint) (model.Property, error) {
+	var property model.Property
+	err := r.db.First(&property, id).Error
+	return property, err
+}
+
+// GetPropertyPayments returns the payments received from the leases of a
+// property, and the refunds of payments charged back to them, dated from
+// from to to inclusive.
+func (r *OwnerRepository) GetPropertyPayments(propertyID uint, from, to time.Time) ([]model.LedgerEntry, error) {
+	var payments []model.LedgerEntry
+	err := r.db.Joins("JOIN leases ON leases.id = ledger_entries.lease_id").
+		Where("leases.property_id = ?", propertyID).
+		Where("ledger_entries.type = ? OR (ledger_entries.type = ? AND ledger_entries.category = ?)",
+			model.LedgerEntryPayment, model.LedgerEntryCharge, model.ChargeCategoryRefund).
+		Where("ledger_entries.date >= ? AND ledger_entries.date <= ?", from, to).
+		Order("ledger_entries.date, ledger_entries.id").Find(&payments).Error
+	return payments, err
+}
+
+// GetExpenses returns the expenses of a property dated from from to to
+// inclusive, in date order.
+func (r *OwnerRepository) GetExpenses(propertyID uint, from, to time.Time) ([]model.Expense, error) {
+	var expenses []model.Expense
+	err := r.db.Where("property_id = ? AND date >= ? AND date <= ?", propertyID, from, to).
+		Order("date, id").Find(&expenses).Error
+	return expenses, err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+func TestGetPropertyPaymentsIncludesRefunds(t *testing.T) {
+	db := newTestDB(t)
+	leases := []model.Lease{
+		{PropertyID: 1, Status: model.LeaseStatusActive, StartDate: date("2026-01-01"), EndDate: date("2026-12-31")},
+		{PropertyID: 2, Status: model.LeaseStatusActive, StartDate: date("2026-01-01"), EndDate: date("2026-12-31")},
+	}
+	if err := db.Create(&leases).Error; err != nil {
+		t.Fatal(err)
+	}
+	entries := []model.LedgerEntry{
+		{LeaseID: 1, Type: model.LedgerEntryCharge, Category: model.ChargeCategoryRent, Date: date("2026-03-01"), Amount: decimal.NewFromInt(950)},
+		{LeaseID: 1, Type: model.LedgerEntryPayment, Date: date("2026-03-02"), Amount: decimal.NewFromInt(950)},
+		{LeaseID: 1, Type: model.LedgerEntryCharge, Category: model.ChargeCategoryRefund, Date: date("2026-03-09"), Amount: decimal.NewFromInt(200)},
+		{LeaseID: 1, Type: model.LedgerEntryCredit, Date: date("2026-03-10"), Amount: decimal.NewFromInt(50)},
+		{LeaseID: 1, Type: model.LedgerEntryPayment, Date: date("2026-04-02"), Amount: decimal.NewFromInt(950)},
+		{LeaseID: 2, Type: model.LedgerEntryPayment, Date: date("2026-03-02"), Amount: decimal.NewFromInt(700)},
+	}
+	if err := db.Create(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := NewOwnerRepository(db).GetPropertyPayments(1, date("2026-03-01"), date("2026-03-31"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments) != 2 || payments[0].ID != entries[1].ID || payments[1].ID != entries[2].ID {
+		t.Fatalf("payments = %+v, want the March payment and refund of lease 1", payments)
+	}
+}
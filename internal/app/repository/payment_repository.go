@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"propmanager/internal/app/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *PaymentRepository) WithContext(ctx context.Context) *PaymentRepository {
+	return &PaymentRepository{db: r.db.WithContext(ctx)}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *PaymentRepository) Transaction(fn func(repo *PaymentRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&PaymentRepository{db: tx})
+	})
+}
+
+func (r *PaymentRepository) GetLease(id uint) (model.Lease, error) {
+	var lease model.Lease
+	err := r.db.First(&lease, id).Error
+	return lease, err
+}
+
+// GetEntries returns the ledger entries of a lease in date order.
+func (r *PaymentRepository) GetEntries(leaseID uint) ([]model.LedgerEntry, error) {
+	var entries []model.LedgerEntry
+	err := r.db.Where("lease_id = ?", leaseID).Order("date, id").Find(&entries).Error
+	return entries, err
+}
+
+// CreateEntryOnce stores a ledger entry unless one with the same posting
+// key has already been posted to the lease. It reports whether the entry
+// was stored.
+func (r *PaymentRepository) CreateEntryOnce(entry *model.LedgerEntry) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentRepository) CreatePaymentIntent(intent *model.PaymentIntent) error {
+	return r.db.Create(intent).Error
+}
+
+// GetPaymentIntents returns the payment intents of a lease, newest first.
+func (r *PaymentRepository) GetPaymentIntents(leaseID uint) ([]model.PaymentIntent, error) {
+	var intents []model.PaymentIntent
+	err := r.db.Where("lease_id = ?", leaseID).Order("id DESC").Find(&intents).Error
+	return intents, err
+}
+
+func (r *PaymentRepository) GetPaymentIntentByProviderID(providerID string) (model.PaymentIntent, error) {
+	var intent model.PaymentIntent
+	err := r.db.Where("provider_id = ?", providerID).First(&intent).Error
+	return intent, err
+}
+
+// GetPaymentIntentsByProviderIDs returns the payment intents with the given
+// provider IDs.
+func (r *PaymentRepository) GetPaymentIntentsByProviderIDs(providerIDs []string) ([]model.PaymentIntent, error) {
+	var intents []model.PaymentIntent
+	err := r.db.Where("provider_id IN ?", providerIDs).Find(&intents).Error
+	return intents, err
+}
+
+// UpdatePaymentIntentFields writes the given columns of a payment intent.
+func (r *PaymentRepository) UpdatePaymentIntentFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.PaymentIntent{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// RecordWebhookEvent stores a webhook event unless it has been recorded
+// before. It reports whether the event is new.
+func (r *PaymentRepository) RecordWebhookEvent(event *model.WebhookEvent) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PaymentRepository) GetPayoutByProviderID(providerID string) (model.Payout, error) {
+	var payout model.Payout
+	err := r.db.Where("provider_id = ?", providerID).First(&payout).Error
+	return payout, err
+}
+
+func (r *PaymentRepository) CreatePayout(payout *model.Payout) error {
+	return r.db.Omit("Payments").Create(payout).Error
+}
+
+// AssignPayout links the given payment intents to a payout.
+func (r *PaymentRepository) AssignPayout(payoutID uint, intentIDs []uint) error {
+	if len(intentIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.PaymentIntent{}).Where("id IN ?", intentIDs).Update("payout_id", payoutID).Error
+}
+
+// GetPayouts returns the payouts, latest arrival first, optionally only
+// those with the given status.
+func (r *PaymentRepository) GetPayouts(status string) ([]model.Payout, error) {
+	query := r.db.Model(&model.Payout{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var payouts []model.Payout
+	err := query.Order("arrival_date DESC, id DESC").Find(&payouts).Error
+	return payouts, err
+}
+
+// GetPayout returns a payout with the payment intents it settled.
+func (r *PaymentRepository) GetPayout(id uint) (model.Payout, error) {
+	var payout model.Payout
+	err := r.db.Preload("Payments").First(&payout, id).Error
+	return payout, err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+// places looks up the property, and the unit of it, that a record belongs
+// to. It is embedded in the repositories of such records.
+type places struct {
+	db *gorm.DB
+}
+
+// PropertyExists reports whether a property exists and is not deleted.
+func (p places) PropertyExists(propertyID uint) (bool, error) {
+	var count int64
+	err := p.db.Model(&model.Property{}).Where("id = ?", propertyID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetUnit returns a unit whose property is not deleted.
+func (p places) GetUnit(unitID uint) (model.Unit, error) {
+	var unit model.Unit
+	err := p.db.Model(&model.Unit{}).
+		Joins("JOIN properties ON properties.id = units.property_id AND properties.deleted_at IS NULL").
+		First(&unit, unitID).Error
+	return unit, err
+}
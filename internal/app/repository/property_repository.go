@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"propmanager/internal/app/model"
 
 	"gorm.io/gorm"
@@ -14,6 +16,18 @@ func NewPropertyRepository(db *gorm.DB) *PropertyRepository {
 	return &PropertyRepository{db: db}
 }
 
+// WithContext returns a repository whose queries run with ctx.
+func (r *PropertyRepository) WithContext(ctx context.Context) *PropertyRepository {
+	return &PropertyRepository{db: r.db.WithContext(ctx)}
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *PropertyRepository) Transaction(fn func(repo *PropertyRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&PropertyRepository{db: tx})
+	})
+}
+
 func (r *PropertyRepository) GetAllProperties() ([]model.Property, error) {
 	var properties []model.Property
 	err := r.db.Model(&model.Property{}).Preload("Images").Find(&properties).Error
@@ -26,18 +40,144 @@ func (r *PropertyRepository) GetProperty(id uint) (model.Property, error) {
 	return property, err
 }
 
+// GetPropertyUnscoped returns a property and all of its images, including soft-deleted ones.
+func (r *PropertyRepository) GetPropertyUnscoped(id uint) (model.Property, error) {
+	var property model.Property
+	err := r.db.Unscoped().Model(&model.Property{}).Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).First(&property, id).Error
+	return property, err
+}
+
 func (r *PropertyRepository) CreateProperty(property *model.Property) error {
 	return r.db.Create(property).Error
 }
 
-func (r *PropertyRepository) UpdateProperty(property *model.Property) error {
-	return r.db.Save(property).Error
+// UpdateProperty writes the property's fields if its stored version still
+// matches expectedVersion, bumping the version. It reports whether a row was updated.
+func (r *PropertyRepository) UpdateProperty(property *model.Property, expectedVersion uint) (bool, error) {
+	result := r.db.Model(&model.Property{}).
+		Where("id = ? AND version = ?", property.ID, expectedVersion).
+		Updates(map[string]interface{}{
+			"name":        property.Name,
+			"description": property.Description,
+			"price":       property.Price,
+			"location":    property.Location,
+			"status":      property.Status,
+			"version":     gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// TouchProperty bumps the version of a property whose images have changed.
+func (r *PropertyRepository) TouchProperty(id uint) error {
+	return r.db.Model(&model.Property{}).Where("id = ?", id).Update("version", gorm.Expr("version + 1")).Error
+}
+
+// RestoreProperty overwrites the property and its images with the given state,
+// undeleting any rows that are part of it and soft-deleting those that are not.
+func (r *PropertyRepository) RestoreProperty(property *model.Property) error {
+	err := r.db.Unscoped().Model(&model.Property{}).Where("id = ?", property.ID).Updates(map[string]interface{}{
+		"name":        property.Name,
+		"description": property.Description,
+		"price":       property.Price,
+		"location":    property.Location,
+		"status":      property.Status,
+		"version":     gorm.Expr("version + 1"),
+		"deleted_at":  nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	imageIDs := make([]uint, 0, len(property.Images))
+	for _, image := range property.Images {
+		imageIDs = append(imageIDs, image.ID)
+	}
+
+	stale := r.db.Where("property_id = ?", property.ID)
+	if len(imageIDs) > 0 {
+		stale = stale.Where("id NOT IN ?", imageIDs)
+	}
+	if err := stale.Delete(&model.Image{}).Error; err != nil {
+		return err
+	}
+
+	if len(imageIDs) == 0 {
+		return nil
+	}
+	return r.db.Unscoped().Model(&model.Image{}).
+		Where("property_id = ? AND id IN ?", property.ID, imageIDs).
+		Update("deleted_at", nil).Error
+}
+
+// DeleteProperty soft-deletes the property if its stored version still
+// matches expectedVersion. It reports whether a row was deleted.
+func (r *PropertyRepository) DeleteProperty(id uint, expectedVersion uint) (bool, error) {
+	result := r.db.Where("version = ?", expectedVersion).Delete(&model.Property{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PropertyRepository) CreateImage(image *model.Image) error {
+	return r.db.Create(image).Error
+}
+
+// DeleteImage soft-deletes an image of a property. It reports whether a row was deleted.
+func (r *PropertyRepository) DeleteImage(propertyID uint, imageID uint) (bool, error) {
+	result := r.db.Where("property_id = ? AND id = ?", propertyID, imageID).Delete(&model.Image{})
+	return result.RowsAffected > 0, result.Error
 }
 
-func (r *PropertyRepository) DeleteProperty(id uint) error {
-	return r.db.Delete(&model.Property{}, id).Error
+func (r *PropertyRepository) CreatePropertyVersion(version *model.PropertyVersion) error {
+	return r.db.Create(version).Error
 }
 
-func (r *PropertyRepository) DeleteImage(propertyID uint, imageID uint) error {
-	return r.db.Where("property_id = ? AND id = ?", propertyID, imageID).Delete(&model.Image{}).Error
+func (r *PropertyRepository) GetPropertyVersions(propertyID uint) ([]model.PropertyVersion, error) {
+	var versions []model.PropertyVersion
+	err := r.db.Where("property_id = ?", propertyID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+func (r *PropertyRepository) GetPropertyVersion(propertyID uint, version uint) (model.PropertyVersion, error) {
+	var propertyVersion model.PropertyVersion
+	err := r.db.Where("property_id = ? AND version = ?", propertyID, version).First(&propertyVersion).Error
+	return propertyVersion, err
+}
+
+func (r *PropertyRepository) GetLatestPropertyVersion(propertyID uint) (model.PropertyVersion, error) {
+	var propertyVersion model.PropertyVersion
+	err := r.db.Where("property_id = ?", propertyID).Order("version DESC").First(&propertyVersion).Error
+	return propertyVersion, err
+}
+
+// GetOccupancy returns the occupancy of each of the given properties,
+// including those without units.
+func (r *PropertyRepository) GetOccupancy(propertyIDs []uint) (map[uint]model.Occupancy, error) {
+	var rows []struct {
+		PropertyID uint
+		Status     string
+		Count      int64
+	}
+	err := r.db.Model(&model.Unit{}).
+		Select("property_id, status, COUNT(*) AS count").
+		Where("property_id IN ?", propertyIDs).
+		Group("property_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]map[string]int64, len(propertyIDs))
+	for _, id := range propertyIDs {
+		counts[id] = map[string]int64{}
+	}
+	for _, row := range rows {
+		counts[row.PropertyID][row.Status] = row.Count
+	}
+
+	occupancy := make(map[uint]model.Occupancy, len(propertyIDs))
+	for id, byStatus := range counts {
+		occupancy[id] = model.NewOccupancy(byStatus)
+	}
+	return occupancy, nil
 }
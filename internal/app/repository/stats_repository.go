@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"propmanager/internal/app/model"
+
+	"gorm.io/gorm"
+)
+
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *StatsRepository) WithContext(ctx context.Context) *StatsRepository {
+	return &StatsRepository{db: r.db.WithContext(ctx)}
+}
+
+// properties returns a query over the non-deleted properties created in [from, to).
+func (r *StatsRepository) properties(from, to *time.Time) *gorm.DB {
+	query := r.db.Model(&model.Property{})
+	if from != nil {
+		query = query.Where("properties.created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("properties.created_at < ?", *to)
+	}
+	return query
+}
+
+func (r *StatsRepository) CountProperties(from, to *time.Time) (int64, error) {
+	var count int64
+	err := r.properties(from, to).Count(&count).Error
+	return count, err
+}
+
+// CountPropertiesBy returns the number of properties for each distinct value of column.
+func (r *StatsRepository) CountPropertiesBy(column string, from, to *time.Time) (map[string]int64, error) {
+	var rows []struct {
+		Value string
+		Count int64
+	}
+	err := r.properties(from, to).
+		Select(column + " AS value, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *StatsRepository) CountPropertiesWithoutImages(from, to *time.Time) (int64, error) {
+	var count int64
+	err := r.properties(from, to).
+		Where("NOT EXISTS (?)", r.db.Model(&model.Image{}).Select("1").Where("images.property_id = properties.id")).
+		Count(&count).Error
+	return count, err
+}
+
+// PriceSummary returns the average, minimum and maximum price.
+func (r *StatsRepository) PriceSummary(from, to *time.Time) (model.PriceStats, error) {
+	var summary struct {
+		Average *float64
+		Min     *float64
+		Max     *float64
+	}
+	err := r.properties(from, to).
+		Select("AVG(price) AS average, MIN(price) AS min, MAX(price) AS max").
+		Scan(&summary).Error
+	if err != nil || summary.Average == nil {
+		return model.PriceStats{}, err
+	}
+	return model.PriceStats{Average: *summary.Average, Min: *summary.Min, Max: *summary.Max}, nil
+}
+
+// MedianPrice returns the median price of count properties without loading them all.
+func (r *StatsRepository) MedianPrice(count int64, from, to *time.Time) (float64, error) {
+	if count == 0 {
+		return 0, nil
+	}
+
+	var prices []float64
+	err := r.properties(from, to).
+		Order("price").
+		Offset(int((count-1)/2)).
+		Limit(int(2-count%2)).
+		Pluck("price", &prices).Error
+	if err != nil || len(prices) == 0 {
+		return 0, err
+	}
+
+	var sum float64
+	for _, price := range prices {
+		sum += price
+	}
+	return sum / float64(len(prices)), nil
+}
+
+func (r *StatsRepository) PropertyCreationTimes(from, to *time.Time) ([]time.Time, error) {
+	var times []time.Time
+	err := r.properties(from, to).Order("created_at").Pluck("created_at", &times).Error
+	return times, err
+}
+
+// ImageStorage returns the number and total size of the images of the properties in range.
+func (r *StatsRepository) ImageStorage(from, to *time.Time) (model.StorageStats, error) {
+	var storage model.StorageStats
+	err := r.db.Model(&model.Image{}).
+		Select("COUNT(*) AS images, COALESCE(SUM(images.size), 0) AS bytes").
+		Where("images.property_id IN (?)", r.properties(from, to).Select("properties.id")).
+		Scan(&storage).Error
+	return storage, err
+}
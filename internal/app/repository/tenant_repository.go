@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"propmanager/internal/app/model"
+
+	"gorm.io/gorm"
+)
+
+// TenantFilter narrows a tenant search. Zero fields do not filter.
+type TenantFilter struct {
+	// Query matches part of a tenant's name, email address or phone number.
+	Query      string
+	PropertyID uint
+	UnitID     uint
+}
+
+type TenantRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *TenantRepository) WithContext(ctx context.Context) *TenantRepository {
+	return NewTenantRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *TenantRepository) Transaction(fn func(repo *TenantRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewTenantRepository(tx))
+	})
+}
+
+// GetTenants returns the tenants matching filter ordered by name.
+func (r *TenantRepository) GetTenants(filter TenantFilter) ([]model.Tenant, error) {
+	query := r.db.Model(&model.Tenant{}).Preload("EmergencyContacts").Preload("Documents")
+	if filter.Query != "" {
+		like := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where(
+			"LOWER(first_name || ' ' || last_name) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\' OR phone LIKE ? ESCAPE '\\'",
+			like, like, like,
+		)
+	}
+	if filter.PropertyID != 0 {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+	if filter.UnitID != 0 {
+		query = query.Where("unit_id = ?", filter.UnitID)
+	}
+
+	var tenants []model.Tenant
+	err := query.Order("last_name, first_name, id").Find(&tenants).Error
+	return tenants, err
+}
+
+func (r *TenantRepository) GetTenant(id uint) (model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.db.Model(&model.Tenant{}).Preload("EmergencyContacts").Preload("Documents").First(&tenant, id).Error
+	return tenant, err
+}
+
+// CreateTenant stores a tenant and its emergency contacts.
+func (r *TenantRepository) CreateTenant(tenant *model.Tenant) error {
+	return r.db.Omit("Documents").Create(tenant).Error
+}
+
+// UpdateTenant writes the tenant's editable fields and replaces its
+// emergency contacts. It reports whether the tenant exists.
+func (r *TenantRepository) UpdateTenant(tenant *model.Tenant) (bool, error) {
+	result := r.db.Model(&model.Tenant{}).Where("id = ?", tenant.ID).Updates(map[string]interface{}{
+		"first_name":           tenant.FirstName,
+		"last_name":            tenant.LastName,
+		"email":                tenant.Email,
+		"phone":                tenant.Phone,
+		"mailing_address":      tenant.MailingAddress,
+		"identification_notes": tenant.IdentificationNotes,
+		"property_id":          tenant.PropertyID,
+		"unit_id":              tenant.UnitID,
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	if err := r.db.Where("tenant_id = ?", tenant.ID).Delete(&model.EmergencyContact{}).Error; err != nil {
+		return false, err
+	}
+	if len(tenant.EmergencyContacts) > 0 {
+		if err := r.db.Create(&tenant.EmergencyContacts).Error; err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// DeleteTenant soft-deletes a tenant and its documents. It reports whether
+// a row was deleted.
+func (r *TenantRepository) DeleteTenant(id uint) (bool, error) {
+	result := r.db.Delete(&model.Tenant{}, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, r.db.Where("tenant_id = ?", id).Delete(&model.TenantDocument{}).Error
+}
+
+func (r *TenantRepository) CreateDocument(document *model.TenantDocument) error {
+	return r.db.Create(document).Error
+}
+
+func (r *TenantRepository) GetDocument(tenantID uint, documentID uint) (model.TenantDocument, error) {
+	var document model.TenantDocument
+	err := r.db.Where("tenant_id = ?", tenantID).First(&document, documentID).Error
+	return document, err
+}
+
+// DeleteDocument soft-deletes a document of a tenant. It reports whether a row was deleted.
+func (r *TenantRepository) DeleteDocument(tenantID uint, documentID uint) (bool, error) {
+	result := r.db.Where("tenant_id = ? AND id = ?", tenantID, documentID).Delete(&model.TenantDocument{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
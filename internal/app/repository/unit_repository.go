@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+
+	"propmanager/internal/app/model"
+
+	"gorm.io/gorm"
+)
+
+type UnitRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewUnitRepository(db *gorm.DB) *UnitRepository {
+	return &UnitRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *UnitRepository) WithContext(ctx context.Context) *UnitRepository {
+	return NewUnitRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *UnitRepository) Transaction(fn func(repo *UnitRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewUnitRepository(tx))
+	})
+}
+
+// GetUnits returns the units of a property ordered by number, optionally
+// only those with the given status.
+func (r *UnitRepository) GetUnits(propertyID uint, status string) ([]model.Unit, error) {
+	var units []model.Unit
+	query := r.db.Model(&model.Unit{}).Preload("Images").Where("property_id = ?", propertyID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("number").Find(&units).Error
+	return units, err
+}
+
+// GetUnit returns a unit of a property with its images. Units of deleted
+// properties are not found.
+func (r *UnitRepository) GetUnit(propertyID uint, unitID uint) (model.Unit, error) {
+	var unit model.Unit
+	err := r.db.Model(&model.Unit{}).Preload("Images").
+		Joins("JOIN properties ON properties.id = units.property_id AND properties.deleted_at IS NULL").
+		Where("units.property_id = ?", propertyID).
+		First(&unit, unitID).Error
+	return unit, err
+}
+
+// UnitNumberTaken reports whether another unit of the property already uses
+// number. excludeID is the unit being renamed, or zero.
+func (r *UnitRepository) UnitNumberTaken(propertyID uint, number string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Unit{}).
+		Where("property_id = ? AND number = ? AND id <> ?", propertyID, number, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *UnitRepository) CreateUnit(unit *model.Unit) error {
+	return r.db.Create(unit).Error
+}
+
+// UpdateUnit writes the unit's editable fields. It reports whether a row was updated.
+func (r *UnitRepository) UpdateUnit(unit *model.Unit) (bool, error) {
+	result := r.db.Model(&model.Unit{}).
+		Where("id = ? AND property_id = ?", unit.ID, unit.PropertyID).
+		Updates(map[string]interface{}{
+			"number":      unit.Number,
+			"floor":       unit.Floor,
+			"bedrooms":    unit.Bedrooms,
+			"bathrooms":   unit.Bathrooms,
+			"square_feet": unit.SquareFeet,
+			"rent":        unit.Rent,
+			"status":      unit.Status,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteUnit soft-deletes a unit of a property and its images. It reports whether a row was deleted.
+func (r *UnitRepository) DeleteUnit(propertyID uint, unitID uint) (bool, error) {
+	result := r.db.Where("property_id = ? AND id = ?", propertyID, unitID).Delete(&model.Unit{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, r.db.Where("unit_id = ?", unitID).Delete(&model.UnitImage{}).Error
+}
+
+// HasLeaseInForce reports whether a lease of a unit is active or renewing.
+func (r *UnitRepository) HasLeaseInForce(unitID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Lease{}).
+		Where("unit_id = ? AND status IN ?", unitID, []string{model.LeaseStatusActive, model.LeaseStatusRenewing}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *UnitRepository) CreateUnitImage(image *model.UnitImage) error {
+	return r.db.Create(image).Error
+}
+
+func (r *UnitRepository) GetUnitImage(unitID uint, imageID uint) (model.UnitImage, error) {
+	var image model.UnitImage
+	err := r.db.Where("unit_id = ?", unitID).First(&image, imageID).Error
+	return image, err
+}
+
+// DeleteUnitImage soft-deletes an image of a unit. It reports whether a row was deleted.
+func (r *UnitRepository) DeleteUnitImage(unitID uint, imageID uint) (bool, error) {
+	result := r.db.Where("unit_id = ? AND id = ?", unitID, imageID).Delete(&model.UnitImage{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+// VendorFilter narrows a vendor search. Zero fields do not filter.
+type VendorFilter struct {
+	// Query matches part of a vendor's name or contact name.
+	Query string
+	Trade string
+	// ServiceArea matches vendors serving that area, ignoring case.
+	ServiceArea string
+}
+
+type VendorRepository struct {
+	db *gorm.DB
+}
+
+func NewVendorRepository(db *gorm.DB) *VendorRepository {
+	return &VendorRepository{db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *VendorRepository) WithContext(ctx context.Context) *VendorRepository {
+	return &VendorRepository{db: r.db.WithContext(ctx)}
+}
+
+// GetVendors returns the vendors matching filter ordered by name.
+func (r *VendorRepository) GetVendors(filter VendorFilter) ([]model.Vendor, error) {
+	query := r.db.Model(&model.Vendor{})
+	if filter.Query != "" {
+		like := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where("LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(contact_name) LIKE ? ESCAPE '\\'", like, like)
+	}
+	if filter.Trade != "" {
+		query = query.Where("trade = ?", filter.Trade)
+	}
+	if filter.ServiceArea != "" {
+		// Service areas are stored as a JSON array of strings, so an area
+		// matches as a whole quoted element.
+		element, _ := json.Marshal(strings.ToLower(filter.ServiceArea))
+		query = query.Where("LOWER(service_areas) LIKE ? ESCAPE '\\'", "%"+escapeLike(string(element))+"%")
+	}
+
+	var vendors []model.Vendor
+	err := query.Order("name, id").Find(&vendors).Error
+	return vendors, err
+}
+
+func (r *VendorRepository) GetVendor(id uint) (model.Vendor, error) {
+	var vendor model.Vendor
+	err := r.db.First(&vendor, id).Error
+	return vendor, err
+}
+
+func (r *VendorRepository) CreateVendor(vendor *model.Vendor) error {
+	return r.db.Create(vendor).Error
+}
+
+// UpdateVendor writes the vendor's editable fields. It reports whether the
+// vendor exists.
+func (r *VendorRepository) UpdateVendor(vendor *model.Vendor) (bool, error) {
+	result := r.db.Model(&model.Vendor{}).Where("id = ?", vendor.ID).Select(
+		"Name", "Trade", "ContactName", "Phone", "Email", "ServiceAreas",
+		"HourlyRate", "CalloutFee", "InsuranceExpiresOn", "Notes",
+	).Updates(vendor)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteVendor soft-deletes a vendor. It reports whether a row was deleted.
+func (r *VendorRepository) DeleteVendor(id uint) (bool, error) {
+	result := r.db.Delete(&model.Vendor{}, id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"propmanager/internal/app/model"
+)
+
+// WorkOrderFilter narrows a list of work orders. Zero fields do not filter.
+type WorkOrderFilter struct {
+	MaintenanceRequestID uint
+	PropertyID           uint
+	VendorID             uint
+	Status               string
+}
+
+type WorkOrderRepository struct {
+	places
+	db *gorm.DB
+}
+
+func NewWorkOrderRepository(db *gorm.DB) *WorkOrderRepository {
+	return &WorkOrderRepository{places: places{db: db}, db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx.
+func (r *WorkOrderRepository) WithContext(ctx context.Context) *WorkOrderRepository {
+	return NewWorkOrderRepository(r.db.WithContext(ctx))
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *WorkOrderRepository) Transaction(fn func(repo *WorkOrderRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewWorkOrderRepository(tx))
+	})
+}
+
+// withVendor preloads the vendor of work orders, even if it has since been
+// deleted.
+func withVendor(db *gorm.DB) *gorm.DB {
+	return db.Preload("Vendor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
+// GetWorkOrders returns the work orders matching filter, newest first.
+func (r *WorkOrderRepository) GetWorkOrders(filter WorkOrderFilter) ([]model.WorkOrder, error) {
+	query := withVendor(r.db.Model(&model.WorkOrder{}))
+	if filter.MaintenanceRequestID != 0 {
+		query = query.Where("maintenance_request_id = ?", filter.MaintenanceRequestID)
+	}
+	if filter.PropertyID != 0 {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+	if filter.VendorID != 0 {
+		query = query.Where("vendor_id = ?", filter.VendorID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var orders []model.WorkOrder
+	err := query.Order("id DESC").Find(&orders).Error
+	return orders, err
+}
+
+func (r *WorkOrderRepository) GetWorkOrder(id uint) (model.WorkOrder, error) {
+	var order model.WorkOrder
+	err := withVendor(r.db.Model(&model.WorkOrder{})).First(&order, id).Error
+	return order, err
+}
+
+func (r *WorkOrderRepository) CreateWorkOrder(order *model.WorkOrder) error {
+	return r.db.Omit("Vendor").Create(order).Error
+}
+
+// UpdateWorkOrderFields writes the given columns of a work order.
+func (r *WorkOrderRepository) UpdateWorkOrderFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.WorkOrder{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *WorkOrderRepository) GetMaintenanceRequest(id uint) (model.MaintenanceRequest, error) {
+	var request model.MaintenanceRequest
+	err := r.db.First(&request, id).Error
+	return request, err
+}
+
+// UpdateMaintenanceRequestFields writes the given columns of a maintenance
+// request.
+func (r *WorkOrderRepository) UpdateMaintenanceRequestFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.MaintenanceRequest{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *WorkOrderRepository) GetVendor(id uint) (model.Vendor, error) {
+	var vendor model.Vendor
+	err := r.db.First(&vendor, id).Error
+	return vendor, err
+}
+
+func (r *WorkOrderRepository) CreateComment(comment *model.MaintenanceComment) error {
+	return r.db.Create(comment).Error
+}
+
+// CreateExpenseOnce stores an expense unless one has already been recorded
+// for its work order.
+func (r *WorkOrderRepository) CreateExpenseOnce(expense *model.Expense) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(expense).Error
+}
+
+// GetExpenses returns the expenses of a property dated from from to to
+// inclusive, in date order.
+func (r *WorkOrderRepository) GetExpenses(propertyID uint, from, to time.Time) ([]model.Expense, error) {
+	var expenses []model.Expense
+	err := r.db.Where("property_id = ? AND date >= ? AND date <= ?", propertyID, from, to).
+		Order("date, id").Find(&expenses).Error
+	return expenses, err
+}
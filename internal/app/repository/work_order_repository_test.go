@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+)
+
+func TestCreateExpenseOnce(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewWorkOrderRepository(db)
+	orderID := uint(7)
+	expense := func(amount int64) *model.Expense {
+		return &model.Expense{PropertyID: 1, Date: date("2026-03-02"), Category: "plumbing", Amount: decimal.NewFromInt(amount), WorkOrderID: &orderID}
+	}
+
+	if err := repo.CreateExpenseOnce(expense(720)); err != nil {
+		t.Fatal(err)
+	}
+	// Signing off the same work order again records nothing.
+	if err := repo.CreateExpenseOnce(expense(800)); err != nil {
+		t.Fatal(err)
+	}
+	// Expenses without a work order are not limited.
+	for i := 0; i < 2; i++ {
+		if err := repo.CreateExpenseOnce(&model.Expense{PropertyID: 1, Date: date("2026-03-02"), Category: "other", Amount: decimal.NewFromInt(50)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expenses, err := repo.GetExpenses(1, date("2026-03-01"), date("2026-03-31"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expenses) != 3 {
+		t.Fatalf("recorded %d expenses, want 3", len(expenses))
+	}
+	if !expenses[0].Amount.Equal(decimal.NewFromInt(720)) {
+		t.Errorf("work order expense = %s, want the first one recorded, 720", expenses[0].Amount)
+	}
+}
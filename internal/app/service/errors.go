@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+)
+
+// Domain errors returned by the services. Their codes are part of the API contract.
+var (
+	ErrPropertyNotFound             = apperror.NotFound("property_not_found", "Property not found.")
+	ErrPropertyVersionNotFound      = apperror.NotFound("property_version_not_found", "Property version not found.")
+	ErrImageNotFound                = apperror.NotFound("image_not_found", "Image not found.")
+	ErrVersionMismatch              = apperror.PreconditionFailed("version_mismatch", "Property has been modified since it was last read.")
+	ErrInvalidPatch                 = apperror.BadRequest("invalid_patch", "The patch document could not be applied.")
+	ErrUnsupportedPatch             = apperror.UnsupportedMediaType("unsupported_patch_type", "Content-Type must be "+MergePatchContentType+" or "+JSONPatchContentType+".")
+	ErrDeletedVersion               = apperror.Conflict("version_deleted", "Cannot restore a version in which the property is deleted.")
+	ErrInvalidCredentials           = apperror.Unauthorized("invalid_credentials", "Invalid username or password.")
+	ErrUnitNotFound                 = apperror.NotFound("unit_not_found", "Unit not found.")
+	ErrTenantNotFound               = apperror.NotFound("tenant_not_found", "Tenant not found.")
+	ErrDocumentNotFound             = apperror.NotFound("document_not_found", "Document not found.")
+	ErrUnitLeased                   = apperror.Conflict("unit_leased", "Units with a lease in force cannot be deleted.")
+	ErrUnitNumberTaken              = apperror.Conflict("unit_number_taken", "Another unit of this property already has that number.")
+	ErrLeaseNotFound                = apperror.NotFound("lease_not_found", "Lease not found.")
+	ErrLeaseNotDraft                = apperror.Conflict("lease_not_draft", "Only draft leases can be changed or deleted.")
+	ErrInvalidLeaseTransition       = apperror.Conflict("invalid_lease_transition", "The lease's current status does not allow that change.")
+	ErrLeaseOverlap                 = apperror.Conflict("lease_overlap", "Another lease in force covers the same unit for part of this term.")
+	ErrLeaseNotBillable             = apperror.Conflict("lease_not_billable", "Draft leases have no account until they are activated.")
+	ErrChargeNotFound               = apperror.NotFound("charge_not_found", "Charge not found.")
+	ErrNothingDue                   = apperror.Conflict("nothing_due", "Nothing is owed on this lease or charge.")
+	ErrPayoutNotFound               = apperror.NotFound("payout_not_found", "Payout not found.")
+	ErrRefundBeforePayment          = apperror.Conflict("refund_before_payment", "The refunded payment has not been recorded yet.")
+	ErrLeaseTermNotOver             = apperror.Conflict("lease_term_not_over", "The lease has not reached its end date; terminate it to end it early.")
+	ErrMaintenanceNotFound          = apperror.NotFound("maintenance_request_not_found", "Maintenance request not found.")
+	ErrMaintenanceClosed            = apperror.Conflict("maintenance_request_closed", "Closed maintenance requests cannot be changed.")
+	ErrMaintenanceHasWorkOrders     = apperror.Conflict("maintenance_request_has_work_orders", "Maintenance requests with work orders cannot be deleted; cancel or close them instead.")
+	ErrInvalidMaintenanceTransition = apperror.Conflict("invalid_maintenance_transition", "The maintenance request's current status does not allow that change.")
+	ErrVendorNotFound               = apperror.NotFound("vendor_not_found", "Vendor not found.")
+	ErrWorkOrderNotFound            = apperror.NotFound("work_order_not_found", "Work order not found.")
+	ErrInvalidWorkOrderTransition   = apperror.Conflict("invalid_work_order_transition", "The work order's current status does not allow that change.")
+	ErrSelfApproval                 = apperror.Forbidden("self_approval", "Work orders cannot be approved by the user who issued them.")
+	ErrOwnerNotFound                = apperror.NotFound("owner_not_found", "Owner not found.")
+	ErrAgreementNotFound            = apperror.NotFound("agreement_not_found", "Management agreement not found.")
+	ErrAgreementOverlap             = apperror.Conflict("agreement_overlap", "Another management agreement of this property covers part of that period.")
+	ErrInspectionTemplateNotFound   = apperror.NotFound("inspection_template_not_found", "Inspection template not found.")
+	ErrInspectionNotFound           = apperror.NotFound("inspection_not_found", "Inspection not found.")
+	ErrInspectionNotScheduled       = apperror.Conflict("inspection_not_scheduled", "Only scheduled inspections can be changed.")
+	ErrInspectionNotCompleted       = apperror.Conflict("inspection_not_completed", "The inspection has no results until it is completed.")
+	ErrBaselineNotFound             = apperror.NotFound("baseline_inspection_not_found", "No completed move-in inspection to compare with.")
+	ErrPhotoNotFound                = apperror.NotFound("photo_not_found", "Photo not found.")
+)
+
+// notFound translates a missing database record into the given domain error.
+func notFound(err error, domainErr *apperror.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr.Wrap(err)
+	}
+	return err
+}
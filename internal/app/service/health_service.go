@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+)
+
+// HealthCheck reports whether a dependency is reachable.
+type HealthCheck func(ctx context.Context) error
+
+// HealthService runs the readiness checks of the server's dependencies.
+type HealthService struct {
+	checks       map[string]HealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewHealthService returns a HealthService that gives each check up to timeout to complete.
+func NewHealthService(checks map[string]HealthCheck, timeout time.Duration) *HealthService {
+	return &HealthService{checks: checks, timeout: timeout}
+}
+
+// DatabaseCheck pings the connection pool behind db.
+func DatabaseCheck(db *gorm.DB) HealthCheck {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// SetShuttingDown marks the server as draining, so that it reports not-ready
+// regardless of its dependencies.
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Liveness reports that the process is up. It checks no dependencies, so that
+// an outage of the database or bucket does not get the process restarted.
+func (s *HealthService) Liveness() model.HealthReport {
+	return model.HealthReport{Status: model.HealthStatusOK}
+}
+
+// Readiness runs every check concurrently and reports the server ready only
+// if all of them pass and it is not shutting down.
+func (s *HealthService) Readiness(ctx context.Context) model.HealthReport {
+	report := model.HealthReport{
+		Status:       model.HealthStatusOK,
+		ShuttingDown: s.shuttingDown.Load(),
+		Checks:       make(map[string]model.DependencyHealth, len(s.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range s.checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			result := s.runCheck(ctx, name, check)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	if report.ShuttingDown {
+		report.Status = model.HealthStatusUnavailable
+	}
+	for _, result := range report.Checks {
+		if result.Status != model.HealthStatusUp {
+			report.Status = model.HealthStatusUnavailable
+		}
+	}
+	return report
+}
+
+func (s *HealthService) runCheck(ctx context.Context, name string, check HealthCheck) model.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := model.DependencyHealth{
+		Status:    model.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Health check failed", "dependency", name, "error", err)
+		// The endpoint is public, so report only that the check failed and
+		// leave the underlying error to the log.
+		result.Status = model.HealthStatusDown
+		if ctx.Err() != nil {
+			result.Error = "timed out"
+		} else {
+			result.Error = "unreachable"
+		}
+	}
+	return result
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+type InspectionService struct {
+	repo *repository.InspectionRepository
+}
+
+func NewInspectionService(repo *repository.InspectionRepository) *InspectionService {
+	return &InspectionService{repo: repo}
+}
+
+func (s *InspectionService) GetTemplates(ctx context.Context) ([]model.InspectionTemplate, error) {
+	return s.repo.WithContext(ctx).GetTemplates()
+}
+
+func (s *InspectionService) GetTemplate(ctx context.Context, id uint) (model.InspectionTemplate, error) {
+	template, err := s.repo.WithContext(ctx).GetTemplate(id)
+	return template, notFound(err, ErrInspectionTemplateNotFound)
+}
+
+// CreateTemplate stores a new inspection template, rated on the default
+// scale unless it has its own.
+func (s *InspectionService) CreateTemplate(ctx context.Context, template *model.InspectionTemplate) error {
+	if err := checkTemplate(template); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).CreateTemplate(template)
+}
+
+// UpdateTemplate overwrites an inspection template. Inspections already
+// scheduled from it are not affected. On success template holds the stored
+// result.
+func (s *InspectionService) UpdateTemplate(ctx context.Context, template *model.InspectionTemplate) error {
+	if err := checkTemplate(template); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.InspectionRepository) error {
+		updated, err := repo.UpdateTemplate(template)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrInspectionTemplateNotFound
+		}
+		stored, err := repo.GetTemplate(template.ID)
+		*template = stored
+		return err
+	})
+}
+
+func (s *InspectionService) DeleteTemplate(ctx context.Context, id uint) error {
+	deleted, err := s.repo.WithContext(ctx).DeleteTemplate(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrInspectionTemplateNotFound
+	}
+	return nil
+}
+
+// SearchInspections returns the inspections of a property matching filter.
+func (s *InspectionService) SearchInspections(ctx context.Context, filter repository.InspectionFilter) ([]model.Inspection, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireProperty(repo, filter.PropertyID); err != nil {
+		return nil, err
+	}
+	return repo.GetInspections(filter)
+}
+
+func (s *InspectionService) GetInspection(ctx context.Context, id uint) (model.Inspection, error) {
+	inspection, err := s.repo.WithContext(ctx).GetInspection(id)
+	return inspection, notFound(err, ErrInspectionNotFound)
+}
+
+// ScheduleInspection schedules an inspection with a copy of the checklist
+// and rating scale of its template. On success inspection holds the stored
+// result.
+func (s *InspectionService) ScheduleInspection(ctx context.Context, inspection *model.Inspection) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.InspectionRepository) error {
+		template, err := repo.GetTemplate(inspection.TemplateID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.Validation([]apperror.FieldError{{Field: "template_id", Reason: "does not exist"}})
+		}
+		if err != nil {
+			return err
+		}
+		if err := locateInspection(repo, inspection); err != nil {
+			return err
+		}
+
+		inspection.Status = model.InspectionStatusScheduled
+		inspection.Ratings = template.Ratings
+		inspection.Rooms = template.Rooms
+		if err := repo.CreateInspection(inspection); err != nil {
+			return err
+		}
+		created, err := repo.GetInspection(inspection.ID)
+		*inspection = created
+		return err
+	})
+}
+
+// RescheduleInspection moves a scheduled inspection to scheduledFor and
+// hands it to inspector.
+func (s *InspectionService) RescheduleInspection(ctx context.Context, id uint, scheduledFor time.Time, inspector string) (model.Inspection, error) {
+	return s.update(ctx, id, map[string]interface{}{"scheduled_for": scheduledFor, "inspector": inspector})
+}
+
+// CancelInspection cancels a scheduled inspection.
+func (s *InspectionService) CancelInspection(ctx context.Context, id uint) (model.Inspection, error) {
+	return s.update(ctx, id, map[string]interface{}{"status": model.InspectionStatusCancelled})
+}
+
+// SubmitInspection completes a scheduled inspection on behalf of actor with
+// a rating for every item of its checklist and any notes.
+func (s *InspectionService) SubmitInspection(ctx context.Context, id uint, notes string, results []model.InspectionResult, actor string) (model.Inspection, error) {
+	var inspection model.Inspection
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.InspectionRepository) error {
+		current, err := getScheduledInspection(repo, id)
+		if err != nil {
+			return err
+		}
+		if err := checkResults(current, results); err != nil {
+			return err
+		}
+
+		if err := repo.CreateResults(results); err != nil {
+			return err
+		}
+		err = repo.UpdateInspectionFields(id, map[string]interface{}{
+			"status":       model.InspectionStatusCompleted,
+			"notes":        notes,
+			"completed_at": time.Now().UTC(),
+			"completed_by": actor,
+		})
+		if err != nil {
+			return err
+		}
+		inspection, err = repo.GetInspection(id)
+		return err
+	})
+	return inspection, err
+}
+
+// CheckPhoto checks that a photo can be added to an inspection, so that
+// the caller need not store a file that would be refused.
+func (s *InspectionService) CheckPhoto(ctx context.Context, photo model.InspectionPhoto) error {
+	return checkPhoto(s.repo.WithContext(ctx), photo)
+}
+
+// AddPhoto records a photo uploaded for a scheduled inspection.
+func (s *InspectionService) AddPhoto(ctx context.Context, photo *model.InspectionPhoto) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.InspectionRepository) error {
+		if err := checkPhoto(repo, *photo); err != nil {
+			return err
+		}
+		return repo.CreatePhoto(photo)
+	})
+}
+
+// GetPhoto returns a photo of an inspection, whatever its status.
+func (s *InspectionService) GetPhoto(ctx context.Context, inspectionID uint, photoID uint) (model.InspectionPhoto, error) {
+	photo, err := s.repo.WithContext(ctx).GetPhoto(inspectionID, photoID)
+	return photo, notFound(err, ErrPhotoNotFound)
+}
+
+// CheckDeletePhoto returns a photo of a scheduled inspection, so that the
+// caller can delete its stored file before DeletePhoto. The photos of
+// completed inspections are kept as evidence.
+func (s *InspectionService) CheckDeletePhoto(ctx context.Context, inspectionID uint, photoID uint) (model.InspectionPhoto, error) {
+	repo := s.repo.WithContext(ctx)
+	if _, err := getScheduledInspection(repo, inspectionID); err != nil {
+		return model.InspectionPhoto{}, err
+	}
+	photo, err := repo.GetPhoto(inspectionID, photoID)
+	return photo, notFound(err, ErrPhotoNotFound)
+}
+
+// DeletePhoto removes a photo of a scheduled inspection. The caller deletes
+// the stored file first.
+func (s *InspectionService) DeletePhoto(ctx context.Context, inspectionID uint, photoID uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.InspectionRepository) error {
+		if _, err := getScheduledInspection(repo, inspectionID); err != nil {
+			return err
+		}
+		deleted, err := repo.DeletePhoto(inspectionID, photoID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrPhotoNotFound
+		}
+		return nil
+	})
+}
+
+// GetComparison compares a completed inspection with the completed
+// inspection baselineID of the same property and unit or, if baselineID is
+// zero, with the latest move-in inspection there before it, preferring one
+// of the same lease.
+func (s *InspectionService) GetComparison(ctx context.Context, id uint, baselineID uint) (model.InspectionComparison, error) {
+	repo := s.repo.WithContext(ctx)
+	inspection, err := repo.GetInspection(id)
+	if err != nil {
+		return model.InspectionComparison{}, notFound(err, ErrInspectionNotFound)
+	}
+	if inspection.Status != model.InspectionStatusCompleted {
+		return model.InspectionComparison{}, ErrInspectionNotCompleted
+	}
+
+	if baselineID == 0 {
+		found, err := repo.FindBaseline(inspection, *inspection.CompletedAt)
+		if err != nil {
+			return model.InspectionComparison{}, err
+		}
+		if found.ID == 0 {
+			return model.InspectionComparison{}, ErrBaselineNotFound
+		}
+		baselineID = found.ID
+	}
+
+	baseline, err := repo.GetInspection(baselineID)
+	var reason string
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		reason = "does not exist"
+	case err != nil:
+		return model.InspectionComparison{}, err
+	case baseline.ID == inspection.ID:
+		reason = "must be another inspection"
+	case baseline.Status != model.InspectionStatusCompleted:
+		reason = "must be a completed inspection"
+	case baseline.PropertyID != inspection.PropertyID || !sameUnit(baseline.UnitID, inspection.UnitID):
+		reason = "must be an inspection of the same property and unit"
+	}
+	if reason != "" {
+		return model.InspectionComparison{}, apperror.Validation([]apperror.FieldError{{Field: "baseline_id", Reason: reason}})
+	}
+	return model.NewInspectionComparison(baseline, inspection), nil
+}
+
+// Place names where an inspection takes place, by property name and unit
+// number, for reports.
+func (s *InspectionService) Place(ctx context.Context, inspection model.Inspection) (string, error) {
+	property, unit, err := s.repo.WithContext(ctx).GetPlace(inspection.PropertyID, inspection.UnitID)
+	if err != nil {
+		return "", err
+	}
+	if unit == nil {
+		return property.Name, nil
+	}
+	return property.Name + ", unit " + unit.Number, nil
+}
+
+// update writes fields of a scheduled inspection and returns the result.
+func (s *InspectionService) update(ctx context.Context, id uint, fields map[string]interface{}) (model.Inspection, error) {
+	var inspection model.Inspection
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.InspectionRepository) error {
+		if _, err := getScheduledInspection(repo, id); err != nil {
+			return err
+		}
+		if err := repo.UpdateInspectionFields(id, fields); err != nil {
+			return err
+		}
+		var err error
+		inspection, err = repo.GetInspection(id)
+		return err
+	})
+	return inspection, err
+}
+
+func getScheduledInspection(repo *repository.InspectionRepository, id uint) (model.Inspection, error) {
+	inspection, err := repo.GetInspection(id)
+	if err != nil {
+		return inspection, notFound(err, ErrInspectionNotFound)
+	}
+	if inspection.Status != model.InspectionStatusScheduled {
+		return inspection, ErrInspectionNotScheduled
+	}
+	return inspection, nil
+}
+
+// checkTemplate rejects a template that lists a rating, room or item more
+// than once, and gives it the default scale if it has none.
+func checkTemplate(template *model.InspectionTemplate) error {
+	var fields []apperror.FieldError
+	ratings := map[string]bool{}
+	for i, rating := range template.Ratings {
+		if ratings[rating] {
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("ratings[%d]", i), Reason: "is listed more than once"})
+		}
+		ratings[rating] = true
+	}
+	rooms := map[string]bool{}
+	for i, room := range template.Rooms {
+		if rooms[room.Name] {
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("rooms[%d].name", i), Reason: "is listed more than once"})
+		}
+		rooms[room.Name] = true
+		items := map[string]bool{}
+		for j, item := range room.Items {
+			if items[item] {
+				fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("rooms[%d].items[%d]", i, j), Reason: "is listed more than once"})
+			}
+			items[item] = true
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+
+	if len(template.Ratings) == 0 {
+		template.Ratings = append([]string(nil), model.DefaultConditionRatings...)
+	}
+	return nil
+}
+
+// checkResults checks that results rate every item of the inspection's
+// checklist exactly once on its scale.
+func checkResults(inspection model.Inspection, results []model.InspectionResult) error {
+	var fields []apperror.FieldError
+	rated := map[[2]string]bool{}
+	for i, result := range results {
+		key := [2]string{result.Room, result.Item}
+		switch {
+		case !inspection.HasItem(result.Room, result.Item):
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("results[%d]", i), Reason: "is not an item of the inspection's checklist"})
+		case rated[key]:
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("results[%d]", i), Reason: "rates an item already rated"})
+		case inspection.RatingRank(result.Rating) < 0:
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("results[%d].rating", i), Reason: "must be one of: " + strings.Join(inspection.Ratings, ", ")})
+		}
+		rated[key] = true
+	}
+	for _, room := range inspection.Rooms {
+		for _, item := range room.Items {
+			if !rated[[2]string{room.Name, item}] {
+				fields = append(fields, apperror.FieldError{Field: "results", Reason: fmt.Sprintf("has no rating for %s: %s", room.Name, item)})
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+// checkPhoto checks that the inspection of a photo is scheduled and that
+// the room and item the photo shows, if any, are on its checklist.
+func checkPhoto(repo *repository.InspectionRepository, photo model.InspectionPhoto) error {
+	inspection, err := getScheduledInspection(repo, photo.InspectionID)
+	if err != nil {
+		return err
+	}
+
+	var fields []apperror.FieldError
+	switch {
+	case photo.Room == "" && photo.Item != "":
+		fields = append(fields, apperror.FieldError{Field: "room", Reason: "is required when item is given"})
+	case photo.Room != "" && !inspection.HasRoom(photo.Room):
+		fields = append(fields, apperror.FieldError{Field: "room", Reason: "is not a room of the inspection's checklist"})
+	case photo.Item != "" && !inspection.HasItem(photo.Room, photo.Item):
+		fields = append(fields, apperror.FieldError{Field: "item", Reason: "is not an item of the room in the inspection's checklist"})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+// locateInspection checks the property, unit and lease of an inspection. A
+// lease implies its unit and property, which are filled in when omitted.
+func locateInspection(repo *repository.InspectionRepository, inspection *model.Inspection) error {
+	var fields []apperror.FieldError
+	if inspection.LeaseID != nil {
+		lease, err := repo.GetLease(*inspection.LeaseID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			fields = append(fields, apperror.FieldError{Field: "lease_id", Reason: "does not exist"})
+		case err != nil:
+			return err
+		case inspection.UnitID != nil && !sameUnit(inspection.UnitID, lease.UnitID):
+			fields = append(fields, apperror.FieldError{Field: "lease_id", Reason: "does not belong to unit_id"})
+		case inspection.PropertyID != 0 && inspection.PropertyID != lease.PropertyID:
+			fields = append(fields, apperror.FieldError{Field: "lease_id", Reason: "does not belong to property_id"})
+		default:
+			inspection.UnitID = lease.UnitID
+			inspection.PropertyID = lease.PropertyID
+		}
+	}
+
+	located, err := locate(repo, &inspection.PropertyID, inspection.UnitID)
+	if err != nil {
+		return err
+	}
+	fields = append(fields, located...)
+	if inspection.PropertyID == 0 && inspection.UnitID == nil && inspection.LeaseID == nil {
+		fields = append(fields, apperror.FieldError{Field: "property_id", Reason: "is required unless unit_id or lease_id is given"})
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+func sameUnit(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
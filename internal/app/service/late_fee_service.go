@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/config"
+)
+
+// LateFeeService charges late fees on overdue rent and reports on
+// delinquent leases.
+type LateFeeService struct {
+	repo *repository.LedgerRepository
+	cfg  *config.LateFeeConfig
+}
+
+func NewLateFeeService(repo *repository.LedgerRepository, cfg *config.LateFeeConfig) *LateFeeService {
+	return &LateFeeService{repo: repo, cfg: cfg}
+}
+
+// AssessLateFees charges the configured late fees, up to and including day,
+// on the rent charges of every lease other than a draft that were still
+// unpaid at the end of their grace period, and returns the number of fees
+// posted. A charge is late from the day after its grace period if it was
+// not fully paid or credited by then. Each fee is posted once, so running
+// it again for the same day posts nothing.
+func (s *LateFeeService) AssessLateFees(ctx context.Context, day time.Time) (int, error) {
+	if s.cfg.Rule == config.LateFeeRuleNone {
+		return 0, nil
+	}
+
+	repo := s.repo.WithContext(ctx)
+	leases, err := repo.GetBilledLeases(0)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, lease := range leases {
+		entries, err := repo.GetEntries(lease.ID, &day)
+		if err != nil {
+			return posted, err
+		}
+
+		charged := map[uint]decimal.Decimal{}
+		for _, entry := range entries {
+			if entry.Category == model.ChargeCategoryLateFee && entry.ChargeID != nil {
+				charged[*entry.ChargeID] = charged[*entry.ChargeID].Add(entry.Amount)
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.Type != model.LedgerEntryCharge || entry.Category != model.ChargeCategoryRent {
+				continue
+			}
+			for _, fee := range s.lateFees(entry, entries, charged[entry.ID], day) {
+				created, err := repo.CreateEntryOnce(&fee)
+				if err != nil {
+					return posted, err
+				}
+				if created {
+					posted++
+					slog.InfoContext(ctx, "Posted late fee", "lease_id", lease.ID, "charge_id", entry.ID, "date", fee.Date.Format(time.DateOnly), "amount", fee.Amount.String())
+				}
+			}
+		}
+	}
+	return posted, nil
+}
+
+// lateFees returns the fees due on a rent charge up to and including day
+// under the configured rule, given the lease's entries and the fees already
+// charged on it. Fees already posted are returned again with the same
+// posting key, so that storing them has no effect.
+func (s *LateFeeService) lateFees(charge model.LedgerEntry, entries []model.LedgerEntry, alreadyCharged decimal.Decimal, day time.Time) []model.LedgerEntry {
+	lastGraceDay := charge.Date.AddDate(0, 0, s.cfg.GraceDays)
+	firstLateDay := lastGraceDay.AddDate(0, 0, 1)
+	if firstLateDay.After(day) {
+		return nil
+	}
+	unpaid := unpaidOn(charge, entries, lastGraceDay)
+	if !unpaid.IsPositive() {
+		return nil
+	}
+
+	fee := func(date time.Time, amount decimal.Decimal, key string, description string) model.LedgerEntry {
+		return model.LedgerEntry{
+			LeaseID:     charge.LeaseID,
+			Type:        model.LedgerEntryCharge,
+			Category:    model.ChargeCategoryLateFee,
+			Date:        date,
+			Description: description,
+			Amount:      amount,
+			CreatedBy:   SystemActor,
+			ChargeID:    &charge.ID,
+			PostingKey:  &key,
+		}
+	}
+	dueDate := charge.Date.Format(time.DateOnly)
+
+	switch s.cfg.Rule {
+	case config.LateFeeRuleFlat:
+		if !s.cfg.Amount.IsPositive() {
+			return nil
+		}
+		key := fmt.Sprintf("late_fee:%d", charge.ID)
+		return []model.LedgerEntry{fee(firstLateDay, s.cfg.Amount, key, "Late fee on rent due "+dueDate)}
+
+	case config.LateFeeRulePercentage:
+		amount := unpaid.Mul(s.cfg.Percent).Div(decimal.NewFromInt(100)).Round(2)
+		if !amount.IsPositive() {
+			return nil
+		}
+		key := fmt.Sprintf("late_fee:%d", charge.ID)
+		description := fmt.Sprintf("Late fee of %s%% on rent due %s", s.cfg.Percent, dueDate)
+		return []model.LedgerEntry{fee(firstLateDay, amount, key, description)}
+
+	case config.LateFeeRuleDaily:
+		// Once the cap has been reached there is nothing left to post.
+		if alreadyCharged.GreaterThanOrEqual(s.cfg.Cap) {
+			return nil
+		}
+		var fees []model.LedgerEntry
+		total := decimal.Zero
+		for date := firstLateDay; !date.After(day); date = date.AddDate(0, 0, 1) {
+			if date.After(firstLateDay) && !unpaidOn(charge, entries, date.AddDate(0, 0, -1)).IsPositive() {
+				break
+			}
+			amount := decimal.Min(s.cfg.Amount, s.cfg.Cap.Sub(total))
+			if !amount.IsPositive() {
+				break
+			}
+			total = total.Add(amount)
+			key := fmt.Sprintf("late_fee:%d:%s", charge.ID, date.Format(time.DateOnly))
+			fees = append(fees, fee(date, amount, key, "Daily late fee on rent due "+dueDate))
+		}
+		return fees
+	}
+	return nil
+}
+
+// unpaidOn returns how much of charge was still unpaid at the end of day.
+func unpaidOn(charge model.LedgerEntry, entries []model.LedgerEntry, day time.Time) decimal.Decimal {
+	for _, outstanding := range model.OutstandingCharges(entries, day) {
+		if outstanding.Charge.ID == charge.ID {
+			return outstanding.Unpaid
+		}
+	}
+	return decimal.Zero
+}
+
+// GetDelinquencyReport returns the leases other than drafts with charges
+// unpaid at the end of day, only those of a property if propertyID is not
+// zero, with the unpaid amounts aged by days past due.
+func (s *LateFeeService) GetDelinquencyReport(ctx context.Context, day time.Time, propertyID uint) (model.DelinquencyReport, error) {
+	repo := s.repo.WithContext(ctx)
+	leases, err := repo.GetBilledLeases(propertyID)
+	if err != nil {
+		return model.DelinquencyReport{}, err
+	}
+
+	report := model.DelinquencyReport{AsOf: day, GeneratedAt: time.Now(), Leases: []model.DelinquentLease{}}
+	for _, lease := range leases {
+		entries, err := repo.GetEntries(lease.ID, &day)
+		if err != nil {
+			return model.DelinquencyReport{}, err
+		}
+		outstanding := model.OutstandingCharges(entries, day)
+		if len(outstanding) == 0 {
+			continue
+		}
+
+		delinquent := model.DelinquentLease{
+			LeaseID:       lease.ID,
+			PropertyID:    lease.PropertyID,
+			UnitID:        lease.UnitID,
+			Status:        lease.Status,
+			Tenants:       make([]string, 0, len(lease.Tenants)),
+			OldestDueDate: outstanding[0].Charge.Date,
+		}
+		for _, tenant := range lease.Tenants {
+			delinquent.Tenants = append(delinquent.Tenants, tenant.FirstName+" "+tenant.LastName)
+		}
+		for _, charge := range outstanding {
+			daysPastDue := int(day.Sub(charge.Charge.Date).Hours() / 24)
+			delinquent.Add(charge.Unpaid, daysPastDue)
+			report.Totals.Add(charge.Unpaid, daysPastDue)
+		}
+		report.Leases = append(report.Leases, delinquent)
+	}
+
+	sort.SliceStable(report.Leases, func(i, j int) bool {
+		return report.Leases[i].OldestDueDate.Before(report.Leases[j].OldestDueDate)
+	})
+	return report, nil
+}
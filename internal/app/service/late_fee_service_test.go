@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/config"
+)
+
+// newLedgerFixture returns an active lease with the given entries posted to it.
+func newLedgerFixture(t *testing.T, entries ...model.LedgerEntry) (*gorm.DB, model.Lease) {
+	t.Helper()
+	db := newTestDB(t)
+	if err := db.Create(&model.Property{Name: "Elm"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	lease := model.Lease{
+		PropertyID: 1,
+		Status:     model.LeaseStatusActive,
+		StartDate:  date("2026-01-01"),
+		EndDate:    date("2026-12-31"),
+		Rent:       decimal.NewFromInt(1000),
+		BillingDay: 1,
+	}
+	if err := db.Create(&lease).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		entry.LeaseID = lease.ID
+		if err := db.Create(&entry).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, lease
+}
+
+func rentDue(day string, amount int64) model.LedgerEntry {
+	return model.LedgerEntry{Type: model.LedgerEntryCharge, Category: model.ChargeCategoryRent, Date: date(day), Amount: decimal.NewFromInt(amount)}
+}
+
+func paid(day string, amount int64) model.LedgerEntry {
+	return model.LedgerEntry{Type: model.LedgerEntryPayment, Date: date(day), Amount: decimal.NewFromInt(amount)}
+}
+
+// TestAssessLateFees assesses the late fees on rent of 1000 due on March 1
+// under each rule, running the job twice for the same day, and checks the
+// fees posted by the first run and that the second posts nothing.
+func TestAssessLateFees(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.LateFeeConfig
+		payments []model.LedgerEntry
+		day      string
+		want     []string
+	}{
+		{
+			name: "no rule",
+			cfg:  config.LateFeeConfig{Rule: config.LateFeeRuleNone, GraceDays: 5, Amount: decimal.NewFromInt(50)},
+			day:  "2026-03-31",
+		},
+		{
+			name: "flat on the last grace day",
+			cfg:  config.LateFeeConfig{Rule: config.LateFeeRuleFlat, GraceDays: 5, Amount: decimal.NewFromInt(50)},
+			day:  "2026-03-06",
+		},
+		{
+			name: "flat after the grace period",
+			cfg:  config.LateFeeConfig{Rule: config.LateFeeRuleFlat, GraceDays: 5, Amount: decimal.NewFromInt(50)},
+			day:  "2026-03-20",
+			want: []string{"2026-03-07 50"},
+		},
+		{
+			name:     "flat paid within the grace period",
+			cfg:      config.LateFeeConfig{Rule: config.LateFeeRuleFlat, GraceDays: 5, Amount: decimal.NewFromInt(50)},
+			payments: []model.LedgerEntry{paid("2026-03-06", 1000)},
+			day:      "2026-03-20",
+		},
+		{
+			name:     "percentage of the unpaid rent",
+			cfg:      config.LateFeeConfig{Rule: config.LateFeeRulePercentage, GraceDays: 5, Percent: decimal.NewFromInt(5)},
+			payments: []model.LedgerEntry{paid("2026-03-03", 400)},
+			day:      "2026-03-20",
+			want:     []string{"2026-03-07 30"},
+		},
+		{
+			name: "daily up to the cap",
+			cfg:  config.LateFeeConfig{Rule: config.LateFeeRuleDaily, GraceDays: 0, Amount: decimal.NewFromInt(10), Cap: decimal.NewFromInt(35)},
+			day:  "2026-03-20",
+			want: []string{"2026-03-02 10", "2026-03-03 10", "2026-03-04 10", "2026-03-05 5"},
+		},
+		{
+			name:     "daily until paid",
+			cfg:      config.LateFeeConfig{Rule: config.LateFeeRuleDaily, GraceDays: 0, Amount: decimal.NewFromInt(10), Cap: decimal.NewFromInt(100)},
+			payments: []model.LedgerEntry{paid("2026-03-03", 1000)},
+			day:      "2026-03-20",
+			want:     []string{"2026-03-02 10", "2026-03-03 10"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, lease := newLedgerFixture(t, append([]model.LedgerEntry{rentDue("2026-03-01", 1000)}, tt.payments...)...)
+			cfg := tt.cfg
+			fees := NewLateFeeService(repository.NewLedgerRepository(db), &cfg)
+
+			for run, want := range []int{len(tt.want), 0} {
+				posted, err := fees.AssessLateFees(ctx, date(tt.day))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if posted != want {
+					t.Errorf("run %d posted %d fees, want %d", run+1, posted, want)
+				}
+			}
+
+			var entries []model.LedgerEntry
+			err := db.Where("lease_id = ? AND category = ?", lease.ID, model.ChargeCategoryLateFee).Order("date").Find(&entries).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Date.Format(time.DateOnly)+" "+entry.Amount.String())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("late fees = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("late fees = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestDelinquencyReportAging ages unpaid rent by days past due, after a
+// payment has been applied to the oldest charge.
+func TestDelinquencyReportAging(t *testing.T) {
+	db, lease := newLedgerFixture(t,
+		rentDue("2026-01-01", 1000),
+		rentDue("2026-02-01", 1000),
+		rentDue("2026-03-01", 1000),
+		rentDue("2026-04-01", 1000),
+		rentDue("2026-05-01", 1000),
+		paid("2026-01-05", 1250),
+		rentDue("2026-06-01", 1000),
+	)
+	cfg := config.LateFeeConfig{Rule: config.LateFeeRuleNone}
+	fees := NewLateFeeService(repository.NewLedgerRepository(db), &cfg)
+
+	report, err := fees.GetDelinquencyReport(context.Background(), date("2026-05-15"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Leases) != 1 || report.Leases[0].LeaseID != lease.ID {
+		t.Fatalf("delinquent leases = %+v, want lease %d", report.Leases, lease.ID)
+	}
+	delinquent := report.Leases[0]
+	if !delinquent.OldestDueDate.Equal(date("2026-02-01")) {
+		t.Errorf("oldest due date = %s, want 2026-02-01", delinquent.OldestDueDate.Format(time.DateOnly))
+	}
+
+	// The June rent is not yet due, and the payment settles January's rent
+	// and a quarter of February's.
+	want := map[string]int64{"0-30": 1000, "31-60": 1000, "61-90": 1000, "over 90": 750, "total": 3750}
+	for name, buckets := range map[string]model.AgingBuckets{"lease": delinquent.AgingBuckets, "report totals": report.Totals} {
+		got := map[string]decimal.Decimal{
+			"0-30":    buckets.Days0To30,
+			"31-60":   buckets.Days31To60,
+			"61-90":   buckets.Days61To90,
+			"over 90": buckets.DaysOver90,
+			"total":   buckets.TotalUnpaid,
+		}
+		for bucket, amount := range want {
+			if !got[bucket].Equal(decimal.NewFromInt(amount)) {
+				t.Errorf("%s: %s days = %s, want %d", name, bucket, got[bucket], amount)
+			}
+		}
+	}
+}
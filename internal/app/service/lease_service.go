@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+type LeaseService struct {
+	repo *repository.LeaseRepository
+}
+
+func NewLeaseService(repo *repository.LeaseRepository) *LeaseService {
+	return &LeaseService{repo: repo}
+}
+
+// SearchLeases returns the leases matching filter.
+func (s *LeaseService) SearchLeases(ctx context.Context, filter repository.LeaseFilter) ([]model.Lease, error) {
+	return s.repo.WithContext(ctx).GetLeases(filter)
+}
+
+func (s *LeaseService) GetLease(ctx context.Context, id uint) (model.Lease, error) {
+	lease, err := s.repo.WithContext(ctx).GetLease(id)
+	return lease, notFound(err, ErrLeaseNotFound)
+}
+
+// CreateLease stores a new draft lease. On success lease holds the stored result.
+func (s *LeaseService) CreateLease(ctx context.Context, lease *model.Lease) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		if err := checkLease(repo, lease); err != nil {
+			return err
+		}
+		if err := repo.CreateLease(lease); err != nil {
+			return err
+		}
+		created, err := repo.GetLease(lease.ID)
+		*lease = created
+		return err
+	})
+}
+
+// UpdateLease replaces the terms and tenants of a draft lease. On success
+// lease holds the stored result.
+func (s *LeaseService) UpdateLease(ctx context.Context, lease *model.Lease) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		existing, err := repo.GetLease(lease.ID)
+		if err != nil {
+			return notFound(err, ErrLeaseNotFound)
+		}
+		if existing.Status != model.LeaseStatusDraft {
+			return ErrLeaseNotDraft
+		}
+		if err := checkLease(repo, lease); err != nil {
+			return err
+		}
+		if err := repo.UpdateLease(lease); err != nil {
+			return err
+		}
+		updated, err := repo.GetLease(lease.ID)
+		*lease = updated
+		return err
+	})
+}
+
+// DeleteLease deletes a draft lease. Deleting a draft renewal returns the
+// lease it would have renewed to active.
+func (s *LeaseService) DeleteLease(ctx context.Context, id uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		lease, err := repo.GetLease(id)
+		if err != nil {
+			return notFound(err, ErrLeaseNotFound)
+		}
+		if lease.Status != model.LeaseStatusDraft {
+			return ErrLeaseNotDraft
+		}
+		if lease.RenewalOfID != nil {
+			previous, err := repo.GetLease(*lease.RenewalOfID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil && previous.Status == model.LeaseStatusRenewing {
+				if err := repo.UpdateLeaseFields(previous.ID, map[string]interface{}{"status": model.LeaseStatusActive}); err != nil {
+					return err
+				}
+			}
+		}
+		return repo.DeleteLease(id)
+	})
+}
+
+// ActivateLease puts a draft lease in force once no other lease in force
+// overlaps it. The lease an activated renewal renews stays renewing, and
+// billed, until EndRenewedLeases ends it after its end date. Activating a
+// renewing lease abandons its draft renewal instead.
+func (s *LeaseService) ActivateLease(ctx context.Context, id uint) (model.Lease, error) {
+	var lease model.Lease
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		var err error
+		lease, err = repo.GetLease(id)
+		if err != nil {
+			return notFound(err, ErrLeaseNotFound)
+		}
+		if !lease.CanTransition(model.LeaseStatusActive) {
+			return ErrInvalidLeaseTransition
+		}
+
+		if lease.Status == model.LeaseStatusRenewing {
+			renewed, err := repo.HasRenewalInForce(id)
+			if err != nil {
+				return err
+			}
+			if renewed {
+				return ErrInvalidLeaseTransition
+			}
+			if err := repo.DeleteDraftRenewals(id); err != nil {
+				return err
+			}
+		} else if lease.RenewalOfID != nil {
+			previous, err := repo.GetLease(*lease.RenewalOfID)
+			if err != nil {
+				return notFound(err, ErrLeaseNotFound)
+			}
+			if previous.Status != model.LeaseStatusRenewing {
+				return ErrInvalidLeaseTransition
+			}
+		}
+
+		overlaps, err := repo.HasOverlappingLease(lease)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return ErrLeaseOverlap
+		}
+
+		if err := repo.UpdateLeaseFields(id, map[string]interface{}{"status": model.LeaseStatusActive}); err != nil {
+			return err
+		}
+		lease, err = repo.GetLease(id)
+		return err
+	})
+	return lease, err
+}
+
+// RenewLease drafts a renewal of an active lease, starting the day after it
+// ends, for the same property, unit and tenants, and marks the lease as
+// renewing. terms holds the renewal's end date, rent, deposit, billing day
+// and terms. It returns the draft renewal.
+func (s *LeaseService) RenewLease(ctx context.Context, id uint, terms model.Lease) (model.Lease, error) {
+	var renewal model.Lease
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		lease, err := repo.GetLease(id)
+		if err != nil {
+			return notFound(err, ErrLeaseNotFound)
+		}
+		if lease.Status != model.LeaseStatusActive || !lease.CanTransition(model.LeaseStatusRenewing) {
+			return ErrInvalidLeaseTransition
+		}
+
+		renewal = terms
+		renewal.ID = 0
+		renewal.Status = model.LeaseStatusDraft
+		renewal.PropertyID = lease.PropertyID
+		renewal.UnitID = lease.UnitID
+		renewal.StartDate = lease.EndDate.AddDate(0, 0, 1)
+		renewal.RenewalOfID = &lease.ID
+		renewal.Tenants = lease.Tenants
+		fields := leaseAmountFields(renewal)
+		if !renewal.EndDate.After(renewal.StartDate) {
+			fields = append(fields, apperror.FieldError{Field: "end_date", Reason: "must be after the end date of the lease being renewed"})
+		}
+		if len(fields) > 0 {
+			return apperror.Validation(fields)
+		}
+
+		if err := repo.CreateLease(&renewal); err != nil {
+			return err
+		}
+		if err := repo.UpdateLeaseFields(id, map[string]interface{}{"status": model.LeaseStatusRenewing}); err != nil {
+			return err
+		}
+		renewal, err = repo.GetLease(renewal.ID)
+		return err
+	})
+	return renewal, err
+}
+
+// EndLease records that a lease has run its term. Any draft renewal of it
+// is abandoned.
+func (s *LeaseService) EndLease(ctx context.Context, id uint) (model.Lease, error) {
+	var lease model.Lease
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		var err error
+		lease, err = repo.GetLease(id)
+		if err != nil {
+			return notFound(err, ErrLeaseNotFound)
+		}
+		if !lease.CanTransition(model.LeaseStatusEnded) {
+			return ErrInvalidLeaseTransition
+		}
+		if lease.EndDate.After(today()) {
+			return ErrLeaseTermNotOver
+		}
+
+		if err := repo.DeleteDraftRenewals(id); err != nil {
+			return err
+		}
+		if err := repo.UpdateLeaseFields(id, map[string]interface{}{"status": model.LeaseStatusEnded}); err != nil {
+			return err
+		}
+		lease, err = repo.GetLease(id)
+		return err
+	})
+	return lease, err
+}
+
+// EndRenewedLeases ends the leases whose renewal is in force once their end
+// date is before day, and returns how many it ended.
+func (s *LeaseService) EndRenewedLeases(ctx context.Context, day time.Time) (int, error) {
+	ended, err := s.repo.WithContext(ctx).EndRenewedLeases(day)
+	return int(ended), err
+}
+
+// TerminateLease ends a lease early, making lastDay its end date. Any draft
+// renewal of it is abandoned.
+func (s *LeaseService) TerminateLease(ctx context.Context, id uint, lastDay time.Time, reason string) (model.Lease, error) {
+	var lease model.Lease
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.LeaseRepository) error {
+		var err error
+		lease, err = repo.GetLease(id)
+		if err != nil {
+			return notFound(err, ErrLeaseNotFound)
+		}
+		if !lease.CanTransition(model.LeaseStatusTerminated) {
+			return ErrInvalidLeaseTransition
+		}
+		if lastDay.Before(lease.StartDate) || lastDay.After(lease.EndDate) {
+			return apperror.Validation([]apperror.FieldError{{Field: "date", Reason: "must be within the lease term"}})
+		}
+
+		if err := repo.DeleteDraftRenewals(id); err != nil {
+			return err
+		}
+		err = repo.UpdateLeaseFields(id, map[string]interface{}{
+			"status":             model.LeaseStatusTerminated,
+			"end_date":           lastDay,
+			"terminated_at":      time.Now(),
+			"termination_reason": reason,
+		})
+		if err != nil {
+			return err
+		}
+		lease, err = repo.GetLease(id)
+		return err
+	})
+	return lease, err
+}
+
+// checkLease checks a lease's dates and that its property, unit and tenants
+// exist.
+func checkLease(repo *repository.LeaseRepository, lease *model.Lease) error {
+	fields := leaseAmountFields(*lease)
+	if !lease.EndDate.After(lease.StartDate) {
+		fields = append(fields, apperror.FieldError{Field: "end_date", Reason: "must be after start_date"})
+	}
+
+	located, err := locate(repo, &lease.PropertyID, lease.UnitID)
+	if err != nil {
+		return err
+	}
+	fields = append(fields, located...)
+	if lease.PropertyID == 0 && lease.UnitID == nil {
+		fields = append(fields, apperror.FieldError{Field: "property_id", Reason: "is required unless unit_id is given"})
+	}
+
+	ids := make([]uint, 0, len(lease.Tenants))
+	seen := map[uint]bool{}
+	for _, tenant := range lease.Tenants {
+		if !seen[tenant.ID] {
+			seen[tenant.ID] = true
+			ids = append(ids, tenant.ID)
+		}
+	}
+	count, err := repo.CountTenants(ids)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		fields = append(fields, apperror.FieldError{Field: "tenant_ids", Reason: "must all be existing tenants"})
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+// maxRent is the largest rent or deposit of a unit or lease.
+var maxRent = decimal.NewFromInt(10000000)
+
+// rentReason returns why amount is not a valid rent or deposit, or "" if it
+// is. Zero is valid only if allowZero is set.
+func rentReason(amount decimal.Decimal, allowZero bool) string {
+	if amount.GreaterThan(maxRent) {
+		return "must be less than or equal to " + maxRent.String()
+	}
+	return amountReason(amount, allowZero)
+}
+
+// leaseAmountFields checks a lease's rent and deposit.
+func leaseAmountFields(lease model.Lease) []apperror.FieldError {
+	var fields []apperror.FieldError
+	if reason := rentReason(lease.Rent, false); reason != "" {
+		fields = append(fields, apperror.FieldError{Field: "rent", Reason: reason})
+	}
+	if reason := rentReason(lease.Deposit, true); reason != "" {
+		fields = append(fields, apperror.FieldError{Field: "deposit", Reason: reason})
+	}
+	return fields
+}
+
+// today returns the current calendar day in UTC at midnight.
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
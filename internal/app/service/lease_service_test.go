@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+// newTestDB returns an empty database with the schema of the models used
+// by service tests.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&model.Property{}, &model.Image{}, &model.PropertyVersion{}, &model.Unit{}, &model.UnitImage{}, &model.Tenant{}, &model.Lease{}, &model.LedgerEntry{},
+		&model.MaintenanceRequest{}, &model.MaintenanceComment{}, &model.MaintenancePhoto{},
+		&model.Vendor{}, &model.WorkOrder{}, &model.Expense{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func date(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// TestRenewalActivatedEarlyKeepsBillingPreviousLease activates a renewal
+// months before the lease it renews is over, and checks that the lease is
+// billed, and stays in force, until its end date.
+func TestRenewalActivatedEarlyKeepsBillingPreviousLease(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if err := db.Create(&model.Property{Name: "Elm"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	lease := model.Lease{
+		PropertyID: 1,
+		Status:     model.LeaseStatusActive,
+		StartDate:  date("2026-01-01"),
+		EndDate:    date("2026-06-30"),
+		Rent:       decimal.NewFromInt(1000),
+		BillingDay: 1,
+	}
+	if err := db.Create(&lease).Error; err != nil {
+		t.Fatal(err)
+	}
+	leases := NewLeaseService(repository.NewLeaseRepository(db))
+	ledger := NewLedgerService(repository.NewLedgerRepository(db))
+
+	renewal, err := leases.RenewLease(ctx, lease.ID, model.Lease{EndDate: date("2027-06-30"), Rent: decimal.NewFromInt(1100), BillingDay: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leases.ActivateLease(ctx, renewal.ID); err != nil {
+		t.Fatal(err)
+	}
+	previous, err := leases.GetLease(ctx, lease.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous.Status != model.LeaseStatusRenewing {
+		t.Fatalf("renewed lease status = %s, want %s", previous.Status, model.LeaseStatusRenewing)
+	}
+	if _, err := leases.ActivateLease(ctx, lease.ID); err != ErrInvalidLeaseTransition {
+		t.Errorf("reactivating the renewed lease: error = %v, want %v", err, ErrInvalidLeaseTransition)
+	}
+
+	// The daily job on the last billing date of the original term.
+	if _, err := ledger.PostDueRent(ctx, date("2026-06-01")); err != nil {
+		t.Fatal(err)
+	}
+	if ended, err := leases.EndRenewedLeases(ctx, date("2026-06-01")); err != nil || ended != 0 {
+		t.Fatalf("EndRenewedLeases before the end date = %d, %v, want 0", ended, err)
+	}
+	var charges int64
+	if err := db.Model(&model.LedgerEntry{}).Where("lease_id = ?", lease.ID).Count(&charges).Error; err != nil {
+		t.Fatal(err)
+	}
+	if charges != 6 {
+		t.Errorf("renewed lease was charged %d times, want 6", charges)
+	}
+
+	// The daily job once the original term is over.
+	if _, err := ledger.PostDueRent(ctx, date("2026-07-01")); err != nil {
+		t.Fatal(err)
+	}
+	if ended, err := leases.EndRenewedLeases(ctx, date("2026-07-01")); err != nil || ended != 1 {
+		t.Fatalf("EndRenewedLeases after the end date = %d, %v, want 1", ended, err)
+	}
+	previous, err = leases.GetLease(ctx, lease.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous.Status != model.LeaseStatusEnded {
+		t.Errorf("renewed lease status = %s, want %s", previous.Status, model.LeaseStatusEnded)
+	}
+	if err := db.Model(&model.LedgerEntry{}).Where("lease_id = ?", renewal.ID).Count(&charges).Error; err != nil {
+		t.Fatal(err)
+	}
+	if charges != 1 {
+		t.Errorf("renewal was charged %d times, want 1", charges)
+	}
+}
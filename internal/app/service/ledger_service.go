@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+// SystemActor is recorded as the creator of entries posted automatically.
+const SystemActor = "system"
+
+type LedgerService struct {
+	repo *repository.LedgerRepository
+}
+
+func NewLedgerService(repo *repository.LedgerRepository) *LedgerService {
+	return &LedgerService{repo: repo}
+}
+
+// GetLedger returns the account of a lease.
+func (s *LedgerService) GetLedger(ctx context.Context, leaseID uint) (model.Ledger, error) {
+	repo := s.repo.WithContext(ctx)
+	if _, err := repo.GetLease(leaseID); err != nil {
+		return model.Ledger{}, notFound(err, ErrLeaseNotFound)
+	}
+
+	entries, err := repo.GetEntries(leaseID, nil)
+	if err != nil {
+		return model.Ledger{}, err
+	}
+	return model.NewLedger(leaseID, decimal.Zero, entries), nil
+}
+
+// PostEntry adds a charge, payment or credit to the account of a lease
+// other than a draft on behalf of actor. On success entry holds the stored
+// result.
+func (s *LedgerService) PostEntry(ctx context.Context, entry *model.LedgerEntry, actor string) error {
+	if entry.Amount.Sign() <= 0 {
+		return apperror.Validation([]apperror.FieldError{{Field: "amount", Reason: "must be greater than 0"}})
+	}
+	if !entry.Amount.Equal(entry.Amount.Round(2)) {
+		return apperror.Validation([]apperror.FieldError{{Field: "amount", Reason: "must have at most 2 decimal places"}})
+	}
+
+	repo := s.repo.WithContext(ctx)
+	lease, err := repo.GetLease(entry.LeaseID)
+	if err != nil {
+		return notFound(err, ErrLeaseNotFound)
+	}
+	if lease.Status == model.LeaseStatusDraft {
+		return ErrLeaseNotBillable
+	}
+	entry.CreatedBy = actor
+	return repo.CreateEntry(entry)
+}
+
+// PostDueRent charges every lease in force its rent for each billing date
+// from its start up to and including day that has not been charged yet, and
+// returns the number of charges posted. Running it again for the same day
+// posts nothing. Partial periods are not prorated; charge them separately.
+func (s *LedgerService) PostDueRent(ctx context.Context, day time.Time) (int, error) {
+	repo := s.repo.WithContext(ctx)
+	leases, err := repo.GetLeasesInForce(day)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, lease := range leases {
+		rent := lease.Rent
+		for _, billingDate := range billingDates(lease, day) {
+			key := "rent:" + billingDate.Format(time.DateOnly)
+			created, err := repo.CreateEntryOnce(&model.LedgerEntry{
+				LeaseID:     lease.ID,
+				Type:        model.LedgerEntryCharge,
+				Category:    model.ChargeCategoryRent,
+				Date:        billingDate,
+				Description: fmt.Sprintf("Rent for the period starting %s", billingDate.Format(time.DateOnly)),
+				Amount:      rent,
+				CreatedBy:   SystemActor,
+				PostingKey:  &key,
+			})
+			if err != nil {
+				return posted, err
+			}
+			if created {
+				posted++
+				slog.InfoContext(ctx, "Posted rent charge", "lease_id", lease.ID, "date", billingDate.Format(time.DateOnly), "amount", rent.String())
+			}
+		}
+	}
+	return posted, nil
+}
+
+// GetStatement returns a tenant's statement for the days from from to to,
+// inclusive, covering all of their leases other than drafts.
+func (s *LedgerService) GetStatement(ctx context.Context, tenantID uint, from, to time.Time) (model.Statement, error) {
+	repo := s.repo.WithContext(ctx)
+	tenant, err := repo.GetTenant(tenantID)
+	if err != nil {
+		return model.Statement{}, notFound(err, ErrTenantNotFound)
+	}
+
+	leases, err := repo.GetTenantLeases(tenantID)
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	statement := model.Statement{
+		TenantID:    tenant.ID,
+		TenantName:  tenant.FirstName + " " + tenant.LastName,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		Leases:      []model.LeaseStatement{},
+	}
+	for _, lease := range leases {
+		entries, err := repo.GetEntries(lease.ID, &to)
+		if err != nil {
+			return model.Statement{}, err
+		}
+
+		opening := decimal.Zero
+		inPeriod := []model.LedgerEntry{}
+		for _, entry := range entries {
+			if entry.Date.Before(from) {
+				opening = opening.Add(entry.SignedAmount())
+			} else {
+				inPeriod = append(inPeriod, entry)
+			}
+		}
+
+		ledger := model.NewLedger(lease.ID, opening, inPeriod)
+		statement.Leases = append(statement.Leases, model.LeaseStatement{
+			LeaseID:        lease.ID,
+			PropertyID:     lease.PropertyID,
+			UnitID:         lease.UnitID,
+			OpeningBalance: opening,
+			Entries:        ledger.Entries,
+			TotalCharges:   ledger.TotalCharges,
+			TotalPayments:  ledger.TotalPayments,
+			TotalCredits:   ledger.TotalCredits,
+			ClosingBalance: ledger.Balance,
+		})
+		statement.AmountDue = statement.AmountDue.Add(ledger.Balance)
+	}
+	return statement, nil
+}
+
+// billingDates returns the lease's billing dates within its term, up to and
+// including day.
+func billingDates(lease model.Lease, day time.Time) []time.Time {
+	var dates []time.Time
+	year, month, _ := lease.StartDate.Date()
+	for i := 0; ; i++ {
+		// time.Date normalises months past December into later years.
+		date := time.Date(year, month+time.Month(i), lease.BillingDay, 0, 0, 0, 0, time.UTC)
+		if date.After(lease.EndDate) || date.After(day) {
+			return dates
+		}
+		if !date.Before(lease.StartDate) {
+			dates = append(dates, date)
+		}
+	}
+}
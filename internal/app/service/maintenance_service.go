@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/config"
+)
+
+type MaintenanceService struct {
+	repo *repository.MaintenanceRepository
+	cfg  *config.MaintenanceConfig
+}
+
+func NewMaintenanceService(repo *repository.MaintenanceRepository, cfg *config.MaintenanceConfig) *MaintenanceService {
+	return &MaintenanceService{repo: repo, cfg: cfg}
+}
+
+// SearchRequests returns the maintenance requests of a property matching
+// filter.
+func (s *MaintenanceService) SearchRequests(ctx context.Context, filter repository.MaintenanceFilter) ([]model.MaintenanceRequest, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireProperty(repo, filter.PropertyID); err != nil {
+		return nil, err
+	}
+
+	requests, err := repo.GetRequests(filter)
+	now := time.Now()
+	for i := range requests {
+		requests[i].Overdue = requests[i].IsOverdue(now)
+	}
+	return requests, err
+}
+
+func (s *MaintenanceService) GetRequest(ctx context.Context, id uint) (model.MaintenanceRequest, error) {
+	return getMaintenanceRequest(s.repo.WithContext(ctx), id)
+}
+
+// CreateRequest reports a new maintenance request, due to be resolved
+// within the service level for its priority. On success request holds the
+// stored result.
+func (s *MaintenanceService) CreateRequest(ctx context.Context, request *model.MaintenanceRequest) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		if err := locateRequest(repo, request); err != nil {
+			return err
+		}
+		request.Status = model.MaintenanceStatusOpen
+		request.DueAt = time.Now().UTC().Add(s.cfg.SLA(request.Priority))
+		if err := repo.CreateRequest(request); err != nil {
+			return err
+		}
+		created, err := getMaintenanceRequest(repo, request.ID)
+		*request = created
+		return err
+	})
+}
+
+// UpdateRequest overwrites the details of a maintenance request that is
+// not closed. A change of priority moves the due date to the service level
+// of the new priority, counted from when the request was reported. On
+// success request holds the stored result.
+func (s *MaintenanceService) UpdateRequest(ctx context.Context, request *model.MaintenanceRequest) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		current, err := repo.GetRequest(request.ID)
+		if err != nil {
+			return notFound(err, ErrMaintenanceNotFound)
+		}
+		if current.Status == model.MaintenanceStatusClosed {
+			return ErrMaintenanceClosed
+		}
+		if err := locateRequest(repo, request); err != nil {
+			return err
+		}
+
+		fields := map[string]interface{}{
+			"property_id": request.PropertyID,
+			"unit_id":     request.UnitID,
+			"tenant_id":   request.TenantID,
+			"category":    request.Category,
+			"priority":    request.Priority,
+			"description": request.Description,
+		}
+		if request.Priority != current.Priority {
+			fields["due_at"] = current.CreatedAt.UTC().Add(s.cfg.SLA(request.Priority))
+		}
+		if err := repo.UpdateRequestFields(request.ID, fields); err != nil {
+			return err
+		}
+		updated, err := getMaintenanceRequest(repo, request.ID)
+		*request = updated
+		return err
+	})
+}
+
+// CheckDeleteRequest returns a maintenance request that may be deleted,
+// with its photos, so that the caller can delete their stored files before
+// DeleteRequest. Requests that work orders have been issued for are kept.
+func (s *MaintenanceService) CheckDeleteRequest(ctx context.Context, id uint) (model.MaintenanceRequest, error) {
+	repo := s.repo.WithContext(ctx)
+	request, err := getMaintenanceRequest(repo, id)
+	if err != nil {
+		return request, err
+	}
+	return request, requireNoWorkOrders(repo, id)
+}
+
+// DeleteRequest removes a maintenance request without work orders and its
+// photos. The caller deletes the stored files first.
+func (s *MaintenanceService) DeleteRequest(ctx context.Context, id uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		if err := requireNoWorkOrders(repo, id); err != nil {
+			return err
+		}
+		deleted, err := repo.DeleteRequest(id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrMaintenanceNotFound
+		}
+		return nil
+	})
+}
+
+// AssignRequest assigns a maintenance request that is not closed to
+// assignee, or unassigns it if assignee is empty, on behalf of actor. An
+// open request becomes assigned, and an assigned one open again when
+// unassigned. The change is recorded in the comments with note, if any.
+func (s *MaintenanceService) AssignRequest(ctx context.Context, id uint, assignee string, note string, actor string) (model.MaintenanceRequest, error) {
+	var request model.MaintenanceRequest
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		current, err := repo.GetRequest(id)
+		if err != nil {
+			return notFound(err, ErrMaintenanceNotFound)
+		}
+		if current.Status == model.MaintenanceStatusClosed {
+			return ErrMaintenanceClosed
+		}
+
+		fields := map[string]interface{}{"assignee": assignee}
+		body := "Assigned to " + assignee + "."
+		switch {
+		case assignee == "":
+			body = "Unassigned."
+			if current.Status == model.MaintenanceStatusAssigned {
+				fields["status"] = model.MaintenanceStatusOpen
+			}
+		case current.Status == model.MaintenanceStatusOpen:
+			fields["status"] = model.MaintenanceStatusAssigned
+		}
+		if err := repo.UpdateRequestFields(id, fields); err != nil {
+			return err
+		}
+		if err := addEventComment(repo, id, actor, body, note); err != nil {
+			return err
+		}
+		request, err = getMaintenanceRequest(repo, id)
+		return err
+	})
+	return request, err
+}
+
+// ChangeStatus moves a maintenance request to status on behalf of actor,
+// recording the change in the comments with note, if any. Only an assigned
+// request may be worked on, and moving a request back to open unassigns it.
+func (s *MaintenanceService) ChangeStatus(ctx context.Context, id uint, status string, note string, actor string) (model.MaintenanceRequest, error) {
+	var request model.MaintenanceRequest
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		current, err := repo.GetRequest(id)
+		if err != nil {
+			return notFound(err, ErrMaintenanceNotFound)
+		}
+		if !current.CanTransition(status) {
+			return ErrInvalidMaintenanceTransition
+		}
+		if status == model.MaintenanceStatusAssigned && current.Assignee == "" {
+			return apperror.Validation([]apperror.FieldError{{Field: "status", Reason: "needs an assignee; assign the request instead"}})
+		}
+
+		now := time.Now().UTC()
+		fields := map[string]interface{}{"status": status}
+		switch status {
+		case model.MaintenanceStatusOpen:
+			fields["assignee"] = ""
+		case model.MaintenanceStatusInProgress:
+			fields["resolved_at"] = nil
+		case model.MaintenanceStatusResolved:
+			fields["resolved_at"] = now
+		case model.MaintenanceStatusClosed:
+			fields["closed_at"] = now
+		}
+		if err := repo.UpdateRequestFields(id, fields); err != nil {
+			return err
+		}
+		body := fmt.Sprintf("Status changed from %s to %s.", current.Status, status)
+		if err := addEventComment(repo, id, actor, body, note); err != nil {
+			return err
+		}
+		request, err = getMaintenanceRequest(repo, id)
+		return err
+	})
+	return request, err
+}
+
+// AddComment adds a comment by actor to a maintenance request that is not
+// closed.
+func (s *MaintenanceService) AddComment(ctx context.Context, id uint, body string, actor string) (model.MaintenanceComment, error) {
+	comment := model.MaintenanceComment{RequestID: id, Author: actor, Body: body}
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		if err := requireOpenRequest(repo, id); err != nil {
+			return err
+		}
+		return repo.CreateComment(&comment)
+	})
+	return comment, err
+}
+
+// AddPhoto records a photo uploaded for a maintenance request that is not
+// closed.
+func (s *MaintenanceService) AddPhoto(ctx context.Context, photo *model.MaintenancePhoto) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		if err := requireOpenRequest(repo, photo.RequestID); err != nil {
+			return err
+		}
+		return repo.CreatePhoto(photo)
+	})
+}
+
+func (s *MaintenanceService) GetPhoto(ctx context.Context, requestID uint, photoID uint) (model.MaintenancePhoto, error) {
+	photo, err := s.repo.WithContext(ctx).GetPhoto(requestID, photoID)
+	return photo, notFound(err, ErrPhotoNotFound)
+}
+
+// CheckDeletePhoto returns a photo of a maintenance request that is not
+// closed, so that the caller can delete its stored file before DeletePhoto.
+func (s *MaintenanceService) CheckDeletePhoto(ctx context.Context, requestID uint, photoID uint) (model.MaintenancePhoto, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireOpenRequest(repo, requestID); err != nil {
+		return model.MaintenancePhoto{}, err
+	}
+	photo, err := repo.GetPhoto(requestID, photoID)
+	return photo, notFound(err, ErrPhotoNotFound)
+}
+
+// DeletePhoto removes a photo of a maintenance request that is not closed.
+// The caller deletes the stored file first.
+func (s *MaintenanceService) DeletePhoto(ctx context.Context, requestID uint, photoID uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.MaintenanceRepository) error {
+		if err := requireOpenRequest(repo, requestID); err != nil {
+			return err
+		}
+		deleted, err := repo.DeletePhoto(requestID, photoID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrPhotoNotFound
+		}
+		return nil
+	})
+}
+
+func getMaintenanceRequest(repo *repository.MaintenanceRepository, id uint) (model.MaintenanceRequest, error) {
+	request, err := repo.GetRequest(id)
+	if err != nil {
+		return request, notFound(err, ErrMaintenanceNotFound)
+	}
+	request.Overdue = request.IsOverdue(time.Now())
+	return request, nil
+}
+
+// requireOpenRequest checks that a maintenance request exists and is not
+// closed.
+func requireOpenRequest(repo *repository.MaintenanceRepository, id uint) error {
+	request, err := repo.GetRequest(id)
+	if err != nil {
+		return notFound(err, ErrMaintenanceNotFound)
+	}
+	if request.Status == model.MaintenanceStatusClosed {
+		return ErrMaintenanceClosed
+	}
+	return nil
+}
+
+// requireNoWorkOrders returns ErrMaintenanceHasWorkOrders if work orders
+// have been issued for a maintenance request.
+func requireNoWorkOrders(repo *repository.MaintenanceRepository, id uint) error {
+	count, err := repo.CountWorkOrders(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrMaintenanceHasWorkOrders
+	}
+	return nil
+}
+
+// addEventComment records a status change or assignment, followed by the
+// note given with it, if any.
+func addEventComment(repo *repository.MaintenanceRepository, id uint, actor string, body string, note string) error {
+	if note != "" {
+		body += "\n\n" + note
+	}
+	return repo.CreateComment(&model.MaintenanceComment{RequestID: id, Author: actor, Body: body})
+}
+
+// locateRequest checks the property, unit and reporting tenant of a
+// maintenance request.
+func locateRequest(repo *repository.MaintenanceRepository, request *model.MaintenanceRequest) error {
+	fields, err := locate(repo, &request.PropertyID, request.UnitID)
+	if err != nil {
+		return err
+	}
+	if request.PropertyID == 0 && request.UnitID == nil {
+		fields = append(fields, apperror.FieldError{Field: "property_id", Reason: "is required unless unit_id is given"})
+	}
+
+	if request.TenantID != nil {
+		exists, err := repo.TenantExists(*request.TenantID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			fields = append(fields, apperror.FieldError{Field: "tenant_id", Reason: "does not exist"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
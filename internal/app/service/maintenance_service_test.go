@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/config"
+)
+
+func TestMaintenanceDueDates(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	if err := db.Create(&model.Property{Name: "Elm"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.MaintenanceConfig{SLAEmergency: 4 * time.Hour, SLAHigh: 24 * time.Hour, SLANormal: 72 * time.Hour, SLALow: 168 * time.Hour}
+	maintenance := NewMaintenanceService(repository.NewMaintenanceRepository(db), cfg)
+
+	before := time.Now().UTC()
+	request := model.MaintenanceRequest{PropertyID: 1, Category: "plumbing", Priority: model.MaintenancePriorityHigh, Description: "Leak"}
+	if err := maintenance.CreateRequest(ctx, &request); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().UTC()
+	if request.DueAt.Before(before.Add(24*time.Hour)) || request.DueAt.After(after.Add(24*time.Hour)) {
+		t.Errorf("DueAt = %v, want 24h after it was reported, between %v and %v", request.DueAt, before, after)
+	}
+
+	// A change of priority counts the new service level from when the
+	// request was reported.
+	request.Priority = model.MaintenancePriorityEmergency
+	if err := maintenance.UpdateRequest(ctx, &request); err != nil {
+		t.Fatal(err)
+	}
+	if want := request.CreatedAt.UTC().Add(4 * time.Hour); !request.DueAt.Equal(want) {
+		t.Errorf("DueAt after raising the priority = %v, want %v", request.DueAt, want)
+	}
+
+	// Other changes keep the due date.
+	due := request.DueAt
+	request.Description = "Burst pipe"
+	if err := maintenance.UpdateRequest(ctx, &request); err != nil {
+		t.Fatal(err)
+	}
+	if !request.DueAt.Equal(due) {
+		t.Errorf("DueAt after editing the description = %v, want %v", request.DueAt, due)
+	}
+}
+
+func TestClosedRequestPhotosAreKept(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	for _, record := range []interface{}{
+		&model.Property{Name: "Elm"},
+		&model.MaintenanceRequest{PropertyID: 1, Category: "plumbing", Priority: model.MaintenancePriorityLow, Description: "Leak", Status: model.MaintenanceStatusClosed},
+		&model.MaintenancePhoto{RequestID: 1, Name: "leak.jpg", Key: "leak.jpg"},
+	} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	maintenance := NewMaintenanceService(repository.NewMaintenanceRepository(db), &config.MaintenanceConfig{})
+
+	if _, err := maintenance.CheckDeletePhoto(ctx, 1, 1); !errors.Is(err, ErrMaintenanceClosed) {
+		t.Errorf("CheckDeletePhoto: err = %v, want ErrMaintenanceClosed", err)
+	}
+	if err := maintenance.DeletePhoto(ctx, 1, 1); !errors.Is(err, ErrMaintenanceClosed) {
+		t.Errorf("DeletePhoto: err = %v, want ErrMaintenanceClosed", err)
+	}
+	if _, err := maintenance.GetPhoto(ctx, 1, 1); err != nil {
+		t.Errorf("photo of the closed request: %v", err)
+	}
+}
+
+func TestDeleteRequestWithWorkOrdersIsRefused(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	for _, record := range []interface{}{
+		&model.Property{Name: "Elm"},
+		&model.MaintenanceRequest{PropertyID: 1, Category: "plumbing", Priority: model.MaintenancePriorityLow, Description: "Leak", Status: model.MaintenanceStatusOpen},
+		&model.MaintenanceRequest{PropertyID: 1, Category: "pest", Priority: model.MaintenancePriorityLow, Description: "Mice", Status: model.MaintenanceStatusOpen},
+		&model.MaintenancePhoto{RequestID: 2, Name: "mice.jpg", Key: "mice.jpg"},
+		&model.WorkOrder{MaintenanceRequestID: 1, PropertyID: 1, VendorID: 1, Description: "Fix leak", Status: model.WorkOrderStatusApproved},
+	} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	maintenance := NewMaintenanceService(repository.NewMaintenanceRepository(db), &config.MaintenanceConfig{})
+
+	if _, err := maintenance.CheckDeleteRequest(ctx, 1); !errors.Is(err, ErrMaintenanceHasWorkOrders) {
+		t.Errorf("CheckDeleteRequest: err = %v, want ErrMaintenanceHasWorkOrders", err)
+	}
+	if err := maintenance.DeleteRequest(ctx, 1); !errors.Is(err, ErrMaintenanceHasWorkOrders) {
+		t.Errorf("DeleteRequest: err = %v, want ErrMaintenanceHasWorkOrders", err)
+	}
+
+	request, err := maintenance.CheckDeleteRequest(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(request.Photos) != 1 {
+		t.Fatalf("photos to delete = %d, want 1", len(request.Photos))
+	}
+	if err := maintenance.DeleteRequest(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := maintenance.GetPhoto(ctx, 2, request.Photos[0].ID); !errors.Is(err, ErrPhotoNotFound) {
+		t.Errorf("photo of the deleted request: err = %v, want ErrPhotoNotFound", err)
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+type OwnerService struct {
+	repo *repository.OwnerRepository
+}
+
+func NewOwnerService(repo *repository.OwnerRepository) *OwnerService {
+	return &OwnerService{repo: repo}
+}
+
+// SearchOwners returns the owners, only those whose name or email address
+// contains query if it is not empty.
+func (s *OwnerService) SearchOwners(ctx context.Context, query string) ([]model.Owner, error) {
+	return s.repo.WithContext(ctx).GetOwners(query)
+}
+
+func (s *OwnerService) GetOwner(ctx context.Context, id uint) (model.Owner, error) {
+	owner, err := s.repo.WithContext(ctx).GetOwner(id)
+	return owner, notFound(err, ErrOwnerNotFound)
+}
+
+// CreateOwner stores a new owner. On success owner holds the stored result.
+func (s *OwnerService) CreateOwner(ctx context.Context, owner *model.Owner) error {
+	if err := s.repo.WithContext(ctx).CreateOwner(owner); err != nil {
+		return err
+	}
+	owner.Ownerships = []model.Ownership{}
+	return nil
+}
+
+// UpdateOwner overwrites the owner's details. On success owner holds the
+// stored result.
+func (s *OwnerService) UpdateOwner(ctx context.Context, owner *model.Owner) error {
+	repo := s.repo.WithContext(ctx)
+	updated, err := repo.UpdateOwner(owner)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrOwnerNotFound
+	}
+	*owner, err = repo.GetOwner(owner.ID)
+	return err
+}
+
+// DeleteOwner deletes an owner and their shares of properties.
+func (s *OwnerService) DeleteOwner(ctx context.Context, id uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.OwnerRepository) error {
+		deleted, err := repo.DeleteOwner(id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrOwnerNotFound
+		}
+		return nil
+	})
+}
+
+// GetPropertyOwners returns the shares of a property with their owners.
+func (s *OwnerService) GetPropertyOwners(ctx context.Context, propertyID uint) ([]model.Ownership, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireProperty(repo, propertyID); err != nil {
+		return nil, err
+	}
+	return repo.GetOwnerships(propertyID)
+}
+
+// SetPropertyOwners replaces the shares of a property, which must be of
+// distinct existing owners, positive, and add up to at most 100 percent.
+func (s *OwnerService) SetPropertyOwners(ctx context.Context, propertyID uint, ownerships []model.Ownership) ([]model.Ownership, error) {
+	var stored []model.Ownership
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.OwnerRepository) error {
+		if err := requireProperty(repo, propertyID); err != nil {
+			return err
+		}
+
+		var fields []apperror.FieldError
+		total := decimal.Zero
+		ids := make([]uint, 0, len(ownerships))
+		seen := map[uint]bool{}
+		for i, ownership := range ownerships {
+			if seen[ownership.OwnerID] {
+				fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("owners[%d].owner_id", i), Reason: "is listed more than once"})
+			}
+			seen[ownership.OwnerID] = true
+			ids = append(ids, ownership.OwnerID)
+			if !ownership.Percent.IsPositive() || ownership.Percent.GreaterThan(decimal.NewFromInt(100)) || !ownership.Percent.Equal(ownership.Percent.Round(2)) {
+				fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("owners[%d].percent", i), Reason: "must be greater than 0 and at most 100, with at most 2 decimal places"})
+			}
+			total = total.Add(ownership.Percent)
+		}
+		if total.GreaterThan(decimal.NewFromInt(100)) {
+			fields = append(fields, apperror.FieldError{Field: "owners", Reason: "percentages must add up to at most 100, not " + total.String()})
+		}
+		if len(ids) > 0 {
+			count, err := repo.CountOwners(ids)
+			if err != nil {
+				return err
+			}
+			if count != int64(len(seen)) {
+				fields = append(fields, apperror.FieldError{Field: "owners", Reason: "must all be existing owners"})
+			}
+		}
+		if len(fields) > 0 {
+			return apperror.Validation(fields)
+		}
+
+		if err := repo.ReplaceOwnerships(propertyID, ownerships); err != nil {
+			return err
+		}
+		var err error
+		stored, err = repo.GetOwnerships(propertyID)
+		return err
+	})
+	return stored, err
+}
+
+// GetAgreements returns the management agreements of a property.
+func (s *OwnerService) GetAgreements(ctx context.Context, propertyID uint) ([]model.ManagementAgreement, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireProperty(repo, propertyID); err != nil {
+		return nil, err
+	}
+	return repo.GetAgreements(propertyID)
+}
+
+// CreateAgreement stores a management agreement for a property that does
+// not overlap its other agreements. On success agreement holds the stored
+// result.
+func (s *OwnerService) CreateAgreement(ctx context.Context, agreement *model.ManagementAgreement) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.OwnerRepository) error {
+		if err := requireProperty(repo, agreement.PropertyID); err != nil {
+			return err
+		}
+		if err := checkAgreement(repo, *agreement); err != nil {
+			return err
+		}
+		return repo.CreateAgreement(agreement)
+	})
+}
+
+// UpdateAgreement overwrites a management agreement of a property. On
+// success agreement holds the stored result.
+func (s *OwnerService) UpdateAgreement(ctx context.Context, agreement *model.ManagementAgreement) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.OwnerRepository) error {
+		if _, err := repo.GetAgreement(agreement.PropertyID, agreement.ID); err != nil {
+			return notFound(err, ErrAgreementNotFound)
+		}
+		if err := checkAgreement(repo, *agreement); err != nil {
+			return err
+		}
+		if err := repo.UpdateAgreement(agreement); err != nil {
+			return err
+		}
+		updated, err := repo.GetAgreement(agreement.PropertyID, agreement.ID)
+		*agreement = updated
+		return err
+	})
+}
+
+func (s *OwnerService) DeleteAgreement(ctx context.Context, propertyID uint, id uint) error {
+	deleted, err := s.repo.WithContext(ctx).DeleteAgreement(propertyID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAgreementNotFound
+	}
+	return nil
+}
+
+// GetOwnerStatement accounts to an owner for the month starting on month:
+// for each property they own a share of, the payments received from its
+// leases, its expenses and the fee under the management agreement in force
+// during the month, charged in full even if the agreement starts or ends
+// within it.
+func (s *OwnerService) GetOwnerStatement(ctx context.Context, ownerID uint, month time.Time) (model.OwnerStatement, error) {
+	repo := s.repo.WithContext(ctx)
+	owner, err := repo.GetOwner(ownerID)
+	if err != nil {
+		return model.OwnerStatement{}, notFound(err, ErrOwnerNotFound)
+	}
+
+	from := month
+	to := month.AddDate(0, 1, -1)
+	statement := model.OwnerStatement{
+		OwnerID:    owner.ID,
+		OwnerName:  owner.Name,
+		Month:      month.Format("2006-01"),
+		From:       from,
+		To:         to,
+		Properties: []model.OwnerPropertyStatement{},
+	}
+	for _, ownership := range owner.Ownerships {
+		property, err := repo.GetProperty(ownership.PropertyID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The property has been deleted since.
+			continue
+		}
+		if err != nil {
+			return model.OwnerStatement{}, err
+		}
+		payments, err := repo.GetPropertyPayments(property.ID, from, to)
+		if err != nil {
+			return model.OwnerStatement{}, err
+		}
+		expenses, err := repo.GetExpenses(property.ID, from, to)
+		if err != nil {
+			return model.OwnerStatement{}, err
+		}
+		agreements, err := repo.GetAgreements(property.ID)
+		if err != nil {
+			return model.OwnerStatement{}, err
+		}
+		var agreement *model.ManagementAgreement
+		for i := range agreements {
+			if agreements[i].Overlaps(from, to) {
+				agreement = &agreements[i]
+				break
+			}
+		}
+		statement.Add(model.NewOwnerPropertyStatement(property, ownership.Percent, payments, expenses, agreement))
+	}
+	return statement, nil
+}
+
+// checkAgreement checks the terms of a management agreement and that it
+// does not overlap the property's other agreements.
+func checkAgreement(repo *repository.OwnerRepository, agreement model.ManagementAgreement) error {
+	var fields []apperror.FieldError
+	if agreement.EndDate != nil && agreement.EndDate.Before(agreement.StartDate) {
+		fields = append(fields, apperror.FieldError{Field: "end_date", Reason: "must not be before start_date"})
+	}
+	if agreement.FeePercent.IsNegative() || agreement.FeePercent.GreaterThan(decimal.NewFromInt(100)) || !agreement.FeePercent.Equal(agreement.FeePercent.Round(2)) {
+		fields = append(fields, apperror.FieldError{Field: "fee_percent", Reason: "must be between 0 and 100 with at most 2 decimal places"})
+	}
+	if reason := amountReason(agreement.FlatFee, true); reason != "" {
+		fields = append(fields, apperror.FieldError{Field: "flat_fee", Reason: reason})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+
+	end := time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if agreement.EndDate != nil {
+		end = *agreement.EndDate
+	}
+	others, err := repo.GetAgreements(agreement.PropertyID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != agreement.ID && other.Overlaps(agreement.StartDate, end) {
+			return ErrAgreementOverlap
+		}
+	}
+	return nil
+}
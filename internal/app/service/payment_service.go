@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/payments"
+)
+
+// PaymentService takes online payments towards leases through a payment
+// provider and records what the provider reports about them.
+type PaymentService struct {
+	repo     *repository.PaymentRepository
+	provider payments.Provider
+	currency string
+}
+
+// NewPaymentService returns a PaymentService that takes payments in
+// currency through provider.
+func NewPaymentService(repo *repository.PaymentRepository, provider payments.Provider, currency string) *PaymentService {
+	return &PaymentService{repo: repo, provider: provider, currency: currency}
+}
+
+// CreatePaymentIntent starts an online payment towards a lease other than
+// a draft. Without a charge it pays the lease's balance, and with one what
+// is unpaid of that charge, rounded down to the currency's minor unit;
+// amount, if not nil, pays part of it instead. Retrying the request before
+// the account changes returns the same payment intent. The returned payment
+// intent carries the client secret the payer needs.
+func (s *PaymentService) CreatePaymentIntent(ctx context.Context, leaseID uint, chargeID *uint, amount *decimal.Decimal) (model.PaymentIntent, error) {
+	repo := s.repo.WithContext(ctx)
+	lease, err := repo.GetLease(leaseID)
+	if err != nil {
+		return model.PaymentIntent{}, notFound(err, ErrLeaseNotFound)
+	}
+	if lease.Status == model.LeaseStatusDraft {
+		return model.PaymentIntent{}, ErrLeaseNotBillable
+	}
+
+	entries, err := repo.GetEntries(leaseID)
+	if err != nil {
+		return model.PaymentIntent{}, err
+	}
+	due, err := amountDue(entries, leaseID, chargeID)
+	if err != nil {
+		return model.PaymentIntent{}, err
+	}
+	places := payments.MinorUnitExponent(s.currency)
+	if places > 2 {
+		places = 2
+	}
+	due = due.RoundFloor(places)
+	if !due.IsPositive() {
+		return model.PaymentIntent{}, ErrNothingDue
+	}
+	if amount != nil {
+		var reason string
+		switch {
+		case !amount.IsPositive():
+			reason = "must be greater than 0"
+		case !amount.Equal(amount.Round(places)):
+			reason = fmt.Sprintf("must have at most %d decimal places", places)
+		case amount.GreaterThan(due):
+			reason = "must be at most the " + due.String() + " due"
+		}
+		if reason != "" {
+			return model.PaymentIntent{}, apperror.Validation([]apperror.FieldError{{Field: "amount", Reason: reason}})
+		}
+		due = *amount
+	}
+
+	metadata := map[string]string{"lease_id": strconv.FormatUint(uint64(leaseID), 10)}
+	description := fmt.Sprintf("Payment towards lease %d", leaseID)
+	if chargeID != nil {
+		metadata["charge_id"] = strconv.FormatUint(uint64(*chargeID), 10)
+		description = fmt.Sprintf("Payment of charge %d on lease %d", *chargeID, leaseID)
+	}
+	created, err := s.provider.CreatePaymentIntent(ctx, payments.PaymentIntentParams{
+		Amount:         due,
+		Currency:       s.currency,
+		Description:    description,
+		Metadata:       metadata,
+		IdempotencyKey: paymentIdempotencyKey(leaseID, chargeID, due, entries),
+	})
+	if err != nil {
+		return model.PaymentIntent{}, fmt.Errorf("creating payment intent: %w", err)
+	}
+
+	// A retried request gets back the payment intent recorded the first time.
+	intent, err := repo.GetPaymentIntentByProviderID(created.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		intent = model.PaymentIntent{
+			LeaseID:    leaseID,
+			ChargeID:   chargeID,
+			Provider:   s.provider.Name(),
+			ProviderID: created.ID,
+			Amount:     due,
+			Currency:   s.currency,
+			Status:     model.PaymentStatusPending,
+		}
+		err = repo.CreatePaymentIntent(&intent)
+	}
+	if err != nil {
+		return model.PaymentIntent{}, err
+	}
+	intent.ClientSecret = created.ClientSecret
+	return intent, nil
+}
+
+// paymentIdempotencyKey identifies a payment of amount towards a lease, or
+// one of its charges, while the lease's account holds entries. Payments
+// started after further entries are posted get a new key.
+func paymentIdempotencyKey(leaseID uint, chargeID *uint, amount decimal.Decimal, entries []model.LedgerEntry) string {
+	var latest uint
+	for _, entry := range entries {
+		if entry.ID > latest {
+			latest = entry.ID
+		}
+	}
+	charge := "balance"
+	if chargeID != nil {
+		charge = strconv.FormatUint(uint64(*chargeID), 10)
+	}
+	return fmt.Sprintf("lease-%d-charge-%s-amount-%s-entry-%d", leaseID, charge, amount.StringFixed(2), latest)
+}
+
+// amountDue returns what is unpaid of a lease's charge, or the lease's
+// balance if chargeID is nil, from the lease's entries.
+func amountDue(entries []model.LedgerEntry, leaseID uint, chargeID *uint) (decimal.Decimal, error) {
+	if chargeID == nil {
+		return model.NewLedger(leaseID, decimal.Zero, entries).Balance, nil
+	}
+	for _, entry := range entries {
+		if entry.ID == *chargeID && entry.Type == model.LedgerEntryCharge {
+			day := today()
+			if entry.Date.After(day) {
+				day = entry.Date
+			}
+			return unpaidOn(entry, entries, day), nil
+		}
+	}
+	return decimal.Zero, ErrChargeNotFound
+}
+
+func (s *PaymentService) GetPaymentIntents(ctx context.Context, leaseID uint) ([]model.PaymentIntent, error) {
+	repo := s.repo.WithContext(ctx)
+	if _, err := repo.GetLease(leaseID); err != nil {
+		return nil, notFound(err, ErrLeaseNotFound)
+	}
+	return repo.GetPaymentIntents(leaseID)
+}
+
+// HandleEvent records a webhook event from the payment provider: succeeded
+// payments are posted to the ledger, refunds are charged back, failures are
+// noted and payouts are reconciled. Redelivered events are ignored.
+func (s *PaymentService) HandleEvent(ctx context.Context, event payments.Event) error {
+	var settled []payments.PayoutPayment
+	if event.Type == payments.EventPayoutPaid {
+		var err error
+		if settled, err = s.provider.PayoutPayments(ctx, event.PayoutID); err != nil {
+			return fmt.Errorf("listing payout payments: %w", err)
+		}
+	}
+
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.PaymentRepository) error {
+		recorded, err := repo.RecordWebhookEvent(&model.WebhookEvent{Provider: s.provider.Name(), EventID: event.ID, Type: event.Type})
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			slog.InfoContext(ctx, "Ignored redelivered payment event", "event_id", event.ID)
+			return nil
+		}
+
+		switch event.Type {
+		case payments.EventPaymentSucceeded:
+			return s.paymentSucceeded(ctx, repo, event)
+		case payments.EventPaymentFailed:
+			return s.paymentFailed(ctx, repo, event)
+		case payments.EventPaymentRefunded:
+			return s.paymentRefunded(ctx, repo, event)
+		case payments.EventPayoutPaid:
+			return s.reconcilePayout(ctx, repo, event, settled)
+		}
+		return nil
+	})
+}
+
+// findPaymentIntent returns the payment intent an event is about. A
+// payment intent missing because it could not be stored when it was
+// created is recovered from the lease and charge in the event's metadata.
+func (s *PaymentService) findPaymentIntent(ctx context.Context, repo *repository.PaymentRepository, event payments.Event) (model.PaymentIntent, bool, error) {
+	intent, err := repo.GetPaymentIntentByProviderID(event.PaymentIntentID)
+	if err == nil {
+		return intent, true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return intent, false, err
+	}
+
+	leaseID, err := strconv.ParseUint(event.Metadata["lease_id"], 10, 64)
+	if err != nil {
+		slog.WarnContext(ctx, "Payment event for an unknown payment", "event_id", event.ID, "payment_intent", event.PaymentIntentID)
+		return intent, false, nil
+	}
+	if _, err := repo.GetLease(uint(leaseID)); err != nil {
+		slog.WarnContext(ctx, "Payment event for an unknown lease", "event_id", event.ID, "lease_id", leaseID)
+		return intent, false, nil
+	}
+	intent = model.PaymentIntent{
+		LeaseID:    uint(leaseID),
+		Provider:   s.provider.Name(),
+		ProviderID: event.PaymentIntentID,
+		Amount:     event.Amount,
+		Currency:   event.Currency,
+		Status:     model.PaymentStatusPending,
+	}
+	if chargeID, err := strconv.ParseUint(event.Metadata["charge_id"], 10, 64); err == nil {
+		id := uint(chargeID)
+		intent.ChargeID = &id
+	}
+	return intent, true, repo.CreatePaymentIntent(&intent)
+}
+
+func (s *PaymentService) paymentSucceeded(ctx context.Context, repo *repository.PaymentRepository, event payments.Event) error {
+	intent, ok, err := s.findPaymentIntent(ctx, repo, event)
+	if err != nil || !ok || intent.LedgerEntryID != nil {
+		return err
+	}
+
+	key := "payment:" + intent.ProviderID
+	entry := model.LedgerEntry{
+		LeaseID:     intent.LeaseID,
+		Type:        model.LedgerEntryPayment,
+		Date:        event.Created.Truncate(24 * time.Hour),
+		Description: "Online payment",
+		Amount:      event.Amount,
+		Reference:   intent.ProviderID,
+		CreatedBy:   s.provider.Name(),
+		PostingKey:  &key,
+	}
+	fields := map[string]interface{}{"status": model.PaymentStatusSucceeded, "amount": event.Amount, "failure_reason": ""}
+	created, err := repo.CreateEntryOnce(&entry)
+	if err != nil {
+		return err
+	}
+	if created {
+		fields["ledger_entry_id"] = entry.ID
+	}
+	slog.InfoContext(ctx, "Recorded online payment", "lease_id", intent.LeaseID, "payment_intent", intent.ProviderID, "amount", event.Amount.String())
+	return repo.UpdatePaymentIntentFields(intent.ID, fields)
+}
+
+func (s *PaymentService) paymentFailed(ctx context.Context, repo *repository.PaymentRepository, event payments.Event) error {
+	intent, ok, err := s.findPaymentIntent(ctx, repo, event)
+	if err != nil || !ok || intent.Status != model.PaymentStatusPending && intent.Status != model.PaymentStatusFailed {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Online payment failed", "lease_id", intent.LeaseID, "payment_intent", intent.ProviderID, "reason", event.FailureMessage)
+	return repo.UpdatePaymentIntentFields(intent.ID, map[string]interface{}{
+		"status":         model.PaymentStatusFailed,
+		"failure_reason": event.FailureMessage,
+	})
+}
+
+// paymentRefunded charges the lease what has been refunded of a payment
+// since the last refund event. event.Amount is the total refunded so far.
+// A refund that arrives before the payment succeeded is refused, so that
+// the provider delivers it again later.
+func (s *PaymentService) paymentRefunded(ctx context.Context, repo *repository.PaymentRepository, event payments.Event) error {
+	intent, ok, err := s.findPaymentIntent(ctx, repo, event)
+	if err != nil || !ok {
+		return err
+	}
+	if intent.LedgerEntryID == nil {
+		slog.WarnContext(ctx, "Refund received before the payment", "event_id", event.ID, "payment_intent", intent.ProviderID)
+		return ErrRefundBeforePayment
+	}
+	refunded := event.Amount.Sub(intent.RefundedAmount)
+	if !refunded.IsPositive() {
+		return nil
+	}
+
+	key := "refund:" + intent.ProviderID + ":" + event.Amount.String()
+	_, err = repo.CreateEntryOnce(&model.LedgerEntry{
+		LeaseID:     intent.LeaseID,
+		Type:        model.LedgerEntryCharge,
+		Category:    model.ChargeCategoryRefund,
+		Date:        event.Created.Truncate(24 * time.Hour),
+		Description: "Refund of online payment",
+		Amount:      refunded,
+		Reference:   intent.ProviderID,
+		CreatedBy:   s.provider.Name(),
+		PostingKey:  &key,
+	})
+	if err != nil {
+		return err
+	}
+
+	status := model.PaymentStatusPartiallyRefunded
+	if event.Amount.GreaterThanOrEqual(intent.Amount) {
+		status = model.PaymentStatusRefunded
+	}
+	slog.InfoContext(ctx, "Recorded online payment refund", "lease_id", intent.LeaseID, "payment_intent", intent.ProviderID, "amount", refunded.String())
+	return repo.UpdatePaymentIntentFields(intent.ID, map[string]interface{}{"status": status, "refunded_amount": event.Amount})
+}
+
+// reconcilePayout records a payout and matches the payments it settled
+// with the payment intents recorded as succeeded. A payout already recorded
+// from another event is left as it is.
+func (s *PaymentService) reconcilePayout(ctx context.Context, repo *repository.PaymentRepository, event payments.Event, settled []payments.PayoutPayment) error {
+	if _, err := repo.GetPayoutByProviderID(event.PayoutID); err == nil {
+		slog.InfoContext(ctx, "Ignored payout recorded before", "event_id", event.ID, "payout", event.PayoutID)
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	providerIDs := make([]string, 0, len(settled))
+	for _, payment := range settled {
+		providerIDs = append(providerIDs, payment.PaymentIntentID)
+	}
+	intents, err := repo.GetPaymentIntentsByProviderIDs(providerIDs)
+	if err != nil {
+		return err
+	}
+	recorded := map[string]model.PaymentIntent{}
+	for _, intent := range intents {
+		if intent.LedgerEntryID != nil {
+			recorded[intent.ProviderID] = intent
+		}
+	}
+
+	payout := model.Payout{
+		Provider:          s.provider.Name(),
+		ProviderID:        event.PayoutID,
+		Amount:            event.Amount,
+		Currency:          event.Currency,
+		ArrivalDate:       event.ArrivalDate,
+		UnmatchedPayments: []string{},
+	}
+	var matchedIDs []uint
+	for _, payment := range settled {
+		intent, ok := recorded[payment.PaymentIntentID]
+		if !ok {
+			payout.UnmatchedPayments = append(payout.UnmatchedPayments, payment.PaymentIntentID)
+			continue
+		}
+		payout.MatchedAmount = payout.MatchedAmount.Add(payment.Net)
+		payout.Fees = payout.Fees.Add(payment.Fee)
+		matchedIDs = append(matchedIDs, intent.ID)
+	}
+	payout.Discrepancy = payout.Amount.Sub(payout.MatchedAmount)
+	payout.Status = model.PayoutStatusReconciled
+	if !payout.Discrepancy.IsZero() || len(payout.UnmatchedPayments) > 0 {
+		payout.Status = model.PayoutStatusDiscrepancy
+		slog.WarnContext(ctx, "Payout does not reconcile", "payout", payout.ProviderID, "discrepancy", payout.Discrepancy.String(), "unmatched_payments", len(payout.UnmatchedPayments))
+	}
+
+	if err := repo.CreatePayout(&payout); err != nil {
+		return err
+	}
+	return repo.AssignPayout(payout.ID, matchedIDs)
+}
+
+// GetPayouts returns the payouts, optionally only those with the given status.
+func (s *PaymentService) GetPayouts(ctx context.Context, status string) ([]model.Payout, error) {
+	return s.repo.WithContext(ctx).GetPayouts(status)
+}
+
+// GetPayout returns a payout with the payment intents it settled.
+func (s *PaymentService) GetPayout(ctx context.Context, id uint) (model.Payout, error) {
+	payout, err := s.repo.WithContext(ctx).GetPayout(id)
+	return payout, notFound(err, ErrPayoutNotFound)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+)
+
+// propertyLookup is implemented by the repositories of records that belong
+// to a property.
+type propertyLookup interface {
+	PropertyExists(propertyID uint) (bool, error)
+}
+
+// placeLookup is implemented by the repositories of records that belong to
+// a property and optionally one of its units.
+type placeLookup interface {
+	propertyLookup
+	GetUnit(unitID uint) (model.Unit, error)
+}
+
+// requireProperty returns ErrPropertyNotFound unless the property exists.
+func requireProperty(repo propertyLookup, propertyID uint) error {
+	exists, err := repo.PropertyExists(propertyID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrPropertyNotFound
+	}
+	return nil
+}
+
+// locate checks the property and unit a record belongs to. A unit implies
+// its property, which is filled in when propertyID is zero. It returns the
+// problems found with the property_id and unit_id fields.
+func locate(repo placeLookup, propertyID *uint, unitID *uint) ([]apperror.FieldError, error) {
+	var fields []apperror.FieldError
+	if unitID != nil {
+		unit, err := repo.GetUnit(*unitID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			fields = append(fields, apperror.FieldError{Field: "unit_id", Reason: "does not exist"})
+		case err != nil:
+			return nil, err
+		case *propertyID != 0 && *propertyID != unit.PropertyID:
+			fields = append(fields, apperror.FieldError{Field: "unit_id", Reason: "does not belong to property_id"})
+		default:
+			*propertyID = unit.PropertyID
+		}
+	}
+
+	if *propertyID != 0 {
+		exists, err := repo.PropertyExists(*propertyID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			fields = append(fields, apperror.FieldError{Field: "property_id", Reason: "does not exist"})
+		}
+	}
+	return fields, nil
+}
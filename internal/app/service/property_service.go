@@ -1,8 +1,24 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/dto"
 	"propmanager/internal/app/model"
 	"propmanager/internal/app/repository"
+	"propmanager/internal/app/validation"
+)
+
+// Patch document media types accepted by PatchProperty.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
 )
 
 type PropertyService struct {
@@ -13,26 +29,343 @@ func NewPropertyService(repo *repository.PropertyRepository) *PropertyService {
 	return &PropertyService{repo: repo}
 }
 
-func (s *PropertyService) GetAllProperties() ([]model.Property, error) {
-	return s.repo.GetAllProperties()
+func (s *PropertyService) GetAllProperties(ctx context.Context) ([]model.Property, error) {
+	repo := s.repo.WithContext(ctx)
+	properties, err := repo.GetAllProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	pointers := make([]*model.Property, len(properties))
+	for i := range properties {
+		pointers[i] = &properties[i]
+	}
+	return properties, withOccupancy(repo, pointers...)
+}
+
+func (s *PropertyService) GetProperty(ctx context.Context, id uint) (model.Property, error) {
+	repo := s.repo.WithContext(ctx)
+	property, err := repo.GetProperty(id)
+	if err != nil {
+		return property, notFound(err, ErrPropertyNotFound)
+	}
+	return property, withOccupancy(repo, &property)
+}
+
+// GetPropertyUnscoped returns a property, including a deleted one, with its current images.
+func (s *PropertyService) GetPropertyUnscoped(ctx context.Context, id uint) (model.Property, error) {
+	repo := s.repo.WithContext(ctx)
+	property, err := repo.GetPropertyUnscoped(id)
+	if err != nil {
+		return property, notFound(err, ErrPropertyNotFound)
+	}
+	property.Images = activeImages(property.Images)
+	return property, withOccupancy(repo, &property)
+}
+
+func (s *PropertyService) CreateProperty(ctx context.Context, property *model.Property, actor string) error {
+	property.Version = 1
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.PropertyRepository) error {
+		if err := repo.CreateProperty(property); err != nil {
+			return err
+		}
+		return recordVersion(repo, property.ID, model.ActionCreate, actor, nil)
+	})
+}
+
+// UpdateProperty overwrites the property's fields provided it is still at
+// expectedVersion. On success property holds the stored result.
+func (s *PropertyService) UpdateProperty(ctx context.Context, property *model.Property, expectedVersion uint, actor string) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.PropertyRepository) error {
+		before, err := repo.GetProperty(property.ID)
+		if err != nil {
+			return notFound(err, ErrPropertyNotFound)
+		}
+		if before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+		updated, err := repo.UpdateProperty(property, expectedVersion)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrVersionMismatch
+		}
+		if err := recordVersion(repo, property.ID, model.ActionUpdate, actor, &before); err != nil {
+			return err
+		}
+		if *property, err = repo.GetProperty(property.ID); err != nil {
+			return err
+		}
+		return withOccupancy(repo, property)
+	})
+}
+
+// PatchProperty applies a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902)
+// document to the property's editable fields provided it is still at expectedVersion.
+func (s *PropertyService) PatchProperty(ctx context.Context, id uint, expectedVersion uint, contentType string, patch []byte, actor string) (model.Property, error) {
+	current, err := s.repo.WithContext(ctx).GetProperty(id)
+	if err != nil {
+		return model.Property{}, notFound(err, ErrPropertyNotFound)
+	}
+
+	original, err := json.Marshal(dto.NewUpdatePropertyRequest(current))
+	if err != nil {
+		return model.Property{}, err
+	}
+
+	var patched []byte
+	switch contentType {
+	case MergePatchContentType:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case JSONPatchContentType:
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			patched, err = operations.Apply(original)
+		}
+	default:
+		return model.Property{}, ErrUnsupportedPatch
+	}
+	if err != nil {
+		return model.Property{}, ErrInvalidPatch.Wrap(err)
+	}
+
+	var request dto.UpdatePropertyRequest
+	if err := validation.DecodeBytes(patched, &request); err != nil {
+		return model.Property{}, err
+	}
+
+	property := request.ToModel(id)
+	if err := s.UpdateProperty(ctx, &property, expectedVersion, actor); err != nil {
+		return model.Property{}, err
+	}
+	return property, nil
+}
+
+// DeleteProperty deletes the property provided it is still at expectedVersion.
+func (s *PropertyService) DeleteProperty(ctx context.Context, id uint, expectedVersion uint, actor string) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.PropertyRepository) error {
+		before, err := repo.GetProperty(id)
+		if err != nil {
+			return notFound(err, ErrPropertyNotFound)
+		}
+		if before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+		deleted, err := repo.DeleteProperty(id, expectedVersion)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrVersionMismatch
+		}
+		return recordVersion(repo, id, model.ActionDelete, actor, &before)
+	})
+}
+
+// AddImage attaches an uploaded image to a property.
+func (s *PropertyService) AddImage(ctx context.Context, propertyID uint, url string, size int64, actor string) (model.Image, error) {
+	image := model.Image{PropertyID: propertyID, URL: url, Size: size}
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.PropertyRepository) error {
+		before, err := repo.GetProperty(propertyID)
+		if err != nil {
+			return notFound(err, ErrPropertyNotFound)
+		}
+		if err := repo.CreateImage(&image); err != nil {
+			return err
+		}
+		if err := repo.TouchProperty(propertyID); err != nil {
+			return err
+		}
+		return recordVersion(repo, propertyID, model.ActionImageAdded, actor, &before)
+	})
+	return image, err
+}
+
+func (s *PropertyService) DeleteImage(ctx context.Context, propertyID uint, imageID uint, actor string) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.PropertyRepository) error {
+		before, err := repo.GetProperty(propertyID)
+		if err != nil {
+			return notFound(err, ErrPropertyNotFound)
+		}
+		deleted, err := repo.DeleteImage(propertyID, imageID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrImageNotFound
+		}
+		if err := repo.TouchProperty(propertyID); err != nil {
+			return err
+		}
+		return recordVersion(repo, propertyID, model.ActionImageDeleted, actor, &before)
+	})
+}
+
+// GetPropertyHistory returns every recorded version of a property, including
+// a deleted one, newest first. A property without recorded versions has an empty history.
+func (s *PropertyService) GetPropertyHistory(ctx context.Context, propertyID uint) ([]model.PropertyVersion, error) {
+	repo := s.repo.WithContext(ctx)
+	if _, err := repo.GetPropertyUnscoped(propertyID); err != nil {
+		return nil, notFound(err, ErrPropertyNotFound)
+	}
+
+	versions, err := repo.GetPropertyVersions(propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []model.PropertyVersion{}
+	}
+	return versions, nil
+}
+
+func (s *PropertyService) GetPropertyVersion(ctx context.Context, propertyID uint, version uint) (model.PropertyVersion, error) {
+	propertyVersion, err := s.repo.WithContext(ctx).GetPropertyVersion(propertyID, version)
+	return propertyVersion, notFound(err, ErrPropertyVersionNotFound)
+}
+
+// RestorePropertyVersion returns a property, including a deleted one, to the
+// state captured in the given version if its current version still matches
+// expectedVersion. The restore is itself recorded as a new version.
+func (s *PropertyService) RestorePropertyVersion(ctx context.Context, propertyID uint, version uint, expectedVersion uint, actor string) (model.Property, error) {
+	var restored model.Property
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.PropertyRepository) error {
+		target, err := repo.GetPropertyVersion(propertyID, version)
+		if err != nil {
+			return notFound(err, ErrPropertyVersionNotFound)
+		}
+
+		var snapshot model.Property
+		if err := json.Unmarshal(target.Snapshot, &snapshot); err != nil {
+			return err
+		}
+		if snapshot.DeletedAt.Valid {
+			return ErrDeletedVersion
+		}
+
+		before, err := repo.GetPropertyUnscoped(propertyID)
+		if err != nil {
+			return notFound(err, ErrPropertyNotFound)
+		}
+		if before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+		before.Images = activeImages(before.Images)
+
+		snapshot.ID = propertyID
+		if snapshot.Status == "" {
+			snapshot.Status = model.PropertyStatusAvailable
+		}
+		if err := repo.RestoreProperty(&snapshot); err != nil {
+			return err
+		}
+		if err := recordVersion(repo, propertyID, model.ActionRestore, actor, &before); err != nil {
+			return err
+		}
+
+		if restored, err = repo.GetProperty(propertyID); err != nil {
+			return err
+		}
+		return withOccupancy(repo, &restored)
+	})
+	return restored, err
 }
 
-func (s *PropertyService) GetProperty(id uint) (model.Property, error) {
-	return s.repo.GetProperty(id)
+// recordVersion snapshots the current state of a property and stores it as
+// the next version along with the field-level diff against before.
+func recordVersion(repo *repository.PropertyRepository, propertyID uint, action string, actor string, before *model.Property) error {
+	after, err := repo.GetPropertyUnscoped(propertyID)
+	if err != nil {
+		return err
+	}
+	after.Images = activeImages(after.Images)
+
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	var next uint = 1
+	latest, err := repo.GetLatestPropertyVersion(propertyID)
+	if err == nil {
+		next = latest.Version + 1
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return repo.CreatePropertyVersion(&model.PropertyVersion{
+		PropertyID: propertyID,
+		Version:    next,
+		Action:     action,
+		Actor:      actor,
+		Changes:    diffProperties(before, &after),
+		Snapshot:   snapshot,
+	})
 }
 
-func (s *PropertyService) CreateProperty(property *model.Property) error {
-	return s.repo.CreateProperty(property)
+// withOccupancy sets the occupancy of each property from its units.
+func withOccupancy(repo *repository.PropertyRepository, properties ...*model.Property) error {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(properties))
+	for i, property := range properties {
+		ids[i] = property.ID
+	}
+	occupancy, err := repo.GetOccupancy(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, property := range properties {
+		propertyOccupancy := occupancy[property.ID]
+		property.Occupancy = &propertyOccupancy
+	}
+	return nil
 }
 
-func (s *PropertyService) UpdateProperty(property *model.Property) error {
-	return s.repo.UpdateProperty(property)
+func activeImages(images []model.Image) []model.Image {
+	active := make([]model.Image, 0, len(images))
+	for _, image := range images {
+		if !image.DeletedAt.Valid {
+			active = append(active, image)
+		}
+	}
+	return active
 }
 
-func (s *PropertyService) DeleteProperty(id uint) error {
-	return s.repo.DeleteProperty(id)
+// diffProperties lists the user-visible fields that differ between two states
+// of a property. A nil before is treated as an empty property.
+func diffProperties(before *model.Property, after *model.Property) model.FieldChanges {
+	if before == nil {
+		before = &model.Property{}
+	}
+
+	changes := model.FieldChanges{}
+	add := func(field string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, model.FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+
+	add("name", before.Name, after.Name)
+	add("description", before.Description, after.Description)
+	add("price", before.Price, after.Price)
+	add("location", before.Location, after.Location)
+	add("status", before.Status, after.Status)
+	add("deleted", before.DeletedAt.Valid, after.DeletedAt.Valid)
+	add("images", imageURLs(before.Images), imageURLs(after.Images))
+
+	return changes
 }
 
-func (s *PropertyService) DeleteImage(propertyID uint, imageID uint) error {
-	return s.repo.DeleteImage(propertyID, imageID)
+func imageURLs(images []model.Image) []string {
+	urls := make([]string, 0, len(images))
+	for _, image := range images {
+		urls = append(urls, image.URL)
+	}
+	return urls
 }
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+func TestPropertyWithoutVersionsHasEmptyHistory(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	properties := NewPropertyService(repository.NewPropertyRepository(db))
+	if err := db.Create(&model.Property{Name: "Elm", Version: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := properties.GetPropertyHistory(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions == nil || len(versions) != 0 {
+		t.Errorf("history = %v, want an empty list", versions)
+	}
+
+	if _, err := properties.GetPropertyHistory(ctx, 2); !errors.Is(err, ErrPropertyNotFound) {
+		t.Errorf("history of a missing property: err = %v, want ErrPropertyNotFound", err)
+	}
+}
+
+// TestRestoreDeletedProperty records a property's history through an update
+// and a delete, and restores its first version once the client presents the
+// current version.
+func TestRestoreDeletedProperty(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	properties := NewPropertyService(repository.NewPropertyRepository(db))
+
+	property := model.Property{Name: "Elm", Price: 1000}
+	if err := properties.CreateProperty(ctx, &property, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	update := model.Property{ID: property.ID, Name: "Oak", Price: 1200, Status: model.PropertyStatusAvailable}
+	if err := properties.UpdateProperty(ctx, &update, 1, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := properties.UpdateProperty(ctx, &update, 1, "bob"); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("stale update: err = %v, want ErrVersionMismatch", err)
+	}
+	if err := properties.DeleteProperty(ctx, property.ID, 2, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := properties.GetPropertyHistory(ctx, property.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, version := range versions {
+		actions = append(actions, version.Action)
+	}
+	want := []string{model.ActionDelete, model.ActionUpdate, model.ActionCreate}
+	if len(actions) != len(want) {
+		t.Fatalf("history actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("history actions = %v, want %v", actions, want)
+		}
+	}
+
+	if _, err := properties.RestorePropertyVersion(ctx, property.ID, 1, 1, "alice"); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("stale restore: err = %v, want ErrVersionMismatch", err)
+	}
+	if _, err := properties.RestorePropertyVersion(ctx, property.ID, 3, 2, "alice"); !errors.Is(err, ErrDeletedVersion) {
+		t.Errorf("restore of the delete: err = %v, want ErrDeletedVersion", err)
+	}
+
+	restored, err := properties.RestorePropertyVersion(ctx, property.ID, 1, 2, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Name != "Elm" || restored.Price != 1000 || restored.Version != 3 {
+		t.Errorf("restored = %s at %v, version %d; want Elm at 1000, version 3", restored.Name, restored.Price, restored.Version)
+	}
+	if _, err := properties.GetProperty(ctx, property.ID); err != nil {
+		t.Errorf("restored property is not visible: %v", err)
+	}
+
+	versions, err = properties.GetPropertyHistory(ctx, property.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 4 || versions[0].Action != model.ActionRestore {
+		t.Errorf("latest of %d versions is %q, want %d ending with a restore", len(versions), versions[0].Action, 4)
+	}
+}
+
+// TestPatchProperty applies merge patches and JSON patches to a property
+// and checks that only the patched fields change and that the result is validated.
+func TestPatchProperty(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		wantErr     error
+		wantName    string
+		wantPrice   float64
+		wantDesc    string
+	}{
+		{"merge patch of one field", MergePatchContentType, `{"price":1500}`, nil, "Elm", 1500, "Two bedrooms"},
+		{"merge patch removing a field", MergePatchContentType, `{"description":null}`, nil, "Elm", 1000, ""},
+		{"JSON patch", JSONPatchContentType, `[{"op":"test","path":"/name","value":"Elm"},{"op":"replace","path":"/name","value":"Oak"}]`, nil, "Oak", 1000, "Two bedrooms"},
+		{"failed JSON patch test", JSONPatchContentType, `[{"op":"test","path":"/name","value":"Oak"},{"op":"replace","path":"/name","value":"Ash"}]`, ErrInvalidPatch, "", 0, ""},
+		{"malformed JSON patch", JSONPatchContentType, `{"op":"replace"}`, ErrInvalidPatch, "", 0, ""},
+		{"invalid result", MergePatchContentType, `{"price":-1}`, apperror.Validation(nil), "", 0, ""},
+		{"field that cannot be patched", JSONPatchContentType, `[{"op":"add","path":"/id","value":7}]`, apperror.Validation(nil), "", 0, ""},
+		{"unsupported content type", "application/json", `{"price":1500}`, ErrUnsupportedPatch, "", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			properties := NewPropertyService(repository.NewPropertyRepository(newTestDB(t)))
+			property := model.Property{Name: "Elm", Description: "Two bedrooms", Location: "1 Elm St", Price: 1000}
+			if err := properties.CreateProperty(ctx, &property, "alice"); err != nil {
+				t.Fatal(err)
+			}
+
+			patched, err := properties.PatchProperty(ctx, property.ID, 1, tt.contentType, []byte(tt.patch), "alice")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if stored, _ := properties.GetProperty(ctx, property.ID); stored.Version != 1 {
+					t.Errorf("version after a rejected patch = %d, want 1", stored.Version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if patched.Name != tt.wantName || patched.Price != tt.wantPrice || patched.Description != tt.wantDesc || patched.Location != "1 Elm St" {
+				t.Errorf("patched = %q, %v, %q at %q; want %q, %v, %q at \"1 Elm St\"",
+					patched.Name, patched.Price, patched.Description, patched.Location, tt.wantName, tt.wantPrice, tt.wantDesc)
+			}
+			if patched.Version != 2 {
+				t.Errorf("version = %d, want 2", patched.Version)
+			}
+		})
+	}
+}
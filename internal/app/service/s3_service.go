@@ -5,26 +5,65 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
+	"propmanager/internal/app/metrics"
 	"propmanager/internal/config"
+	"propmanager/internal/tracing"
 )
 
 type S3Service struct {
 	cfg *config.Config
+
+	mu        sync.RWMutex
+	accessKey string
+	secretKey string
 }
 
 func NewS3Service(cfg *config.Config) *S3Service {
-	log.Printf("Initializing S3 Service with Endpoint: %s, Region: %s, Bucket: %s", cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket)
-	log.Printf("AWS Access Key: %s, Secret Key: %s", cfg.S3AccessKey, cfg.S3SecretKey)
-	return &S3Service{cfg: cfg}
+	slog.Info("Initializing S3 service", "endpoint", cfg.S3Endpoint, "region", cfg.S3Region, "bucket", cfg.S3Bucket)
+	return &S3Service{cfg: cfg, accessKey: cfg.S3AccessKey, secretKey: cfg.S3SecretKey}
+}
+
+// SetCredentials replaces the credentials used by subsequent S3 calls, so
+// that rotated keys take effect without a restart. It reports whether they
+// changed.
+func (s *S3Service) SetCredentials(accessKey, secretKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if accessKey == s.accessKey && secretKey == s.secretKey {
+		return false
+	}
+	s.accessKey, s.secretKey = accessKey, secretKey
+	return true
+}
+
+func (s *S3Service) newSession() (*session.Session, error) {
+	s.mu.RLock()
+	accessKey, secretKey := s.accessKey, s.secretKey
+	s.mu.RUnlock()
+
+	return session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		Endpoint:         aws.String(s.cfg.S3Endpoint),
+		Region:           aws.String(s.cfg.S3Region),
+		DisableSSL:       aws.Bool(false),
+		S3ForcePathStyle: aws.Bool(true),
+		LogLevel:         aws.LogLevel(aws.LogOff),
+	})
 }
 
 // generateRandomPrefix creates a random string to be used as a filename prefix.
@@ -37,85 +76,196 @@ func generateRandomPrefix(n int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// UploadImage stores a publicly readable image and returns its URL.
 func (s *S3Service) UploadImage(ctx context.Context, file []byte, fileName string) (string, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Credentials:      credentials.NewStaticCredentials(s.cfg.S3AccessKey, s.cfg.S3SecretKey, ""),
-		Endpoint:         aws.String(s.cfg.S3Endpoint),
-		Region:           aws.String(s.cfg.S3Region),
-		DisableSSL:       aws.Bool(false),
-		S3ForcePathStyle: aws.Bool(true),
-		LogLevel:         aws.LogLevel(aws.LogOff),
-	})
+	key, err := s.putObject(ctx, file, fileName, "", "public-read")
+	if err != nil {
+		return "", err
+	}
+	// Return the full URL including the modified file name.
+	return fmt.Sprintf("%s/%s", s.cfg.S3Endpoint, key), nil
+}
+
+// UploadDocument stores a private file and returns its key. Private files
+// can only be downloaded through PresignGetURL.
+func (s *S3Service) UploadDocument(ctx context.Context, file []byte, fileName string, contentType string) (string, error) {
+	return s.putObject(ctx, file, fileName, contentType, "private")
+}
+
+func (s *S3Service) putObject(ctx context.Context, file []byte, fileName string, contentType string, acl string) (key string, err error) {
+	ctx, span := s.startSpan(ctx, "PutObject", attribute.Int("s3.bytes", len(file)))
+	defer func(start time.Time) {
+		metrics.ObserveS3Operation("put_object", start, len(file), err)
+		endSpan(span, err)
+	}(time.Now())
+
+	sess, err := s.newSession()
 	if err != nil {
-		log.Fatalf("AWS Session error: %v", err)
+		slog.ErrorContext(ctx, "AWS session error", "error", err)
 		return "", err
 	}
 
 	// Generate a random prefix for the filename to prevent collisions.
 	prefix, err := generateRandomPrefix(4) // generates a random 8 character hex string
 	if err != nil {
-		log.Printf("Error generating random prefix: %v", err)
+		slog.ErrorContext(ctx, "Error generating random prefix", "error", err)
 		return "", err
 	}
 
 	// Append the random prefix to the original filename.
 	modifiedFileName := fmt.Sprintf("%s-%s", prefix, fileName)
-	log.Printf("Uploading file with modified name: %s", modifiedFileName)
+	span.SetAttributes(attribute.String("s3.key", modifiedFileName))
+	slog.InfoContext(ctx, "Uploading file to S3", "bucket", s.cfg.S3Bucket, "key", modifiedFileName, "bytes", len(file))
 
-	_, err = s3.New(sess).PutObjectWithContext(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket: aws.String(s.cfg.S3Bucket),
 		Key:    aws.String(modifiedFileName),
 		Body:   aws.ReadSeekCloser(strings.NewReader(string(file))),
-		ACL:    aws.String("public-read"),
-	})
+		ACL:    aws.String(acl),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	_, err = s3.New(sess).PutObjectWithContext(ctx, input)
 
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
 			case s3.ErrCodeBucketAlreadyExists:
-				log.Printf("Bucket name already in use: %v", aerr.Message())
+				slog.ErrorContext(ctx, "Bucket name already in use", "bucket", s.cfg.S3Bucket, "error", aerr.Message())
 			default:
-				log.Printf("Unknown S3 error: %v", aerr.Message())
+				slog.ErrorContext(ctx, "Unknown S3 error", "code", aerr.Code(), "error", aerr.Message())
 			}
 		} else {
-			log.Printf("Non-S3 error: %v", err)
+			slog.ErrorContext(ctx, "Non-S3 error", "error", err)
 		}
 		return "", err
 	}
 
-	log.Printf("Successfully uploaded: %s", modifiedFileName)
-	// Return the full URL including the modified file name.
-	return fmt.Sprintf("%s/%s", s.cfg.S3Endpoint, modifiedFileName), nil
+	slog.InfoContext(ctx, "Successfully uploaded file to S3", "key", modifiedFileName)
+	return modifiedFileName, nil
 }
 
-func (s *S3Service) DeleteImage(imageUrl string) error {
-	sess, err := session.NewSession(&aws.Config{
-		Credentials:      credentials.NewStaticCredentials(s.cfg.S3AccessKey, s.cfg.S3SecretKey, ""),
-		Endpoint:         aws.String(s.cfg.S3Endpoint),
-		Region:           aws.String(s.cfg.S3Region),
-		DisableSSL:       aws.Bool(false),
-		S3ForcePathStyle: aws.Bool(true),
-		LogLevel:         aws.LogLevel(aws.LogOff),
-	})
+// DeleteImage deletes an image uploaded with UploadImage by its URL.
+func (s *S3Service) DeleteImage(ctx context.Context, imageUrl string) error {
+	// Extract the key from imageUrl
+	urlParts := strings.Split(imageUrl, "/")
+	return s.deleteObject(ctx, urlParts[len(urlParts)-1])
+}
+
+// DeleteDocument deletes a file uploaded with UploadDocument.
+func (s *S3Service) DeleteDocument(ctx context.Context, key string) error {
+	return s.deleteObject(ctx, key)
+}
+
+func (s *S3Service) deleteObject(ctx context.Context, key string) (err error) {
+	ctx, span := s.startSpan(ctx, "DeleteObject", attribute.String("s3.key", key))
+	defer func(start time.Time) {
+		metrics.ObserveS3Operation("delete_object", start, 0, err)
+		endSpan(span, err)
+	}(time.Now())
+
+	sess, err := s.newSession()
 	if err != nil {
-		log.Fatalf("AWS Session error: %v", err)
+		slog.ErrorContext(ctx, "AWS session error", "error", err)
 		return err
 	}
 
-	// Extract the key from imageUrl
-	urlParts := strings.Split(imageUrl, "/")
-	key := urlParts[len(urlParts)-1]
-	log.Printf("Deleting image with key: %s", key)
+	slog.InfoContext(ctx, "Deleting file from S3", "bucket", s.cfg.S3Bucket, "key", key)
 
-	_, err = s3.New(sess).DeleteObject(&s3.DeleteObjectInput{
+	_, err = s3.New(sess).DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.cfg.S3Bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		log.Printf("Error deleting image: %v", err)
+		slog.ErrorContext(ctx, "Error deleting file from S3", "key", key, "error", err)
 		return err
 	}
 
-	log.Printf("Successfully deleted image: %s", key)
+	slog.InfoContext(ctx, "Successfully deleted file from S3", "key", key)
 	return nil
 }
+
+// DownloadDocument returns the contents of a file uploaded with
+// UploadDocument, for documents that embed it.
+func (s *S3Service) DownloadDocument(ctx context.Context, key string) (data []byte, err error) {
+	ctx, span := s.startSpan(ctx, "GetObject", attribute.String("s3.key", key))
+	defer func(start time.Time) {
+		metrics.ObserveS3Operation("get_object", start, 0, err)
+		endSpan(span, err)
+	}(time.Now())
+
+	sess, err := s.newSession()
+	if err != nil {
+		slog.ErrorContext(ctx, "AWS session error", "error", err)
+		return nil, err
+	}
+
+	output, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.S3Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error downloading file from S3", "key", key, "error", err)
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return io.ReadAll(output.Body)
+}
+
+// PresignGetURL returns a URL that downloads the private file key for ttl
+// without further authentication.
+func (s *S3Service) PresignGetURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	sess, err := s.newSession()
+	if err != nil {
+		return "", err
+	}
+
+	req, _ := s3.New(sess).GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.S3Bucket),
+		Key:    aws.String(key),
+	})
+	req.SetContext(ctx)
+	return req.Presign(ttl)
+}
+
+// CheckBucket sends a HEAD request for the configured bucket, failing if it
+// does not exist or the credentials cannot access it.
+func (s *S3Service) CheckBucket(ctx context.Context) (err error) {
+	ctx, span := s.startSpan(ctx, "HeadBucket")
+	defer func(start time.Time) {
+		metrics.ObserveS3Operation("head_bucket", start, 0, err)
+		endSpan(span, err)
+	}(time.Now())
+
+	sess, err := s.newSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = s3.New(sess).HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.cfg.S3Bucket),
+	})
+	return err
+}
+
+// startSpan starts a client span for an S3 operation on the configured bucket.
+func (s *S3Service) startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("rpc.system", "aws-api"),
+		attribute.String("rpc.service", "S3"),
+		attribute.String("rpc.method", operation),
+		attribute.String("s3.bucket", s.cfg.S3Bucket),
+	)
+	return tracing.Tracer().Start(ctx, "S3 "+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -1,24 +1,122 @@
 package service
 
-import "runtime"
+import (
+	"context"
+	"sync"
+	"time"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
 
 // StatsService represents the service for statistics.
-type StatsService struct{}
+type StatsService struct {
+	repo     *repository.StatsRepository
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]model.PortfolioStats
+}
 
-// NewStatsService returns a new StatsService.
-func NewStatsService() *StatsService {
-	return &StatsService{}
+// NewStatsService returns a new StatsService that reuses computed statistics for cacheTTL.
+func NewStatsService(repo *repository.StatsRepository, cacheTTL time.Duration) *StatsService {
+	return &StatsService{repo: repo, cacheTTL: cacheTTL, cache: map[string]model.PortfolioStats{}}
 }
 
-// GetStats returns system statistics such as memory usage and performance.
-func (s *StatsService) GetStats() map[string]interface{} {
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
+// GetStats returns portfolio statistics for the properties created in
+// [from, to). Either bound may be nil to leave the range open.
+func (s *StatsService) GetStats(ctx context.Context, from, to *time.Time) (model.PortfolioStats, error) {
+	key := cacheKey(from) + "/" + cacheKey(to)
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Since(cached.GeneratedAt) < s.cacheTTL {
+		return cached, nil
+	}
+
+	stats, err := s.computeStats(s.repo.WithContext(ctx), from, to)
+	if err != nil {
+		return model.PortfolioStats{}, err
+	}
+
+	s.mu.Lock()
+	for k, v := range s.cache {
+		if time.Since(v.GeneratedAt) >= s.cacheTTL {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = stats
+	s.mu.Unlock()
+
+	return stats, nil
+}
+
+func (s *StatsService) computeStats(repo *repository.StatsRepository, from, to *time.Time) (model.PortfolioStats, error) {
+	stats := model.PortfolioStats{From: from, To: to, GeneratedAt: time.Now()}
+
+	var err error
+	if stats.Properties.Total, err = repo.CountProperties(from, to); err != nil {
+		return stats, err
+	}
+	if stats.Properties.ByStatus, err = repo.CountPropertiesBy("status", from, to); err != nil {
+		return stats, err
+	}
+	if stats.Properties.ByLocation, err = repo.CountPropertiesBy("location", from, to); err != nil {
+		return stats, err
+	}
+	if stats.Properties.WithoutImages, err = repo.CountPropertiesWithoutImages(from, to); err != nil {
+		return stats, err
+	}
+	if stats.Price, err = repo.PriceSummary(from, to); err != nil {
+		return stats, err
+	}
+	if stats.Price.Median, err = repo.MedianPrice(stats.Properties.Total, from, to); err != nil {
+		return stats, err
+	}
+	if stats.Storage, err = repo.ImageStorage(from, to); err != nil {
+		return stats, err
+	}
+
+	createdAt, err := repo.PropertyCreationTimes(from, to)
+	if err != nil {
+		return stats, err
+	}
+	stats.ListingsPerWeek = listingsPerWeek(createdAt)
+
+	return stats, nil
+}
+
+// listingsPerWeek buckets creation times, which must be sorted, into
+// consecutive weeks starting on Monday (UTC). Weeks with no listings are included.
+func listingsPerWeek(createdAt []time.Time) []model.WeeklyListings {
+	weeks := []model.WeeklyListings{}
+	for _, t := range createdAt {
+		week := weekStart(t)
+		for len(weeks) > 0 && weeks[len(weeks)-1].WeekStart.Before(week) {
+			next := weeks[len(weeks)-1].WeekStart.AddDate(0, 0, 7)
+			if next.After(week) {
+				break
+			}
+			weeks = append(weeks, model.WeeklyListings{WeekStart: next})
+		}
+		if len(weeks) == 0 {
+			weeks = append(weeks, model.WeeklyListings{WeekStart: week})
+		}
+		weeks[len(weeks)-1].Count++
+	}
+	return weeks
+}
+
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
 
-	return map[string]interface{}{
-		"Alloc":      memStats.Alloc,
-		"TotalAlloc": memStats.TotalAlloc,
-		"Sys":        memStats.Sys,
-		"NumGC":      memStats.NumGC,
+func cacheKey(t *time.Time) string {
+	if t == nil {
+		return ""
 	}
+	return t.UTC().Format(time.RFC3339Nano)
 }
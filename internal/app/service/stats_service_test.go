@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+// TestStatsAreCachedPerRange checks that statistics are reused for the same
+// date range until they expire, and computed afresh for another range.
+func TestStatsAreCachedPerRange(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	create := func(name string) {
+		t.Helper()
+		if err := db.Create(&model.Property{Name: name, Location: "Springfield", Status: model.PropertyStatusAvailable, Price: 1000}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	create("Elm")
+	create("Oak")
+
+	cached := NewStatsService(repository.NewStatsRepository(db), time.Hour)
+	uncached := NewStatsService(repository.NewStatsRepository(db), 0)
+	from := time.Now().Add(-time.Hour)
+
+	for _, s := range []*StatsService{cached, uncached} {
+		stats, err := s.GetStats(ctx, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Properties.Total != 2 || stats.Properties.ByLocation["Springfield"] != 2 {
+			t.Fatalf("properties = %+v, want 2 in Springfield", stats.Properties)
+		}
+	}
+
+	create("Ash")
+	tests := []struct {
+		name    string
+		service *StatsService
+		from    *time.Time
+		want    int64
+	}{
+		{"same range", cached, nil, 2},
+		{"another range", cached, &from, 3},
+		{"caching disabled", uncached, nil, 3},
+	}
+	for _, tt := range tests {
+		stats, err := tt.service.GetStats(ctx, tt.from, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Properties.Total != tt.want {
+			t.Errorf("%s: total = %d, want %d", tt.name, stats.Properties.Total, tt.want)
+		}
+	}
+}
+
+func TestListingsPerWeek(t *testing.T) {
+	createdAt := []time.Time{
+		time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),  // Monday
+		time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC), // Sunday of the same week
+		time.Date(2026, 3, 24, 9, 0, 0, 0, time.UTC), // two weeks later
+	}
+	want := []model.WeeklyListings{
+		{WeekStart: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Count: 2},
+		{WeekStart: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Count: 0},
+		{WeekStart: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), Count: 0},
+		{WeekStart: time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC), Count: 1},
+	}
+
+	got := listingsPerWeek(createdAt)
+	if len(got) != len(want) {
+		t.Fatalf("weeks = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].WeekStart.Equal(want[i].WeekStart) || got[i].Count != want[i].Count {
+			t.Errorf("week %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
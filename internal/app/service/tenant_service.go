@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+type TenantService struct {
+	repo *repository.TenantRepository
+}
+
+func NewTenantService(repo *repository.TenantRepository) *TenantService {
+	return &TenantService{repo: repo}
+}
+
+// SearchTenants returns the tenants matching filter.
+func (s *TenantService) SearchTenants(ctx context.Context, filter repository.TenantFilter) ([]model.Tenant, error) {
+	return s.repo.WithContext(ctx).GetTenants(filter)
+}
+
+func (s *TenantService) GetTenant(ctx context.Context, id uint) (model.Tenant, error) {
+	tenant, err := s.repo.WithContext(ctx).GetTenant(id)
+	return tenant, notFound(err, ErrTenantNotFound)
+}
+
+// CreateTenant stores a new tenant. On success tenant holds the stored result.
+func (s *TenantService) CreateTenant(ctx context.Context, tenant *model.Tenant) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.TenantRepository) error {
+		if err := linkResidence(repo, tenant); err != nil {
+			return err
+		}
+		if err := repo.CreateTenant(tenant); err != nil {
+			return err
+		}
+		created, err := repo.GetTenant(tenant.ID)
+		*tenant = created
+		return err
+	})
+}
+
+// UpdateTenant overwrites the tenant's details and emergency contacts. On
+// success tenant holds the stored result.
+func (s *TenantService) UpdateTenant(ctx context.Context, tenant *model.Tenant) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.TenantRepository) error {
+		if err := linkResidence(repo, tenant); err != nil {
+			return err
+		}
+		updated, err := repo.UpdateTenant(tenant)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrTenantNotFound
+		}
+		stored, err := repo.GetTenant(tenant.ID)
+		*tenant = stored
+		return err
+	})
+}
+
+// DeleteTenant removes a tenant and its documents. The caller deletes the
+// stored files first.
+func (s *TenantService) DeleteTenant(ctx context.Context, id uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.TenantRepository) error {
+		deleted, err := repo.DeleteTenant(id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrTenantNotFound
+		}
+		return nil
+	})
+}
+
+// AddDocument records a document uploaded for a tenant.
+func (s *TenantService) AddDocument(ctx context.Context, document *model.TenantDocument) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.TenantRepository) error {
+		if _, err := repo.GetTenant(document.TenantID); err != nil {
+			return notFound(err, ErrTenantNotFound)
+		}
+		return repo.CreateDocument(document)
+	})
+}
+
+func (s *TenantService) GetDocument(ctx context.Context, tenantID uint, documentID uint) (model.TenantDocument, error) {
+	document, err := s.repo.WithContext(ctx).GetDocument(tenantID, documentID)
+	return document, notFound(err, ErrDocumentNotFound)
+}
+
+// DeleteDocument removes a tenant's document. The caller deletes the stored
+// file first.
+func (s *TenantService) DeleteDocument(ctx context.Context, tenantID uint, documentID uint) error {
+	deleted, err := s.repo.WithContext(ctx).DeleteDocument(tenantID, documentID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+// linkResidence checks the property and unit a tenant is linked to.
+func linkResidence(repo *repository.TenantRepository, tenant *model.Tenant) error {
+	var propertyID uint
+	if tenant.PropertyID != nil {
+		propertyID = *tenant.PropertyID
+	}
+	fields, err := locate(repo, &propertyID, tenant.UnitID)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	if propertyID != 0 {
+		tenant.PropertyID = &propertyID
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+type UnitService struct {
+	repo *repository.UnitRepository
+}
+
+func NewUnitService(repo *repository.UnitRepository) *UnitService {
+	return &UnitService{repo: repo}
+}
+
+// GetUnits returns the units of a property, optionally only those with the given status.
+func (s *UnitService) GetUnits(ctx context.Context, propertyID uint, status string) ([]model.Unit, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireProperty(repo, propertyID); err != nil {
+		return nil, err
+	}
+	return repo.GetUnits(propertyID, status)
+}
+
+func (s *UnitService) GetUnit(ctx context.Context, propertyID uint, unitID uint) (model.Unit, error) {
+	unit, err := s.repo.WithContext(ctx).GetUnit(propertyID, unitID)
+	return unit, notFound(err, ErrUnitNotFound)
+}
+
+// CreateUnit adds a unit to a property. Unit numbers are unique within a property.
+func (s *UnitService) CreateUnit(ctx context.Context, unit *model.Unit) error {
+	if err := checkUnitRent(*unit); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.UnitRepository) error {
+		if err := requireProperty(repo, unit.PropertyID); err != nil {
+			return err
+		}
+		if err := requireUnitNumberFree(repo, unit.PropertyID, unit.Number, 0); err != nil {
+			return err
+		}
+		if err := repo.CreateUnit(unit); err != nil {
+			return err
+		}
+		created, err := repo.GetUnit(unit.PropertyID, unit.ID)
+		*unit = created
+		return err
+	})
+}
+
+// UpdateUnit overwrites the unit's editable fields. On success unit holds the stored result.
+func (s *UnitService) UpdateUnit(ctx context.Context, unit *model.Unit) error {
+	if err := checkUnitRent(*unit); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.UnitRepository) error {
+		if _, err := repo.GetUnit(unit.PropertyID, unit.ID); err != nil {
+			return notFound(err, ErrUnitNotFound)
+		}
+		if err := requireUnitNumberFree(repo, unit.PropertyID, unit.Number, unit.ID); err != nil {
+			return err
+		}
+		if _, err := repo.UpdateUnit(unit); err != nil {
+			return err
+		}
+		updated, err := repo.GetUnit(unit.PropertyID, unit.ID)
+		*unit = updated
+		return err
+	})
+}
+
+// CheckDeleteUnit returns a unit that may be deleted, with its images, so
+// that the caller can delete their stored files before DeleteUnit. Units
+// with a lease in force are kept.
+func (s *UnitService) CheckDeleteUnit(ctx context.Context, propertyID uint, unitID uint) (model.Unit, error) {
+	repo := s.repo.WithContext(ctx)
+	unit, err := repo.GetUnit(propertyID, unitID)
+	if err != nil {
+		return unit, notFound(err, ErrUnitNotFound)
+	}
+	return unit, requireUnitNotLeased(repo, unitID)
+}
+
+// DeleteUnit removes a unit without a lease in force and its images. The
+// caller deletes the stored files first.
+func (s *UnitService) DeleteUnit(ctx context.Context, propertyID uint, unitID uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.UnitRepository) error {
+		if _, err := repo.GetUnit(propertyID, unitID); err != nil {
+			return notFound(err, ErrUnitNotFound)
+		}
+		if err := requireUnitNotLeased(repo, unitID); err != nil {
+			return err
+		}
+		deleted, err := repo.DeleteUnit(propertyID, unitID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrUnitNotFound
+		}
+		return nil
+	})
+}
+
+// AddImage attaches an uploaded image to a unit.
+func (s *UnitService) AddImage(ctx context.Context, propertyID uint, unitID uint, url string, size int64) (model.UnitImage, error) {
+	image := model.UnitImage{UnitID: unitID, URL: url, Size: size}
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.UnitRepository) error {
+		if _, err := repo.GetUnit(propertyID, unitID); err != nil {
+			return notFound(err, ErrUnitNotFound)
+		}
+		return repo.CreateUnitImage(&image)
+	})
+	return image, err
+}
+
+func (s *UnitService) GetImage(ctx context.Context, propertyID uint, unitID uint, imageID uint) (model.UnitImage, error) {
+	repo := s.repo.WithContext(ctx)
+	if _, err := repo.GetUnit(propertyID, unitID); err != nil {
+		return model.UnitImage{}, notFound(err, ErrUnitNotFound)
+	}
+	image, err := repo.GetUnitImage(unitID, imageID)
+	return image, notFound(err, ErrImageNotFound)
+}
+
+func (s *UnitService) DeleteImage(ctx context.Context, propertyID uint, unitID uint, imageID uint) error {
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.UnitRepository) error {
+		if _, err := repo.GetUnit(propertyID, unitID); err != nil {
+			return notFound(err, ErrUnitNotFound)
+		}
+		deleted, err := repo.DeleteUnitImage(unitID, imageID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return ErrImageNotFound
+		}
+		return nil
+	})
+}
+
+func requireUnitNotLeased(repo *repository.UnitRepository, unitID uint) error {
+	leased, err := repo.HasLeaseInForce(unitID)
+	if err != nil {
+		return err
+	}
+	if leased {
+		return ErrUnitLeased
+	}
+	return nil
+}
+
+func checkUnitRent(unit model.Unit) error {
+	if reason := rentReason(unit.Rent, true); reason != "" {
+		return apperror.Validation([]apperror.FieldError{{Field: "rent", Reason: reason}})
+	}
+	return nil
+}
+
+func requireUnitNumberFree(repo *repository.UnitRepository, propertyID uint, number string, excludeID uint) error {
+	taken, err := repo.UnitNumberTaken(propertyID, number, excludeID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrUnitNumberTaken
+	}
+	return nil
+}
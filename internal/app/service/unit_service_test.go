@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+func TestDeleteUnitWithLeaseInForceIsRefused(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	units := NewUnitService(repository.NewUnitRepository(db))
+	if err := db.Create(&model.Property{Name: "Elm"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	unit := model.Unit{PropertyID: 1, Number: "1A", Rent: decimal.NewFromInt(900)}
+	if err := units.CreateUnit(ctx, &unit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := units.AddImage(ctx, 1, unit.ID, "https://s3.example.com/abcd-1a.jpg", 100); err != nil {
+		t.Fatal(err)
+	}
+	lease := model.Lease{PropertyID: 1, UnitID: &unit.ID, Status: model.LeaseStatusActive, StartDate: date("2026-01-01"), EndDate: date("2026-12-31")}
+	if err := db.Create(&lease).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := units.CheckDeleteUnit(ctx, 1, unit.ID); !errors.Is(err, ErrUnitLeased) {
+		t.Errorf("CheckDeleteUnit: err = %v, want ErrUnitLeased", err)
+	}
+	if err := units.DeleteUnit(ctx, 1, unit.ID); !errors.Is(err, ErrUnitLeased) {
+		t.Errorf("DeleteUnit: err = %v, want ErrUnitLeased", err)
+	}
+
+	if err := db.Model(&lease).Update("status", model.LeaseStatusEnded).Error; err != nil {
+		t.Fatal(err)
+	}
+	checked, err := units.CheckDeleteUnit(ctx, 1, unit.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checked.Images) != 1 {
+		t.Fatalf("images to delete = %d, want 1", len(checked.Images))
+	}
+	if err := units.DeleteUnit(ctx, 1, unit.ID); err != nil {
+		t.Fatal(err)
+	}
+	var images int64
+	if err := db.Model(&model.UnitImage{}).Where("unit_id = ?", unit.ID).Count(&images).Error; err != nil {
+		t.Fatal(err)
+	}
+	if images != 0 {
+		t.Errorf("%d images left after deleting the unit, want 0", images)
+	}
+}
+
+func TestUnitCRUD(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	units := NewUnitService(repository.NewUnitRepository(db))
+	for _, name := range []string{"Elm", "Oak"} {
+		if err := db.Create(&model.Property{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := units.CreateUnit(ctx, &model.Unit{PropertyID: 9, Number: "1A", Rent: decimal.NewFromInt(900)}); !errors.Is(err, ErrPropertyNotFound) {
+		t.Errorf("unit of a missing property: err = %v, want ErrPropertyNotFound", err)
+	}
+	if err := units.CreateUnit(ctx, &model.Unit{PropertyID: 1, Number: "1A", Rent: decimal.NewFromInt(-1)}); !errors.Is(err, apperror.Validation(nil)) {
+		t.Errorf("negative rent: err = %v, want a validation error", err)
+	}
+
+	a := model.Unit{PropertyID: 1, Number: "1A", Rent: decimal.NewFromInt(900)}
+	b := model.Unit{PropertyID: 1, Number: "1B", Rent: decimal.NewFromInt(950), Status: model.UnitStatusOccupied}
+	other := model.Unit{PropertyID: 2, Number: "1A", Rent: decimal.NewFromInt(800)}
+	for _, unit := range []*model.Unit{&a, &b, &other} {
+		if err := units.CreateUnit(ctx, unit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.Status != model.UnitStatusVacant {
+		t.Errorf("new unit status = %q, want %q", a.Status, model.UnitStatusVacant)
+	}
+	if err := units.CreateUnit(ctx, &model.Unit{PropertyID: 1, Number: "1A", Rent: decimal.NewFromInt(900)}); !errors.Is(err, ErrUnitNumberTaken) {
+		t.Errorf("duplicate number: err = %v, want ErrUnitNumberTaken", err)
+	}
+
+	vacant, err := units.GetUnits(ctx, 1, model.UnitStatusVacant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vacant) != 1 || vacant[0].ID != a.ID {
+		t.Errorf("vacant units = %v, want only 1A", vacant)
+	}
+	if _, err := units.GetUnits(ctx, 9, ""); !errors.Is(err, ErrPropertyNotFound) {
+		t.Errorf("units of a missing property: err = %v, want ErrPropertyNotFound", err)
+	}
+	if _, err := units.GetUnit(ctx, 1, other.ID); !errors.Is(err, ErrUnitNotFound) {
+		t.Errorf("unit of another property: err = %v, want ErrUnitNotFound", err)
+	}
+
+	update := b
+	update.Number = "1A"
+	if err := units.UpdateUnit(ctx, &update); !errors.Is(err, ErrUnitNumberTaken) {
+		t.Errorf("renumbering to a taken number: err = %v, want ErrUnitNumberTaken", err)
+	}
+	update.Number = "1C"
+	update.Rent = decimal.NewFromInt(975)
+	if err := units.UpdateUnit(ctx, &update); err != nil {
+		t.Fatal(err)
+	}
+	if update.Number != "1C" || !update.Rent.Equal(decimal.NewFromInt(975)) {
+		t.Errorf("updated unit = %s at %s, want 1C at 975", update.Number, update.Rent)
+	}
+
+	image, err := units.AddImage(ctx, 1, a.ID, "https://s3.example.com/abcd-1a.jpg", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := units.AddImage(ctx, 2, a.ID, "https://s3.example.com/abcd-1a.jpg", 100); !errors.Is(err, ErrUnitNotFound) {
+		t.Errorf("image for a unit of another property: err = %v, want ErrUnitNotFound", err)
+	}
+	if _, err := units.GetImage(ctx, 1, b.ID, image.ID); !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("image of another unit: err = %v, want ErrImageNotFound", err)
+	}
+	got, err := units.GetUnit(ctx, 1, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Images) != 1 || got.Images[0].URL != image.URL {
+		t.Errorf("unit images = %v, want the uploaded image", got.Images)
+	}
+	if err := units.DeleteImage(ctx, 1, a.ID, image.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := units.DeleteImage(ctx, 1, a.ID, image.ID); !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("deleting the image again: err = %v, want ErrImageNotFound", err)
+	}
+
+	if err := units.DeleteUnit(ctx, 1, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := units.GetUnit(ctx, 1, a.ID); !errors.Is(err, ErrUnitNotFound) {
+		t.Errorf("deleted unit: err = %v, want ErrUnitNotFound", err)
+	}
+	if err := units.CreateUnit(ctx, &model.Unit{PropertyID: 1, Number: "1A", Rent: decimal.NewFromInt(900)}); err != nil {
+		t.Errorf("reusing the number of a deleted unit: %v", err)
+	}
+}
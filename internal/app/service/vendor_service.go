@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+)
+
+type VendorService struct {
+	repo *repository.VendorRepository
+}
+
+func NewVendorService(repo *repository.VendorRepository) *VendorService {
+	return &VendorService{repo: repo}
+}
+
+// SearchVendors returns the vendors matching filter.
+func (s *VendorService) SearchVendors(ctx context.Context, filter repository.VendorFilter) ([]model.Vendor, error) {
+	vendors, err := s.repo.WithContext(ctx).GetVendors(filter)
+	day := today()
+	for i := range vendors {
+		vendors[i].InsuranceExpired = vendors[i].InsuranceLapsed(day)
+	}
+	return vendors, err
+}
+
+func (s *VendorService) GetVendor(ctx context.Context, id uint) (model.Vendor, error) {
+	vendor, err := s.repo.WithContext(ctx).GetVendor(id)
+	if err != nil {
+		return vendor, notFound(err, ErrVendorNotFound)
+	}
+	vendor.InsuranceExpired = vendor.InsuranceLapsed(today())
+	return vendor, nil
+}
+
+// CreateVendor stores a new vendor. On success vendor holds the stored result.
+func (s *VendorService) CreateVendor(ctx context.Context, vendor *model.Vendor) error {
+	if err := checkRates(*vendor); err != nil {
+		return err
+	}
+	if err := s.repo.WithContext(ctx).CreateVendor(vendor); err != nil {
+		return err
+	}
+	vendor.InsuranceExpired = vendor.InsuranceLapsed(today())
+	return nil
+}
+
+// UpdateVendor overwrites the vendor's details. On success vendor holds the
+// stored result.
+func (s *VendorService) UpdateVendor(ctx context.Context, vendor *model.Vendor) error {
+	if err := checkRates(*vendor); err != nil {
+		return err
+	}
+	updated, err := s.repo.WithContext(ctx).UpdateVendor(vendor)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrVendorNotFound
+	}
+	*vendor, err = s.GetVendor(ctx, vendor.ID)
+	return err
+}
+
+func (s *VendorService) DeleteVendor(ctx context.Context, id uint) error {
+	deleted, err := s.repo.WithContext(ctx).DeleteVendor(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrVendorNotFound
+	}
+	return nil
+}
+
+func checkRates(vendor model.Vendor) error {
+	var fields []apperror.FieldError
+	if reason := amountReason(vendor.HourlyRate, true); reason != "" {
+		fields = append(fields, apperror.FieldError{Field: "hourly_rate", Reason: reason})
+	}
+	if reason := amountReason(vendor.CalloutFee, true); reason != "" {
+		fields = append(fields, apperror.FieldError{Field: "callout_fee", Reason: reason})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
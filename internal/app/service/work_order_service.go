@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/config"
+)
+
+type WorkOrderService struct {
+	repo *repository.WorkOrderRepository
+	cfg  *config.WorkOrderConfig
+}
+
+func NewWorkOrderService(repo *repository.WorkOrderRepository, cfg *config.WorkOrderConfig) *WorkOrderService {
+	return &WorkOrderService{repo: repo, cfg: cfg}
+}
+
+// SearchWorkOrders returns the work orders matching filter.
+func (s *WorkOrderService) SearchWorkOrders(ctx context.Context, filter repository.WorkOrderFilter) ([]model.WorkOrder, error) {
+	return s.repo.WithContext(ctx).GetWorkOrders(filter)
+}
+
+// GetRequestWorkOrders returns the work orders issued for a maintenance
+// request.
+func (s *WorkOrderService) GetRequestWorkOrders(ctx context.Context, requestID uint) ([]model.WorkOrder, error) {
+	repo := s.repo.WithContext(ctx)
+	if _, err := repo.GetMaintenanceRequest(requestID); err != nil {
+		return nil, notFound(err, ErrMaintenanceNotFound)
+	}
+	return repo.GetWorkOrders(repository.WorkOrderFilter{MaintenanceRequestID: requestID})
+}
+
+func (s *WorkOrderService) GetWorkOrder(ctx context.Context, id uint) (model.WorkOrder, error) {
+	order, err := s.repo.WithContext(ctx).GetWorkOrder(id)
+	return order, notFound(err, ErrWorkOrderNotFound)
+}
+
+// IssueWorkOrder issues a work order for a maintenance request that is not
+// closed to a vendor whose insurance has not lapsed, on behalf of actor.
+// Orders estimated above the approval threshold wait for approval; others
+// are approved straight away. An unassigned request is assigned to the
+// vendor. On success order holds the stored result.
+func (s *WorkOrderService) IssueWorkOrder(ctx context.Context, order *model.WorkOrder, actor string) error {
+	if reason := amountReason(order.Estimate, false); reason != "" {
+		return apperror.Validation([]apperror.FieldError{{Field: "estimate", Reason: reason}})
+	}
+
+	return s.repo.WithContext(ctx).Transaction(func(repo *repository.WorkOrderRepository) error {
+		request, err := repo.GetMaintenanceRequest(order.MaintenanceRequestID)
+		if err != nil {
+			return notFound(err, ErrMaintenanceNotFound)
+		}
+		if request.Status == model.MaintenanceStatusClosed {
+			return ErrMaintenanceClosed
+		}
+		vendor, err := repo.GetVendor(order.VendorID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.Validation([]apperror.FieldError{{Field: "vendor_id", Reason: "does not exist"}})
+		}
+		if err != nil {
+			return err
+		}
+		if vendor.InsuranceLapsed(today()) {
+			return apperror.Validation([]apperror.FieldError{{Field: "vendor_id", Reason: "has insurance that expired on " + vendor.InsuranceExpiresOn.Format(time.DateOnly)}})
+		}
+
+		order.PropertyID = request.PropertyID
+		order.UnitID = request.UnitID
+		order.IssuedBy = actor
+		order.Status = model.WorkOrderStatusPendingApproval
+		if !order.Estimate.GreaterThan(s.cfg.ApprovalThreshold) {
+			now := time.Now().UTC()
+			order.Status = model.WorkOrderStatusApproved
+			order.ApprovedBy = SystemActor
+			order.ApprovedAt = &now
+		}
+		if err := repo.CreateWorkOrder(order); err != nil {
+			return err
+		}
+
+		if request.Assignee == "" {
+			fields := map[string]interface{}{"assignee": vendor.Name}
+			if request.Status == model.MaintenanceStatusOpen {
+				fields["status"] = model.MaintenanceStatusAssigned
+			}
+			if err := repo.UpdateMaintenanceRequestFields(request.ID, fields); err != nil {
+				return err
+			}
+		}
+		body := fmt.Sprintf("Work order %d issued to %s, estimated at %s.", order.ID, vendor.Name, order.Estimate.StringFixed(2))
+		if order.Status == model.WorkOrderStatusPendingApproval {
+			body += " It needs approval before work starts."
+		}
+		if err := repo.CreateComment(&model.MaintenanceComment{RequestID: request.ID, Author: actor, Body: body}); err != nil {
+			return err
+		}
+
+		issued, err := repo.GetWorkOrder(order.ID)
+		*order = issued
+		return err
+	})
+}
+
+// ApproveWorkOrder approves a work order pending approval on behalf of
+// actor, who must not be the user who issued it. An order sent back for
+// approval by an invoice above its estimate becomes invoiced.
+func (s *WorkOrderService) ApproveWorkOrder(ctx context.Context, id uint, actor string) (model.WorkOrder, error) {
+	return s.transition(ctx, id, model.WorkOrderStatusApproved, actor, func(repo *repository.WorkOrderRepository, order model.WorkOrder) (map[string]interface{}, string, error) {
+		if order.IssuedBy == actor {
+			return nil, "", ErrSelfApproval
+		}
+		fields := map[string]interface{}{"approved_by": actor, "approved_at": time.Now().UTC()}
+		if order.InvoiceAmount != nil {
+			fields["status"] = model.WorkOrderStatusInvoiced
+			return fields, fmt.Sprintf("Invoice %s for %s for work order %d approved by %s.", order.InvoiceNumber, order.InvoiceAmount.StringFixed(2), order.ID, actor), nil
+		}
+		return fields, fmt.Sprintf("Work order %d approved by %s.", order.ID, actor), nil
+	})
+}
+
+// RejectWorkOrder rejects a work order pending approval on behalf of actor.
+func (s *WorkOrderService) RejectWorkOrder(ctx context.Context, id uint, reason string, actor string) (model.WorkOrder, error) {
+	return s.transition(ctx, id, model.WorkOrderStatusRejected, actor, func(repo *repository.WorkOrderRepository, order model.WorkOrder) (map[string]interface{}, string, error) {
+		return map[string]interface{}{"status_reason": reason},
+			fmt.Sprintf("Work order %d rejected by %s: %s", order.ID, actor, reason), nil
+	})
+}
+
+// CancelWorkOrder cancels a work order that has not been invoiced on
+// behalf of actor. An order waiting for its invoice to be approved has been
+// invoiced, so it can only be rejected.
+func (s *WorkOrderService) CancelWorkOrder(ctx context.Context, id uint, reason string, actor string) (model.WorkOrder, error) {
+	return s.transition(ctx, id, model.WorkOrderStatusCancelled, actor, func(repo *repository.WorkOrderRepository, order model.WorkOrder) (map[string]interface{}, string, error) {
+		if order.InvoiceAmount != nil {
+			return nil, "", ErrInvalidWorkOrderTransition
+		}
+		return map[string]interface{}{"status_reason": reason},
+			fmt.Sprintf("Work order %d cancelled by %s: %s", order.ID, actor, reason), nil
+	})
+}
+
+// RecordInvoice records the vendor's invoice for an approved work order.
+// An invoice above both the order's estimate and the approval threshold
+// sends the order back for approval.
+func (s *WorkOrderService) RecordInvoice(ctx context.Context, id uint, number string, date time.Time, amount decimal.Decimal, actor string) (model.WorkOrder, error) {
+	if reason := amountReason(amount, false); reason != "" {
+		return model.WorkOrder{}, apperror.Validation([]apperror.FieldError{{Field: "amount", Reason: reason}})
+	}
+	return s.transition(ctx, id, model.WorkOrderStatusInvoiced, actor, func(repo *repository.WorkOrderRepository, order model.WorkOrder) (map[string]interface{}, string, error) {
+		fields := map[string]interface{}{"invoice_number": number, "invoice_date": date, "invoice_amount": amount}
+		body := fmt.Sprintf("Invoice %s for %s received for work order %d.", number, amount.StringFixed(2), order.ID)
+		if amount.GreaterThan(order.Estimate) && amount.GreaterThan(s.cfg.ApprovalThreshold) {
+			fields["status"] = model.WorkOrderStatusPendingApproval
+			fields["approved_by"] = ""
+			fields["approved_at"] = nil
+			body += fmt.Sprintf(" It exceeds the estimate of %s and needs approval.", order.Estimate.StringFixed(2))
+		}
+		return fields, body, nil
+	})
+}
+
+// CompleteWorkOrder signs off the work of an invoiced work order on behalf
+// of actor and records its invoice as an expense of the property.
+func (s *WorkOrderService) CompleteWorkOrder(ctx context.Context, id uint, notes string, actor string) (model.WorkOrder, error) {
+	return s.transition(ctx, id, model.WorkOrderStatusCompleted, actor, func(repo *repository.WorkOrderRepository, order model.WorkOrder) (map[string]interface{}, string, error) {
+		request, err := repo.GetMaintenanceRequest(order.MaintenanceRequestID)
+		if err != nil {
+			return nil, "", err
+		}
+		err = repo.CreateExpenseOnce(&model.Expense{
+			PropertyID:  order.PropertyID,
+			UnitID:      order.UnitID,
+			Date:        *order.InvoiceDate,
+			Category:    request.Category,
+			Description: fmt.Sprintf("Work order %d: %s", order.ID, order.Description),
+			Amount:      *order.InvoiceAmount,
+			VendorID:    &order.VendorID,
+			WorkOrderID: &order.ID,
+			CreatedBy:   actor,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		slog.InfoContext(ctx, "Recorded work order expense", "work_order_id", order.ID, "property_id", order.PropertyID, "amount", order.InvoiceAmount.String())
+
+		fields := map[string]interface{}{"completed_by": actor, "completed_at": time.Now().UTC(), "sign_off_notes": notes}
+		body := fmt.Sprintf("Work order %d signed off as complete by %s.", order.ID, actor)
+		if notes != "" {
+			body += "\n\n" + notes
+		}
+		return fields, body, nil
+	})
+}
+
+// transitionFunc returns the fields to write when a work order changes
+// status and the comment recording the change. Setting the status field
+// moves the order to that status instead.
+type transitionFunc func(repo *repository.WorkOrderRepository, order model.WorkOrder) (map[string]interface{}, string, error)
+
+// transition moves a work order to status if its current status allows,
+// writing the fields returned by apply and recording the comment it
+// returns on the maintenance request.
+func (s *WorkOrderService) transition(ctx context.Context, id uint, status string, actor string, apply transitionFunc) (model.WorkOrder, error) {
+	var order model.WorkOrder
+	err := s.repo.WithContext(ctx).Transaction(func(repo *repository.WorkOrderRepository) error {
+		current, err := repo.GetWorkOrder(id)
+		if err != nil {
+			return notFound(err, ErrWorkOrderNotFound)
+		}
+		if !current.CanTransition(status) {
+			return ErrInvalidWorkOrderTransition
+		}
+
+		fields, comment, err := apply(repo, current)
+		if err != nil {
+			return err
+		}
+		if _, ok := fields["status"]; !ok {
+			fields["status"] = status
+		}
+		if err := repo.UpdateWorkOrderFields(id, fields); err != nil {
+			return err
+		}
+		if err := repo.CreateComment(&model.MaintenanceComment{RequestID: current.MaintenanceRequestID, Author: actor, Body: comment}); err != nil {
+			return err
+		}
+		order, err = repo.GetWorkOrder(id)
+		return err
+	})
+	return order, err
+}
+
+// GetExpenseHistory returns the expenses of a property dated from from to
+// to inclusive.
+func (s *WorkOrderService) GetExpenseHistory(ctx context.Context, propertyID uint, from, to time.Time) (model.ExpenseHistory, error) {
+	repo := s.repo.WithContext(ctx)
+	if err := requireProperty(repo, propertyID); err != nil {
+		return model.ExpenseHistory{}, err
+	}
+
+	expenses, err := repo.GetExpenses(propertyID, from, to)
+	if err != nil {
+		return model.ExpenseHistory{}, err
+	}
+	return model.NewExpenseHistory(propertyID, from, to, expenses), nil
+}
+
+// amountReason returns why amount is not a valid amount of money, or "" if
+// it is. Zero is valid only if allowZero is set.
+func amountReason(amount decimal.Decimal, allowZero bool) string {
+	switch {
+	case !allowZero && !amount.IsPositive():
+		return "must be greater than 0"
+	case amount.IsNegative():
+		return "must not be negative"
+	case !amount.Equal(amount.Round(2)):
+		return "must have at most 2 decimal places"
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"propmanager/internal/app/model"
+	"propmanager/internal/app/repository"
+	"propmanager/internal/config"
+)
+
+func newWorkOrderTest(t *testing.T) *WorkOrderService {
+	t.Helper()
+	db := newTestDB(t)
+	for _, record := range []interface{}{
+		&model.Property{Name: "Elm"},
+		&model.MaintenanceRequest{PropertyID: 1, Category: "plumbing", Priority: model.MaintenancePriorityNormal, Description: "Leak", Status: model.MaintenanceStatusOpen},
+		&model.Vendor{Name: "Pipes Ltd", Trade: "plumbing"},
+	} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.WorkOrderConfig{ApprovalThreshold: decimal.NewFromInt(500)}
+	return NewWorkOrderService(repository.NewWorkOrderRepository(db), cfg)
+}
+
+func TestIssueWorkOrderApprovalThreshold(t *testing.T) {
+	ctx := context.Background()
+	workOrders := newWorkOrderTest(t)
+
+	tests := []struct {
+		estimate string
+		want     string
+	}{
+		{"120.00", model.WorkOrderStatusApproved},
+		{"500.00", model.WorkOrderStatusApproved},
+		{"500.01", model.WorkOrderStatusPendingApproval},
+	}
+	for _, tt := range tests {
+		order := model.WorkOrder{MaintenanceRequestID: 1, VendorID: 1, Description: "Fix leak", Estimate: decimal.RequireFromString(tt.estimate)}
+		if err := workOrders.IssueWorkOrder(ctx, &order, "alice"); err != nil {
+			t.Fatal(err)
+		}
+		if order.Status != tt.want {
+			t.Errorf("estimate %s: Status = %s, want %s", tt.estimate, order.Status, tt.want)
+		}
+		if approved := order.Status == model.WorkOrderStatusApproved; approved != (order.ApprovedBy == SystemActor) {
+			t.Errorf("estimate %s: ApprovedBy = %q", tt.estimate, order.ApprovedBy)
+		}
+	}
+}
+
+func TestWorkOrderInvoiceAboveEstimateNeedsApproval(t *testing.T) {
+	ctx := context.Background()
+	workOrders := newWorkOrderTest(t)
+
+	order := model.WorkOrder{MaintenanceRequestID: 1, VendorID: 1, Description: "Replace boiler", Estimate: decimal.NewFromInt(2000)}
+	if err := workOrders.IssueWorkOrder(ctx, &order, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workOrders.ApproveWorkOrder(ctx, order.ID, "alice"); !errors.Is(err, ErrSelfApproval) {
+		t.Fatalf("approval by the issuer: err = %v, want ErrSelfApproval", err)
+	}
+	if _, err := workOrders.ApproveWorkOrder(ctx, order.ID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	invoiced, err := workOrders.RecordInvoice(ctx, order.ID, "INV-1", date("2026-03-02"), decimal.NewFromInt(2400), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoiced.Status != model.WorkOrderStatusPendingApproval || invoiced.ApprovedBy != "" || invoiced.ApprovedAt != nil {
+		t.Fatalf("after an invoice above the estimate: Status = %s, ApprovedBy = %q, ApprovedAt = %v; want pending approval",
+			invoiced.Status, invoiced.ApprovedBy, invoiced.ApprovedAt)
+	}
+	if _, err := workOrders.CompleteWorkOrder(ctx, order.ID, "", "alice"); !errors.Is(err, ErrInvalidWorkOrderTransition) {
+		t.Fatalf("sign-off before the invoice is approved: err = %v, want ErrInvalidWorkOrderTransition", err)
+	}
+	if _, err := workOrders.CancelWorkOrder(ctx, order.ID, "Too dear", "bob"); !errors.Is(err, ErrInvalidWorkOrderTransition) {
+		t.Fatalf("cancelling an invoiced order: err = %v, want ErrInvalidWorkOrderTransition", err)
+	}
+
+	approved, err := workOrders.ApproveWorkOrder(ctx, order.ID, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != model.WorkOrderStatusInvoiced || !approved.InvoiceAmount.Equal(decimal.NewFromInt(2400)) {
+		t.Errorf("after approving the invoice: Status = %s, InvoiceAmount = %v; want invoiced for 2400", approved.Status, approved.InvoiceAmount)
+	}
+	if _, err := workOrders.CompleteWorkOrder(ctx, order.ID, "", "alice"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,201 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"propmanager/internal/app/apperror"
+)
+
+// ErrMalformed is returned when a request body is not a valid JSON object.
+var ErrMalformed = apperror.BadRequest("malformed_body", "The request body is not a valid JSON object.")
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Decode reads a JSON object from r into obj and validates it against its
+// `validate` tags. Fields obj does not declare are reported as invalid.
+func Decode(r io.Reader, obj interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return DecodeBytes(data, obj)
+}
+
+// DecodeBytes is Decode for an in-memory document.
+func DecodeBytes(data []byte, obj interface{}) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ErrMalformed.Wrap(err)
+	}
+	if raw == nil {
+		return ErrMalformed
+	}
+
+	var fields []apperror.FieldError
+
+	known := jsonFieldNames(reflect.TypeOf(obj))
+	for name := range raw {
+		if !known[name] {
+			fields = append(fields, apperror.FieldError{Field: name, Reason: "is not allowed"})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+
+	if err := json.Unmarshal(data, obj); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if !errors.As(err, &typeError) {
+			return ErrMalformed.Wrap(err)
+		}
+		fields = append(fields, apperror.FieldError{Field: typeError.Field, Reason: "must be of type " + typeError.Type.String()})
+		return apperror.Validation(fields)
+	}
+
+	if err := Struct(obj); err != nil {
+		appErr, ok := apperror.As(err)
+		if !ok {
+			return err
+		}
+		fields = append(fields, appErr.Fields...)
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+// Struct validates obj against its `validate` tags.
+func Struct(obj interface{}) error {
+	err := validate.Struct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, apperror.FieldError{
+			Field:  fieldName(fieldError),
+			Reason: reason(fieldError),
+		})
+	}
+	return apperror.Validation(fields)
+}
+
+// jsonFieldNames returns the top-level JSON keys a struct type accepts.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	names := map[string]bool{}
+	if t.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		switch {
+		case name == "-":
+			continue
+		case field.Anonymous && name == "":
+			for embedded := range jsonFieldNames(field.Type) {
+				names[embedded] = true
+			}
+		case name == "":
+			names[field.Name] = true
+		default:
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// fieldName returns the dotted JSON path of the field without the top-level struct name.
+func fieldName(fieldError validator.FieldError) string {
+	namespace := fieldError.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func reason(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	kind := fieldError.Kind()
+	if kind == reflect.Ptr {
+		kind = fieldError.Type().Elem().Kind()
+	}
+	isString := kind == reflect.String
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required unless " + strings.ToLower(param) + " is given"
+	case "min":
+		if isString {
+			return "must be at least " + param + " characters long"
+		}
+		return "must be at least " + param
+	case "max":
+		if isString {
+			return "must be at most " + param + " characters long"
+		}
+		return "must be at most " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "datetime":
+		switch param {
+		case "2006-01-02":
+			return "must be a date formatted as YYYY-MM-DD"
+		case time.RFC3339:
+			return "must be a date and time formatted as RFC 3339, such as 2025-03-01T10:00:00Z"
+		}
+		return "must be formatted as " + param
+	default:
+		return "failed " + fieldError.Tag() + " validation"
+	}
+}
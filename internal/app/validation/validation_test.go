@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"propmanager/internal/app/apperror"
+	"propmanager/internal/app/dto"
+)
+
+func TestDecodeProperty(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		malformed bool
+		want      []apperror.FieldError
+	}{
+		{
+			name: "valid",
+			body: `{"name":"Elm","price":1000,"location":"1 Elm St","status":"rented"}`,
+		},
+		{
+			name: "missing and out of range",
+			body: `{"name":"","price":-1,"status":"demolished"}`,
+			want: []apperror.FieldError{
+				{Field: "name", Reason: "is required"},
+				{Field: "price", Reason: "must be greater than or equal to 0"},
+				{Field: "location", Reason: "is required"},
+				{Field: "status", Reason: "must be one of: available, rented, sold, off_market"},
+			},
+		},
+		{
+			name: "server-assigned fields",
+			body: `{"id":7,"images":[],"name":"Elm","price":1000,"location":"1 Elm St"}`,
+			want: []apperror.FieldError{
+				{Field: "id", Reason: "is not allowed"},
+				{Field: "images", Reason: "is not allowed"},
+			},
+		},
+		{
+			name: "wrong type",
+			body: `{"name":"Elm","price":"cheap","location":"1 Elm St"}`,
+			want: []apperror.FieldError{{Field: "price", Reason: "must be of type float64"}},
+		},
+		{name: "not an object", body: `["Elm"]`, malformed: true},
+		{name: "null", body: `null`, malformed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request dto.CreatePropertyRequest
+			err := DecodeBytes([]byte(tt.body), &request)
+			switch {
+			case tt.malformed:
+				if !errors.Is(err, ErrMalformed) {
+					t.Errorf("err = %v, want ErrMalformed", err)
+				}
+			case tt.want == nil:
+				if err != nil {
+					t.Errorf("err = %v, want nil", err)
+				}
+			default:
+				appErr, ok := apperror.As(err)
+				if !ok || appErr.HTTPStatus() != http.StatusUnprocessableEntity {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+				if !reflect.DeepEqual(appErr.Fields, tt.want) {
+					t.Errorf("fields = %+v, want %+v", appErr.Fields, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -1,25 +1,15 @@
 package config
 
 import (
-	"os"
-
 	"propmanager/internal/app/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthConfig struct {
-	Username  string
-	Password  string
-	SecretKey string
-}
-
-func LoadAuthConfig() AuthConfig {
-	return AuthConfig{
-		Username:  os.Getenv("AUTH_USERNAME"),
-		Password:  os.Getenv("AUTH_PASSWORD"),
-		SecretKey: os.Getenv("AUTH_SECRET_KEY"),
-	}
+	Username  string `config:"username" validate:"required" usage:"login username"`
+	Password  string `config:"password" secret:"true" validate:"required" usage:"login password"`
+	SecretKey string `config:"secret_key" secret:"true" validate:"required" usage:"key that signs JWT tokens"`
 }
 
 func (cfg *AuthConfig) AuthMiddleware() gin.HandlerFunc {
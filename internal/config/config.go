@@ -1,33 +1,92 @@
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
+// Config is the effective configuration of the server. Each setting is
+// identified by its `config` key, which names it in config files; the
+// environment variable and command-line flag are derived from the key, so
+// "s3.bucket" is set by S3_BUCKET and -s3-bucket. Sources are applied in
+// increasing order of precedence: `default` tags, the config file, the
+// environment (including .env) and flags. Any environment variable may
+// instead be read from the file named by the same variable with a _FILE
+// suffix, and secret settings may reference an external store; see
+// ResolveSecrets.
 type Config struct {
-	S3Endpoint  string
-	S3Region    string
-	S3Bucket    string
-	S3AccessKey string
-	S3SecretKey string
-	Port        string
+	Port int `config:"port" default:"8080" validate:"min=1,max=65535" usage:"port to listen on"`
+
+	LogLevel  string `config:"log.level" default:"info" validate:"oneof=debug info warn warning error" usage:"minimum log level"`
+	LogFormat string `config:"log.format" default:"json" validate:"oneof=json text" usage:"log output format"`
+
+	// TracingExporter selects where spans are sent: "otlp", or "none".
+	TracingExporter string `config:"tracing.exporter" default:"none" validate:"oneof=none otlp" usage:"trace exporter; OTLP is configured by the OTEL_EXPORTER_OTLP_* variables"`
+
+	// MetricsAddr is the address of a separate listener that serves
+	// /metrics to scrapers without authentication; it should be reachable
+	// only from the internal network. Without it, /metrics is served on the
+	// main listener to authenticated callers only.
+	MetricsAddr string `config:"metrics.addr" validate:"omitempty,hostname_port" usage:"internal address, such as 127.0.0.1:9090, that serves /metrics without authentication; if empty, /metrics requires authentication"`
+
+	S3Endpoint  string `config:"s3.endpoint" validate:"required,url" usage:"S3 endpoint URL"`
+	S3Region    string `config:"s3.region" validate:"required" usage:"S3 region"`
+	S3Bucket    string `config:"s3.bucket" validate:"required" usage:"bucket that stores property images"`
+	S3AccessKey string `config:"s3.access_key" secret:"true" validate:"required" usage:"S3 access key ID"`
+	S3SecretKey string `config:"s3.secret_key" secret:"true" validate:"required" usage:"S3 secret access key"`
+
+	// HTTP server limits. WriteTimeout bounds the whole handler, including
+	// image uploads to S3, so it is generous by default.
+	ReadTimeout       time.Duration `config:"http.read_timeout" default:"30s" validate:"gt=0" usage:"maximum time to read a request, including the body"`
+	ReadHeaderTimeout time.Duration `config:"http.read_header_timeout" default:"5s" validate:"gt=0" usage:"maximum time to read request headers"`
+	WriteTimeout      time.Duration `config:"http.write_timeout" default:"60s" validate:"gt=0" usage:"maximum time to handle a request and write the response"`
+	IdleTimeout       time.Duration `config:"http.idle_timeout" default:"120s" validate:"gt=0" usage:"how long keep-alive connections may idle"`
+	MaxHeaderBytes    int           `config:"http.max_header_bytes" default:"1048576" validate:"gt=0" usage:"maximum size of request headers in bytes"`
+
+	// On SIGINT or SIGTERM the server reports not-ready for
+	// ShutdownDrainDelay, then waits up to ShutdownTimeout for in-flight
+	// requests to finish.
+	ShutdownDrainDelay time.Duration `config:"shutdown.drain_delay" default:"5s" validate:"gte=0" usage:"how long to report not-ready before draining"`
+	ShutdownTimeout    time.Duration `config:"shutdown.timeout" default:"30s" validate:"gt=0" usage:"maximum time to drain in-flight requests"`
+
+	// TLS is enabled when a certificate and key are configured. The files
+	// are checked for renewal every TLSReloadInterval.
+	TLSCertFile       string        `config:"tls.cert_file" usage:"PEM certificate chain; enables HTTPS"`
+	TLSKeyFile        string        `config:"tls.key_file" usage:"PEM private key for tls.cert_file"`
+	TLSReloadInterval time.Duration `config:"tls.reload_interval" default:"1m" validate:"gt=0" usage:"how often to check the certificate files for changes"`
+	TLSClientAuth     string        `config:"tls.client_auth" default:"none" validate:"oneof=none optional require" usage:"verify client certificates against tls.client_ca_file"`
+	TLSClientCAFile   string        `config:"tls.client_ca_file" usage:"PEM CA certificates for client certificate authentication"`
+	TLSRedirectPort   int           `config:"tls.redirect_port" validate:"min=0,max=65535" usage:"plain HTTP port that redirects to HTTPS; 0 disables it"`
+	HSTSMaxAge        time.Duration `config:"tls.hsts_max_age" default:"8760h" validate:"gte=0" usage:"Strict-Transport-Security max-age; 0 disables the header"`
+
+	Auth AuthConfig `config:"auth"`
+
+	// StatsCacheTTL is how long /stats reuses the statistics it computed
+	// for the same date range.
+	StatsCacheTTL time.Duration `config:"stats.cache_ttl" default:"5m" validate:"gte=0" usage:"how long to cache portfolio statistics; 0 disables caching"`
+
+	// Secret stores that secret settings can reference, and how often to
+	// re-read them so that rotated S3 credentials are picked up.
+	SecretsRefreshInterval time.Duration `config:"secrets.refresh_interval" default:"5m" validate:"gte=0" usage:"how often to re-read secrets; 0 disables refresh"`
+	SecretsLocalFile       string        `config:"secrets.local_file" usage:"JSON file of secrets for local:<path>#<key> references"`
+	VaultAddr              string        `config:"vault.addr" validate:"omitempty,url" usage:"Vault server address for vault:<path>#<key> references"`
+	VaultToken             string        `config:"vault.token" secret:"true" usage:"Vault token"`
+	VaultMount             string        `config:"vault.mount" default:"secret" usage:"mount path of the Vault KV v2 engine"`
+
+	// Rent is charged to leases on their billing day, and late fees on rent
+	// left unpaid, by a background job that runs every LedgerPostingInterval.
+	// The same job ends renewed leases once their term is over.
+	LedgerPostingInterval time.Duration `config:"ledger.posting_interval" default:"1h" validate:"gte=0" usage:"how often to post rent and late fees that have fallen due; 0 disables automatic posting"`
+
+	LateFees LateFeeConfig `config:"late_fees"`
+
+	Payments PaymentsConfig `config:"payments"`
+
+	Maintenance MaintenanceConfig `config:"maintenance"`
+
+	WorkOrders WorkOrderConfig `config:"work_orders"`
 }
 
-func LoadConfig() Config {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-
-	return Config{
-		S3Endpoint:  os.Getenv("S3_ENDPOINT"),
-		S3Region:    os.Getenv("S3_REGION"),
-		S3Bucket:    os.Getenv("S3_BUCKET"),
-		S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
-		S3SecretKey: os.Getenv("S3_SECRET_KEY"),
-		Port:        os.Getenv("PORT"),
-	}
+// TLSEnabled reports whether the server should serve HTTPS.
+func (cfg Config) TLSEnabled() bool {
+	return cfg.TLSCertFile != ""
 }
@@ -0,0 +1,37 @@
+package config
+
+import "github.com/shopspring/decimal"
+
+// Late fee rules.
+const (
+	LateFeeRuleNone       = "none"
+	LateFeeRuleFlat       = "flat"
+	LateFeeRulePercentage = "percentage"
+	LateFeeRuleDaily      = "daily"
+)
+
+// LateFeeConfig sets the fees charged on rent left unpaid after the grace
+// period. The flat rule charges Amount once, the percentage rule charges
+// Percent of the unpaid rent once, and the daily rule charges Amount for
+// every further day it stays unpaid, up to Cap in all.
+type LateFeeConfig struct {
+	Rule      string          `config:"rule" default:"none" validate:"oneof=none flat percentage daily" usage:"late fee rule: none, flat, percentage or daily"`
+	GraceDays int             `config:"grace_days" default:"5" validate:"gte=0" usage:"days after rent falls due before it is late"`
+	Amount    decimal.Decimal `config:"amount" default:"50" usage:"fee for the flat rule, or per day for the daily rule"`
+	Percent   decimal.Decimal `config:"percent" default:"5" usage:"percentage of the unpaid rent charged by the percentage rule"`
+	Cap       decimal.Decimal `config:"cap" default:"0" usage:"most the daily rule charges for one late rent charge"`
+}
+
+func (cfg LateFeeConfig) check() []string {
+	var problems []string
+	if cfg.Amount.IsNegative() || !cfg.Amount.Equal(cfg.Amount.Round(2)) {
+		problems = append(problems, "late_fees.amount (LATE_FEES_AMOUNT): must be a non-negative amount with at most 2 decimal places")
+	}
+	if cfg.Percent.IsNegative() || cfg.Percent.GreaterThan(decimal.NewFromInt(100)) {
+		problems = append(problems, "late_fees.percent (LATE_FEES_PERCENT): must be between 0 and 100")
+	}
+	if cfg.Rule == LateFeeRuleDaily && !cfg.Cap.IsPositive() {
+		problems = append(problems, "late_fees.cap (LATE_FEES_CAP): must be greater than 0 when late_fees.rule is daily")
+	}
+	return problems
+}
@@ -0,0 +1,389 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the environment variable that points at a config file
+// when the -config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Error lists every setting that could not be parsed or failed validation.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// setting is one leaf field of Config.
+type setting struct {
+	key    string
+	value  reflect.Value
+	field  reflect.StructField
+	secret bool
+}
+
+func (s setting) envName() string {
+	return strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+// describe names the setting by its key and environment variable.
+func (s setting) describe() string {
+	return s.key + " (" + s.envName() + ")"
+}
+
+// Load builds the configuration from defaults, the config file named by the
+// -config flag or CONFIG_FILE, the environment, a .env file in the working
+// directory if there is one, and the flags in args. It returns the
+// configuration even when it is invalid, together with an *Error listing
+// every problem, so that callers can still show what was loaded. It returns
+// flag.ErrHelp if args ask for usage.
+func Load(args []string) (Config, error) {
+	var cfg Config
+	settings := settingsOf(&cfg)
+	var problems []string
+	// unparsed holds the keys of settings that failed to parse, which
+	// validation would otherwise report a second time.
+	unparsed := map[string]bool{}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		problems = append(problems, "reading .env: "+err.Error())
+	}
+
+	flags, configFile, err := parseFlags(settings, args)
+	if err != nil {
+		return cfg, err
+	}
+	if *configFile == "" {
+		*configFile = os.Getenv(ConfigFileEnv)
+	}
+
+	for _, s := range settings {
+		if def, ok := s.field.Tag.Lookup("default"); ok {
+			if err := setValue(s.value, def); err != nil {
+				panic(fmt.Sprintf("config: bad default for %s: %v", s.key, err))
+			}
+		}
+	}
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		byKey := map[string]setting{}
+		for _, s := range settings {
+			byKey[s.key] = s
+		}
+		for _, key := range sortedKeys(values) {
+			s, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown setting %q", *configFile, key))
+				continue
+			}
+			if err := setValue(s.value, values[key]); err != nil {
+				problems = append(problems, fmt.Sprintf("%s in %s: %v", s.key, *configFile, err))
+				unparsed[s.key] = true
+			}
+		}
+	}
+
+	for _, s := range settings {
+		value, ok, err := lookupEnv(s.envName())
+		if err != nil {
+			problems = append(problems, err.Error())
+			unparsed[s.key] = true
+			continue
+		}
+		if ok {
+			if err := setValue(s.value, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", s.envName(), err))
+				unparsed[s.key] = true
+			}
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flagName() != f.Name {
+				continue
+			}
+			if err := setValue(s.value, f.Value.String()); err != nil {
+				problems = append(problems, fmt.Sprintf("-%s: %v", f.Name, err))
+				unparsed[s.key] = true
+			}
+		}
+	})
+
+	problems = append(problems, validate(&cfg, settings, unparsed)...)
+	problems = append(problems, checkDependencies(cfg)...)
+	if len(problems) > 0 {
+		return cfg, &Error{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// lookupEnv returns the value of the environment variable name or, if
+// name_FILE is set instead, the contents of the file it names without a
+// trailing newline, as Docker and Kubernetes secrets are mounted.
+func lookupEnv(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(name)
+	path, fromFile := os.LookupEnv(name + "_FILE")
+	if !fromFile {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("%s and %s_FILE are both set; set only one", name, name)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %v", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// parseFlags declares a string flag for every setting, plus -config, and
+// parses args. Flag values are applied by Load after the other sources.
+func parseFlags(settings []setting, args []string) (*flag.FlagSet, *string, error) {
+	flags := flag.NewFlagSet("propmanager", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configFile := flags.String("config", "", "path to a YAML or TOML config file (env "+ConfigFileEnv+")")
+	for _, s := range settings {
+		usage := s.field.Tag.Get("usage") + " (env " + s.envName() + ")"
+		flags.String(s.flagName(), s.field.Tag.Get("default"), usage)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flags.SetOutput(os.Stderr)
+			flags.PrintDefaults()
+		}
+		return nil, nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+	return flags, configFile, nil
+}
+
+// settingsOf returns the leaf settings of cfg, descending into nested
+// structs such as AuthConfig.
+func settingsOf(cfg *Config) []setting {
+	return collectSettings(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func collectSettings(value reflect.Value, prefix string) []setting {
+	var settings []setting
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key, ok := field.Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		key = prefix + key
+		if isGroup(field.Type) {
+			settings = append(settings, collectSettings(value.Field(i), key+".")...)
+			continue
+		}
+		settings = append(settings, setting{
+			key:    key,
+			value:  value.Field(i),
+			field:  field,
+			secret: field.Tag.Get("secret") == "true",
+		})
+	}
+	return settings
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	decimalType  = reflect.TypeOf(decimal.Decimal{})
+)
+
+// isGroup reports whether a field of type t groups nested settings, such
+// as AuthConfig, rather than holding a value.
+func isGroup(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != decimalType
+}
+
+// setValue parses raw into the string, integer, boolean, duration or
+// decimal v.
+func setValue(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Type() == decimalType:
+		d, err := decimal.NewFromString(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a decimal number", raw)
+		}
+		v.Set(reflect.ValueOf(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		v.SetBool(b)
+	default:
+		panic("config: unsupported setting type " + v.Type().String())
+	}
+	return nil
+}
+
+// readFile reads a YAML (.yaml, .yml) or TOML (.toml) file of nested tables
+// and returns its values keyed by dotted path, such as "s3.bucket".
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	tree := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten(tree, "", values)
+	return values, nil
+}
+
+func flatten(tree map[string]interface{}, prefix string, values map[string]string) {
+	for key, value := range tree {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(nested, prefix+key+".", values)
+			continue
+		}
+		values[prefix+key] = fmt.Sprint(value)
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validate checks cfg against its `validate` tags and describes each
+// failure by setting key and environment variable, skipping the settings in
+// unparsed.
+func validate(cfg *Config, settings []setting, unparsed map[string]bool) []string {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("config")
+	})
+
+	err := v.Struct(cfg)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+
+	byKey := map[string]setting{}
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+	problems := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		// The namespace is "Config." followed by the dotted config key.
+		key := strings.TrimPrefix(fieldError.Namespace(), "Config.")
+		if unparsed[key] {
+			continue
+		}
+		problems = append(problems, byKey[key].describe()+": "+reason(fieldError))
+	}
+	return problems
+}
+
+// checkDependencies reports settings that are only valid together.
+func checkDependencies(cfg Config) []string {
+	var problems []string
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		problems = append(problems, "tls.cert_file (TLS_CERT_FILE) and tls.key_file (TLS_KEY_FILE) must be set together")
+	}
+	if cfg.TLSClientAuth != "none" && cfg.TLSClientCAFile == "" {
+		problems = append(problems, "tls.client_ca_file (TLS_CLIENT_CA_FILE): is required when tls.client_auth is "+cfg.TLSClientAuth)
+	}
+	if !cfg.TLSEnabled() && (cfg.TLSClientAuth != "none" || cfg.TLSRedirectPort != 0) {
+		problems = append(problems, "tls.client_auth and tls.redirect_port (TLS_REDIRECT_PORT) require tls.cert_file and tls.key_file")
+	}
+	if cfg.TLSRedirectPort != 0 && cfg.TLSRedirectPort == cfg.Port {
+		problems = append(problems, "tls.redirect_port (TLS_REDIRECT_PORT): must differ from port")
+	}
+	problems = append(problems, cfg.LateFees.check()...)
+	problems = append(problems, cfg.Payments.check()...)
+	problems = append(problems, cfg.WorkOrders.check()...)
+	return problems
+}
+
+func reason(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + param
+	case "max":
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "url":
+		return "must be a valid URL"
+	default:
+		return "failed " + fieldError.Tag() + " validation"
+	}
+}
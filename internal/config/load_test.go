@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setRequiredEnv sets every required setting, so that tests can focus on the
+// ones they change.
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	for name, value := range map[string]string{
+		"S3_ENDPOINT":     "https://s3.example.com",
+		"S3_REGION":       "us-east-1",
+		"S3_BUCKET":       "bucket",
+		"S3_ACCESS_KEY":   "AKIAEXAMPLE",
+		"S3_SECRET_KEY":   "s3-secret",
+		"AUTH_USERNAME":   "admin",
+		"AUTH_PASSWORD":   "correct-horse",
+		"AUTH_SECRET_KEY": "jwt-key",
+	} {
+		t.Setenv(name, value)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	setRequiredEnv(t)
+	file := filepath.Join(t.TempDir(), "propmanager.yaml")
+	content := "port: 9000\nlog:\n  level: debug\n  format: text\nhttp:\n  write_timeout: 2m\nlate_fees:\n  amount: 12.50\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "json")
+
+	cfg, err := Load([]string{"-config", file, "-log-format", "text"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.ReadTimeout != 30*time.Second {
+		t.Errorf("ReadTimeout = %v, want the default 30s", cfg.ReadTimeout)
+	}
+	if cfg.Port != 9000 || cfg.WriteTimeout != 2*time.Minute {
+		t.Errorf("Port, WriteTimeout = %d, %v, want 9000, 2m0s from the file", cfg.Port, cfg.WriteTimeout)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, want %q from the environment over the file", cfg.LogLevel, "warn")
+	}
+	if cfg.LogFormat != "text" {
+		t.Errorf("LogFormat = %q, want %q from the flag over the environment", cfg.LogFormat, "text")
+	}
+	if cfg.Auth.SecretKey != "jwt-key" {
+		t.Errorf("Auth.SecretKey = %q, want %q", cfg.Auth.SecretKey, "jwt-key")
+	}
+	if cfg.LateFees.Amount.String() != "12.5" {
+		t.Errorf("LateFees.Amount = %s, want 12.5 from the file", cfg.LateFees.Amount)
+	}
+}
+
+func TestLoadListsEveryProblem(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("AUTH_SECRET_KEY", "")
+	t.Setenv("S3_BUCKET", "")
+	t.Setenv("HTTP_IDLE_TIMEOUT", "forever")
+	t.Setenv("LOG_FORMAT", "xml")
+
+	_, err := Load(nil)
+	var configErr *Error
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Load error = %v, want *Error", err)
+	}
+
+	want := []string{
+		`HTTP_IDLE_TIMEOUT: "forever" is not a duration such as 30s`,
+		"log.format (LOG_FORMAT): must be one of: json, text",
+		"s3.bucket (S3_BUCKET): is required",
+		"auth.secret_key (AUTH_SECRET_KEY): is required",
+	}
+	if !reflect.DeepEqual(configErr.Problems, want) {
+		t.Errorf("Problems =\n  %s\nwant\n  %s", strings.Join(configErr.Problems, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestLoadFromFileVariables(t *testing.T) {
+	setRequiredEnv(t)
+	file := filepath.Join(t.TempDir(), "s3_secret_key")
+	if err := os.WriteFile(file, []byte("mounted-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("S3_SECRET_KEY")
+	t.Setenv("S3_SECRET_KEY_FILE", file)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.S3SecretKey != "mounted-secret" {
+		t.Errorf("S3SecretKey = %q, want %q", cfg.S3SecretKey, "mounted-secret")
+	}
+
+	t.Setenv("S3_SECRET_KEY", "from-env")
+	if _, err := Load(nil); err == nil {
+		t.Error("Load succeeded with both S3_SECRET_KEY and S3_SECRET_KEY_FILE set")
+	}
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	setRequiredEnv(t)
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var out strings.Builder
+	if err := Print(&out, cfg); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	for _, secret := range SecretValues(cfg) {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print output contains secret %q:\n%s", secret, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "bucket: bucket") {
+		t.Errorf("Print output lacks non-secret settings:\n%s", out.String())
+	}
+}
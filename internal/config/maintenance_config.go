@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// MaintenanceConfig sets how long after it is reported a maintenance
+// request of each priority is due to be resolved.
+type MaintenanceConfig struct {
+	SLAEmergency time.Duration `config:"sla_emergency" default:"4h" validate:"gt=0" usage:"time allowed to resolve an emergency maintenance request"`
+	SLAHigh      time.Duration `config:"sla_high" default:"24h" validate:"gt=0" usage:"time allowed to resolve a high priority maintenance request"`
+	SLANormal    time.Duration `config:"sla_normal" default:"72h" validate:"gt=0" usage:"time allowed to resolve a normal priority maintenance request"`
+	SLALow       time.Duration `config:"sla_low" default:"168h" validate:"gt=0" usage:"time allowed to resolve a low priority maintenance request"`
+}
+
+// SLA returns the time allowed to resolve a maintenance request of the
+// given priority.
+func (cfg MaintenanceConfig) SLA(priority string) time.Duration {
+	switch priority {
+	case "emergency":
+		return cfg.SLAEmergency
+	case "high":
+		return cfg.SLAHigh
+	case "low":
+		return cfg.SLALow
+	default:
+		return cfg.SLANormal
+	}
+}
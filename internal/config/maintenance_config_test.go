@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceSLA(t *testing.T) {
+	cfg := MaintenanceConfig{SLAEmergency: 4 * time.Hour, SLAHigh: 24 * time.Hour, SLANormal: 72 * time.Hour, SLALow: 168 * time.Hour}
+	tests := []struct {
+		priority string
+		want     time.Duration
+	}{
+		{"emergency", 4 * time.Hour},
+		{"high", 24 * time.Hour},
+		{"normal", 72 * time.Hour},
+		{"low", 168 * time.Hour},
+		// Unknown priorities get the normal service level.
+		{"", 72 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := cfg.SLA(tt.priority); got != tt.want {
+			t.Errorf("SLA(%q) = %v, want %v", tt.priority, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import "propmanager/internal/payments"
+
+// PaymentsConfig selects the provider that takes online payments. The fake
+// provider takes no real payments and is meant for development.
+type PaymentsConfig struct {
+	Provider        string `config:"provider" default:"fake" validate:"oneof=fake stripe" usage:"payment provider: fake or stripe"`
+	Currency        string `config:"currency" default:"usd" validate:"min=3,max=3" usage:"ISO 4217 code, in lower case, of the currency payments are taken in"`
+	StripeAPIURL    string `config:"stripe_api_url" default:"https://api.stripe.com" validate:"url" usage:"base URL of the Stripe-compatible API"`
+	StripeSecretKey string `config:"stripe_secret_key" secret:"true" usage:"Stripe secret API key"`
+	WebhookSecret   string `config:"webhook_secret" secret:"true" usage:"secret that signs payment webhook requests"`
+}
+
+// NewProvider returns the configured payment provider.
+func (cfg *PaymentsConfig) NewProvider() payments.Provider {
+	if cfg.Provider == "stripe" {
+		return payments.NewStripeProvider(cfg.StripeAPIURL, cfg.StripeSecretKey, cfg.WebhookSecret)
+	}
+	return payments.NewFakeProvider(cfg.WebhookSecret)
+}
+
+func (cfg PaymentsConfig) check() []string {
+	var problems []string
+	if cfg.Provider == "stripe" && cfg.StripeSecretKey == "" {
+		problems = append(problems, "payments.stripe_secret_key (PAYMENTS_STRIPE_SECRET_KEY): is required when payments.provider is stripe")
+	}
+	if cfg.Provider == "stripe" && cfg.WebhookSecret == "" {
+		problems = append(problems, "payments.webhook_secret (PAYMENTS_WEBHOOK_SECRET): is required when payments.provider is stripe")
+	}
+	return problems
+}
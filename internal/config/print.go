@@ -0,0 +1,50 @@
+package config
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// secretMask replaces secret values in printed configuration.
+const secretMask = "********"
+
+// Print writes cfg to w as a YAML config file, with the values of secret
+// settings masked.
+func Print(w io.Writer, cfg Config) error {
+	tree := map[string]interface{}{}
+	for _, s := range settingsOf(&cfg) {
+		var value interface{} = s.value.Interface()
+		switch v := value.(type) {
+		case time.Duration:
+			value = v.String()
+		case decimal.Decimal:
+			value = v.String()
+		}
+		if s.secret && s.value.String() != "" {
+			value = secretMask
+		}
+
+		parts := strings.Split(s.key, ".")
+		node := tree
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = value
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(tree); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"context"
+
+	"propmanager/internal/secrets"
+)
+
+// NewSecretResolver returns a resolver for the secret stores cfg configures:
+// "vault:" references read Vault KV and "local:" references read
+// SecretsLocalFile.
+func NewSecretResolver(cfg Config) *secrets.Resolver {
+	resolver := secrets.NewResolver()
+	resolver.Register("vault", secrets.NewVaultProvider(cfg.VaultAddr, cfg.VaultToken, cfg.VaultMount))
+	resolver.Register("local", secrets.NewLocalProvider(cfg.SecretsLocalFile))
+	return resolver
+}
+
+// ResolveSecrets replaces the secret settings of cfg that reference a
+// secret store, such as "vault:propmanager/s3#secret_key", with the values
+// they refer to. It returns an *Error listing every reference that could not
+// be resolved.
+func ResolveSecrets(ctx context.Context, cfg *Config, resolver *secrets.Resolver) error {
+	var problems []string
+	for _, s := range settingsOf(cfg) {
+		if !s.secret || !resolver.IsReference(s.value.String()) {
+			continue
+		}
+		value, err := resolver.Resolve(ctx, s.value.String())
+		if err != nil {
+			problems = append(problems, s.describe()+": "+err.Error())
+			continue
+		}
+		s.value.SetString(value)
+	}
+
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import "reflect"
+
+// SecretValues returns the non-empty string fields of a config struct, and
+// of the structs nested in it, that are tagged `secret:"true"`, so they can
+// be redacted from logs.
+func SecretValues(cfg interface{}) []string {
+	value := reflect.Indirect(reflect.ValueOf(cfg))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var values []string
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if isGroup(field.Type) {
+			values = append(values, SecretValues(value.Field(i).Interface())...)
+			continue
+		}
+		if field.Tag.Get("secret") != "true" || field.Type.Kind() != reflect.String {
+			continue
+		}
+		if secret := value.Field(i).String(); secret != "" {
+			values = append(values, secret)
+		}
+	}
+	return values
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSecretValues(t *testing.T) {
+	cfg := Config{
+		S3Endpoint:  "https://s3.example.com",
+		S3AccessKey: "AKIAEXAMPLE",
+		S3SecretKey: "s3-secret",
+		Port:        8080,
+	}
+	if got, want := SecretValues(cfg), []string{"AKIAEXAMPLE", "s3-secret"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SecretValues(Config) = %v, want %v", got, want)
+	}
+
+	authCfg := &AuthConfig{Username: "admin", Password: "password", SecretKey: ""}
+	if got, want := SecretValues(authCfg), []string{"password"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SecretValues(AuthConfig) = %v, want %v", got, want)
+	}
+}
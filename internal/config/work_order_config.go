@@ -0,0 +1,16 @@
+package config
+
+import "github.com/shopspring/decimal"
+
+// WorkOrderConfig sets when work orders need approval before a vendor
+// starts work.
+type WorkOrderConfig struct {
+	ApprovalThreshold decimal.Decimal `config:"approval_threshold" default:"500" usage:"work orders estimated above this amount need approval"`
+}
+
+func (cfg WorkOrderConfig) check() []string {
+	if cfg.ApprovalThreshold.IsNegative() || !cfg.ApprovalThreshold.Equal(cfg.ApprovalThreshold.Round(2)) {
+		return []string{"work_orders.approval_threshold (WORK_ORDERS_APPROVAL_THRESHOLD): must be a non-negative amount with at most 2 decimal places"}
+	}
+	return nil
+}
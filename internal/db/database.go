@@ -2,16 +2,30 @@ package db
 
 import (
 	"log"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"propmanager/internal/app/metrics"
+	"propmanager/internal/logging"
+	"propmanager/internal/tracing"
 )
 
 func ConnectDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("propmanager.db"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open("propmanager.db"), &gorm.Config{
+		Logger: logging.NewGormLogger(200 * time.Millisecond),
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatal(err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal(err)
+	}
+
 	return db
 }
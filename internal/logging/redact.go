@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secret values in log output.
+const Redacted = "[REDACTED]"
+
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// RegisterSecrets marks values, such as passwords and keys loaded from
+// configuration, as secrets that must never appear in log output.
+func RegisterSecrets(values ...string) {
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	for _, value := range values {
+		if value != "" {
+			secrets.values = append(secrets.values, value)
+		}
+	}
+	// Replace longer secrets first so that one containing another is fully masked.
+	sort.Slice(secrets.values, func(i, j int) bool { return len(secrets.values[i]) > len(secrets.values[j]) })
+}
+
+// Redact replaces every registered secret in s.
+func Redact(s string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+
+	for _, secret := range secrets.values {
+		if strings.Contains(s, secret) {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return s
+}
+
+// sensitiveKeys are substrings of attribute, header and field names whose values are always masked.
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "credential", "cookie"}
+
+// IsSensitiveKey reports whether values stored under key should be masked.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns the headers as a flat map with sensitive ones masked.
+func RedactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if IsSensitiveKey(name) {
+			redacted[name] = Redacted
+			continue
+		}
+		redacted[name] = Redact(strings.Join(values, ", "))
+	}
+	return redacted
+}
+
+// unparseableBody stands in for structured bodies that cannot be parsed, and
+// so cannot have their sensitive fields masked.
+const unparseableBody = "[unparseable body omitted]"
+
+// RedactBody returns a request body of the given content type with
+// sensitive fields and registered secrets masked.
+func RedactBody(contentType string, body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return unparseableBody
+		}
+		for key := range values {
+			if IsSensitiveKey(key) {
+				values[key] = []string{Redacted}
+			}
+		}
+		return Redact(values.Encode())
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err != nil {
+			return unparseableBody
+		}
+		masked, err := json.Marshal(redactJSON(document))
+		if err != nil {
+			return unparseableBody
+		}
+		return Redact(string(masked))
+	}
+	return Redact(string(body))
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if IsSensitiveKey(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// redactAttr masks an attribute whose key is sensitive, or whose value
+// contains a registered secret.
+func redactAttr(attr slog.Attr) slog.Attr {
+	if IsSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, 0, len(group))
+		for _, member := range group {
+			attrs = append(attrs, redactAttr(member))
+		}
+		return slog.Group(attr.Key, attrs...)
+	case slog.KindAny:
+		text := fmt.Sprintf("%+v", value.Any())
+		if redacted := Redact(text); redacted != text {
+			return slog.String(attr.Key, redacted)
+		}
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, err.Error())
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLoggerRedactsRegisteredSecrets(t *testing.T) {
+	RegisterSecrets("s3-secret-value", "jwt-signing-key")
+
+	var buf bytes.Buffer
+	logger := New(&buf, "debug", "json")
+
+	logger.Info("connecting with s3-secret-value")
+	logger.Info("attrs", "key", "prefix-jwt-signing-key-suffix")
+	logger.Info("error", "error", errors.New("signature invalid for jwt-signing-key"))
+	logger.Info("group", slog.Group("aws", slog.String("credentials", "s3-secret-value")))
+	logger.With("cfg", "s3-secret-value").Info("with")
+	logger.Info("struct", "cfg", struct{ Key string }{"jwt-signing-key"})
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "context", "value", "s3-secret-value")
+
+	assertNoSecrets(t, buf.String(), "s3-secret-value", "jwt-signing-key")
+	if !strings.Contains(buf.String(), Redacted) {
+		t.Errorf("expected output to contain %q, got:\n%s", Redacted, buf.String())
+	}
+}
+
+func TestLoggerMasksSensitiveKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "debug", "text")
+
+	logger.Info("login", "password", "hunter2", "api_token", "tok-123", "username", "alice")
+
+	assertNoSecrets(t, buf.String(), "hunter2", "tok-123")
+	if !strings.Contains(buf.String(), "alice") {
+		t.Errorf("expected non-sensitive attribute to be kept, got:\n%s", buf.String())
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc.def.ghi")
+	header.Set("Cookie", "session=xyz")
+	header.Set("Accept", "application/json")
+
+	redacted := RedactHeaders(header)
+
+	if redacted["Authorization"] != Redacted {
+		t.Errorf("Authorization = %q, want %q", redacted["Authorization"], Redacted)
+	}
+	if redacted["Cookie"] != Redacted {
+		t.Errorf("Cookie = %q, want %q", redacted["Cookie"], Redacted)
+	}
+	if redacted["Accept"] != "application/json" {
+		t.Errorf("Accept = %q, want application/json", redacted["Accept"])
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		secrets     []string
+		keep        string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"username":"alice","password":"hunter2","nested":{"refresh_token":"tok-123"},"list":[{"secret":"s-1"}]}`,
+			secrets:     []string{"hunter2", "tok-123", "s-1"},
+			keep:        "alice",
+		},
+		{
+			name:        "merge patch",
+			contentType: "application/merge-patch+json; charset=utf-8",
+			body:        `{"token":"tok-456","name":"Loft"}`,
+			secrets:     []string{"tok-456"},
+			keep:        "Loft",
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "username=alice&password=hunter2",
+			secrets:     []string{"hunter2"},
+			keep:        "alice",
+		},
+		{
+			name:        "truncated json",
+			contentType: "application/json",
+			body:        `{"password":"hunter2","na`,
+			secrets:     []string{"hunter2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RedactBody(tt.contentType, []byte(tt.body))
+			assertNoSecrets(t, got, tt.secrets...)
+			if tt.keep != "" && !strings.Contains(got, tt.keep) {
+				t.Errorf("expected %q to be kept, got %s", tt.keep, got)
+			}
+		})
+	}
+}
+
+func assertNoSecrets(t *testing.T, output string, secrets ...string) {
+	t.Helper()
+	for _, secret := range secrets {
+		if strings.Contains(output, secret) {
+			t.Errorf("output contains secret %q:\n%s", secret, output)
+		}
+	}
+}
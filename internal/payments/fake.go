@@ -0,0 +1,100 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeProvider stands in for a payment provider during development. It
+// creates payment intents without contacting anyone and accepts webhook
+// events in Stripe's format signed with its webhook secret, so that
+// payments can be completed, refunded and paid out by posting events. A
+// payout settles every succeeded payment not yet paid out, with no fees.
+type FakeProvider struct {
+	webhookSecret string
+
+	mu      sync.Mutex
+	next    int
+	intents map[string]PaymentIntent
+	settled []PayoutPayment
+	seen    map[string]bool
+	payouts map[string][]PayoutPayment
+}
+
+// NewFakeProvider returns a fake provider that verifies webhooks signed
+// with webhookSecret.
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		webhookSecret: webhookSecret,
+		intents:       map[string]PaymentIntent{},
+		seen:          map[string]bool{},
+		payouts:       map[string][]PayoutPayment{},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreatePaymentIntent returns the payment intent created earlier with the
+// same idempotency key, if any, like Stripe.
+func (p *FakeProvider) CreatePaymentIntent(ctx context.Context, params PaymentIntentParams) (PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if intent, ok := p.intents[params.IdempotencyKey]; ok && params.IdempotencyKey != "" {
+		return intent, nil
+	}
+
+	p.next++
+	id := fmt.Sprintf("pi_fake_%d_%d", time.Now().Unix(), p.next)
+	intent := PaymentIntent{
+		ID:           id,
+		ClientSecret: id + "_secret_fake",
+		Status:       "requires_payment_method",
+		Amount:       params.Amount,
+		Currency:     params.Currency,
+	}
+	if params.IdempotencyKey != "" {
+		p.intents[params.IdempotencyKey] = intent
+	}
+	return intent, nil
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, header http.Header) (Event, error) {
+	if err := verifySignature(p.webhookSecret, payload, header.Get(SignatureHeader), time.Now()); err != nil {
+		return Event{}, err
+	}
+	event, err := parseEvent(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch event.Type {
+	case EventPaymentSucceeded:
+		if !p.seen[event.PaymentIntentID] {
+			p.seen[event.PaymentIntentID] = true
+			p.settled = append(p.settled, PayoutPayment{
+				PaymentIntentID: event.PaymentIntentID,
+				Amount:          event.Amount,
+				Net:             event.Amount,
+			})
+		}
+	case EventPayoutPaid:
+		if _, ok := p.payouts[event.PayoutID]; !ok {
+			p.payouts[event.PayoutID] = p.settled
+			p.settled = nil
+		}
+	}
+	return event, nil
+}
+
+func (p *FakeProvider) PayoutPayments(ctx context.Context, payoutID string) ([]PayoutPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.payouts[payoutID], nil
+}
@@ -0,0 +1,242 @@
+// Package payments takes payments through an online payment provider and
+// verifies the webhook events it sends about them.
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Normalised event types. Provider events of other types are reported with
+// an empty Type.
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentRefunded  = "payment.refunded"
+	EventPayoutPaid       = "payout.paid"
+)
+
+// SignatureHeader carries the signature of a webhook request, in the form
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">".
+const SignatureHeader = "Stripe-Signature"
+
+// signatureTolerance is how old a signed webhook may be, to limit replays.
+const signatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned for webhook requests that are unsigned,
+// signed with another secret, tampered with or too old.
+var ErrInvalidSignature = errors.New("payments: invalid webhook signature")
+
+// Provider takes payments with an online payment processor.
+type Provider interface {
+	// Name identifies the provider in stored records, such as "stripe".
+	Name() string
+	// CreatePaymentIntent starts a payment that the payer completes with
+	// the returned client secret.
+	CreatePaymentIntent(ctx context.Context, params PaymentIntentParams) (PaymentIntent, error)
+	// ParseWebhook verifies the signature of a webhook request and returns
+	// the event it carries.
+	ParseWebhook(payload []byte, header http.Header) (Event, error)
+	// PayoutPayments returns the payments settled by a payout.
+	PayoutPayments(ctx context.Context, payoutID string) ([]PayoutPayment, error)
+}
+
+// PaymentIntentParams describes a payment to take.
+type PaymentIntentParams struct {
+	Amount      decimal.Decimal
+	Currency    string
+	Description string
+	// Metadata is stored with the payment and returned in its events.
+	Metadata map[string]string
+	// IdempotencyKey makes retried requests return the same payment.
+	IdempotencyKey string
+}
+
+// PaymentIntent is a payment started with a provider.
+type PaymentIntent struct {
+	ID           string
+	ClientSecret string
+	Status       string
+	Amount       decimal.Decimal
+	Currency     string
+}
+
+// Event is a webhook event about a payment or payout.
+type Event struct {
+	ID      string
+	Type    string
+	Created time.Time
+	// PaymentIntentID is the payment the event is about, if any.
+	PaymentIntentID string
+	// Amount is the amount received for succeeded payments, the total
+	// refunded so far for refunds and the amount paid out for payouts.
+	Amount         decimal.Decimal
+	Currency       string
+	FailureMessage string
+	Metadata       map[string]string
+	// PayoutID and ArrivalDate describe payout events.
+	PayoutID    string
+	ArrivalDate time.Time
+}
+
+// PayoutPayment is a payment, or a refund of one, settled by a payout: its
+// amount, the provider's fee and what was paid out for it.
+type PayoutPayment struct {
+	PaymentIntentID string
+	Amount          decimal.Decimal
+	Fee             decimal.Decimal
+	Net             decimal.Decimal
+}
+
+// Sign returns the signature header value for payload signed with secret
+// at time t.
+func Sign(secret string, payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + signature(secret, timestamp, payload)
+}
+
+func signature(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature checks the signature header of a webhook request.
+func verifySignature(secret string, payload []byte, header string, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no webhook secret is configured", ErrInvalidSignature)
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, SignatureHeader)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", ErrInvalidSignature)
+	}
+
+	expected := signature(secret, timestamp, payload)
+	for _, candidate := range signatures {
+		if hmac.Equal([]byte(candidate), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// stripeEvent is the webhook event format shared by Stripe and the fake.
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type stripeObject struct {
+	ID               string            `json:"id"`
+	Amount           int64             `json:"amount"`
+	AmountReceived   int64             `json:"amount_received"`
+	AmountRefunded   int64             `json:"amount_refunded"`
+	Currency         string            `json:"currency"`
+	PaymentIntent    string            `json:"payment_intent"`
+	ArrivalDate      int64             `json:"arrival_date"`
+	Metadata         map[string]string `json:"metadata"`
+	LastPaymentError *struct {
+		Message string `json:"message"`
+	} `json:"last_payment_error"`
+}
+
+// parseEvent decodes a Stripe webhook event into an Event.
+func parseEvent(payload []byte) (Event, error) {
+	var raw stripeEvent
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return Event{}, fmt.Errorf("payments: decoding event: %w", err)
+	}
+	if raw.ID == "" {
+		return Event{}, errors.New("payments: event has no id")
+	}
+	var object stripeObject
+	if len(raw.Data.Object) > 0 {
+		if err := json.Unmarshal(raw.Data.Object, &object); err != nil {
+			return Event{}, fmt.Errorf("payments: decoding event object: %w", err)
+		}
+	}
+
+	event := Event{ID: raw.ID, Created: time.Unix(raw.Created, 0).UTC(), Currency: object.Currency, Metadata: object.Metadata}
+	switch raw.Type {
+	case "payment_intent.succeeded":
+		event.Type = EventPaymentSucceeded
+		event.PaymentIntentID = object.ID
+		event.Amount = fromMinorUnits(object.AmountReceived, object.Currency)
+	case "payment_intent.payment_failed":
+		event.Type = EventPaymentFailed
+		event.PaymentIntentID = object.ID
+		event.Amount = fromMinorUnits(object.Amount, object.Currency)
+		if object.LastPaymentError != nil {
+			event.FailureMessage = object.LastPaymentError.Message
+		}
+	case "charge.refunded":
+		event.Type = EventPaymentRefunded
+		event.PaymentIntentID = object.PaymentIntent
+		event.Amount = fromMinorUnits(object.AmountRefunded, object.Currency)
+	case "payout.paid":
+		event.Type = EventPayoutPaid
+		event.PayoutID = object.ID
+		event.Amount = fromMinorUnits(object.Amount, object.Currency)
+		event.ArrivalDate = time.Unix(object.ArrivalDate, 0).UTC()
+	}
+	return event, nil
+}
+
+// currencyExponents lists the currencies whose minor unit is not a
+// hundredth: those without one, such as the yen, and those divided into
+// thousandths, such as the Kuwaiti dinar.
+var currencyExponents = map[string]int32{
+	"bif": 0, "clp": 0, "djf": 0, "gnf": 0, "jpy": 0, "kmf": 0, "krw": 0, "mga": 0,
+	"pyg": 0, "rwf": 0, "ugx": 0, "vnd": 0, "vuv": 0, "xaf": 0, "xof": 0, "xpf": 0,
+	"bhd": 3, "jod": 3, "kwd": 3, "omr": 3, "tnd": 3,
+}
+
+// MinorUnitExponent returns the number of decimal places of the minor unit
+// of currency, an ISO 4217 code: 2 for cents, 0 for the yen.
+func MinorUnitExponent(currency string) int32 {
+	if exponent, ok := currencyExponents[strings.ToLower(currency)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// Amounts are sent to providers in the currency's minor unit, such as cents.
+func toMinorUnits(amount decimal.Decimal, currency string) int64 {
+	return amount.Shift(MinorUnitExponent(currency)).Round(0).IntPart()
+}
+
+func fromMinorUnits(amount int64, currency string) decimal.Decimal {
+	return decimal.New(amount, -MinorUnitExponent(currency))
+}
@@ -0,0 +1,97 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const testSecret = "whsec_test"
+
+func signedHeader(payload []byte, secret string, t time.Time) http.Header {
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(secret, payload, t))
+	return header
+}
+
+func TestParseWebhookVerifiesSignature(t *testing.T) {
+	provider := NewStripeProvider("https://api.stripe.test", "sk_test", testSecret)
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","created":1700000000,
+		"data":{"object":{"id":"pi_1","amount":95000,"amount_received":95000,"currency":"usd","metadata":{"lease_id":"7"}}}}`)
+
+	event, err := provider.ParseWebhook(payload, signedHeader(payload, testSecret, time.Now()))
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventPaymentSucceeded || event.PaymentIntentID != "pi_1" || !event.Amount.Equal(decimal.RequireFromString("950")) || event.Metadata["lease_id"] != "7" {
+		t.Errorf("ParseWebhook = %+v, want a 950.00 payment.succeeded for pi_1 of lease 7", event)
+	}
+
+	for name, header := range map[string]http.Header{
+		"unsigned":     {},
+		"wrong secret": signedHeader(payload, "whsec_other", time.Now()),
+		"too old":      signedHeader(payload, testSecret, time.Now().Add(-time.Hour)),
+		"tampered":     signedHeader(append([]byte(" "), payload...), testSecret, time.Now()),
+	} {
+		if _, err := provider.ParseWebhook(payload, header); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: ParseWebhook error = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
+
+func TestStripeCreatePaymentIntent(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Invalid API Key"}}`))
+			return
+		}
+		if r.URL.Path != "/v1/payment_intents" || r.FormValue("amount") != "12050" || r.FormValue("metadata[lease_id]") != "7" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"unexpected request"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"pi_1","client_secret":"pi_1_secret","status":"requires_payment_method","amount":12050,"currency":"usd"}`))
+	}))
+	defer api.Close()
+
+	params := PaymentIntentParams{Amount: decimal.RequireFromString("120.50"), Currency: "usd", Metadata: map[string]string{"lease_id": "7"}}
+	intent, err := NewStripeProvider(api.URL, "sk_test", testSecret).CreatePaymentIntent(context.Background(), params)
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent: %v", err)
+	}
+	if intent.ID != "pi_1" || intent.ClientSecret != "pi_1_secret" || !intent.Amount.Equal(params.Amount) {
+		t.Errorf("CreatePaymentIntent = %+v, want pi_1 for 120.50", intent)
+	}
+
+	if _, err := NewStripeProvider(api.URL, "sk_wrong", testSecret).CreatePaymentIntent(context.Background(), params); err == nil {
+		t.Error("CreatePaymentIntent with a wrong key succeeded")
+	}
+}
+
+func TestMinorUnitsFollowCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		amount   string
+		minor    int64
+	}{
+		{"usd", "120.50", 12050},
+		{"EUR", "0.01", 1},
+		{"jpy", "950", 950},
+		{"kwd", "12.50", 12500},
+	}
+	for _, tt := range tests {
+		amount := decimal.RequireFromString(tt.amount)
+		if got := toMinorUnits(amount, tt.currency); got != tt.minor {
+			t.Errorf("toMinorUnits(%s %s) = %d, want %d", tt.amount, tt.currency, got, tt.minor)
+		}
+		if got := fromMinorUnits(tt.minor, tt.currency); !got.Equal(amount) {
+			t.Errorf("fromMinorUnits(%d %s) = %s, want %s", tt.minor, tt.currency, got, tt.amount)
+		}
+	}
+}
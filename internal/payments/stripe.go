@@ -0,0 +1,161 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StripeProvider takes payments with Stripe, or any service implementing
+// the same API.
+type StripeProvider struct {
+	apiURL        string
+	secretKey     string
+	webhookSecret string
+	client        *http.Client
+}
+
+// NewStripeProvider returns a provider for the Stripe API at apiURL,
+// authenticating with secretKey and verifying webhooks signed with
+// webhookSecret.
+func NewStripeProvider(apiURL, secretKey, webhookSecret string) *StripeProvider {
+	return &StripeProvider{
+		apiURL:        strings.TrimRight(apiURL, "/"),
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *StripeProvider) Name() string {
+	return "stripe"
+}
+
+func (p *StripeProvider) CreatePaymentIntent(ctx context.Context, params PaymentIntentParams) (PaymentIntent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(toMinorUnits(params.Amount, params.Currency), 10))
+	form.Set("currency", params.Currency)
+	form.Set("description", params.Description)
+	form.Set("automatic_payment_methods[enabled]", "true")
+	for key, value := range params.Metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL+"/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return PaymentIntent{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if params.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", params.IdempotencyKey)
+	}
+
+	var body struct {
+		ID           string `json:"id"`
+		ClientSecret string `json:"client_secret"`
+		Status       string `json:"status"`
+		Amount       int64  `json:"amount"`
+		Currency     string `json:"currency"`
+	}
+	if err := p.do(req, &body); err != nil {
+		return PaymentIntent{}, err
+	}
+	return PaymentIntent{
+		ID:           body.ID,
+		ClientSecret: body.ClientSecret,
+		Status:       body.Status,
+		Amount:       fromMinorUnits(body.Amount, body.Currency),
+		Currency:     body.Currency,
+	}, nil
+}
+
+func (p *StripeProvider) ParseWebhook(payload []byte, header http.Header) (Event, error) {
+	if err := verifySignature(p.webhookSecret, payload, header.Get(SignatureHeader), time.Now()); err != nil {
+		return Event{}, err
+	}
+	return parseEvent(payload)
+}
+
+// PayoutPayments lists the balance transactions of a payout and returns
+// those for payments and their refunds, with the payment intent each
+// belongs to. Refunds have negative amounts.
+func (p *StripeProvider) PayoutPayments(ctx context.Context, payoutID string) ([]PayoutPayment, error) {
+	var payments []PayoutPayment
+	startingAfter := ""
+	for {
+		query := url.Values{}
+		query.Set("payout", payoutID)
+		query.Set("limit", "100")
+		query.Add("expand[]", "data.source")
+		if startingAfter != "" {
+			query.Set("starting_after", startingAfter)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+"/v1/balance_transactions?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var body struct {
+			Data []struct {
+				ID       string `json:"id"`
+				Amount   int64  `json:"amount"`
+				Fee      int64  `json:"fee"`
+				Net      int64  `json:"net"`
+				Currency string `json:"currency"`
+				Source   struct {
+					PaymentIntent string `json:"payment_intent"`
+				} `json:"source"`
+			} `json:"data"`
+			HasMore bool `json:"has_more"`
+		}
+		if err := p.do(req, &body); err != nil {
+			return nil, err
+		}
+
+		for _, transaction := range body.Data {
+			if transaction.Source.PaymentIntent == "" {
+				continue
+			}
+			payments = append(payments, PayoutPayment{
+				PaymentIntentID: transaction.Source.PaymentIntent,
+				Amount:          fromMinorUnits(transaction.Amount, transaction.Currency),
+				Fee:             fromMinorUnits(transaction.Fee, transaction.Currency),
+				Net:             fromMinorUnits(transaction.Net, transaction.Currency),
+			})
+		}
+		if !body.HasMore || len(body.Data) == 0 {
+			return payments, nil
+		}
+		startingAfter = body.Data[len(body.Data)-1].ID
+	}
+}
+
+// do sends an authenticated API request and decodes the JSON response into out.
+func (p *StripeProvider) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("stripe responded %s: %s", resp.Status, body.Error.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding stripe response: %w", err)
+	}
+	return nil
+}
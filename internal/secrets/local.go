@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LocalProvider reads secrets from a JSON file mapping paths to keys and
+// values, such as {"propmanager/s3": {"secret_key": "..."}}. It stands in
+// for an external store in development and tests; the file is read on every
+// lookup, so editing it rotates the secrets.
+type LocalProvider struct {
+	path string
+}
+
+func NewLocalProvider(path string) *LocalProvider {
+	return &LocalProvider{path: path}
+}
+
+func (p *LocalProvider) Get(_ context.Context, path string) (map[string]string, error) {
+	if p.path == "" {
+		return nil, errors.New("local secrets file is not configured")
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p.path, err)
+	}
+
+	values, ok := entries[path]
+	if !ok {
+		return nil, fmt.Errorf("no entry in %s", p.path)
+	}
+	return values, nil
+}
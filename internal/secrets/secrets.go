@@ -0,0 +1,65 @@
+// Package secrets resolves references to secrets held outside the
+// configuration, such as "vault:propmanager/s3#secret_key".
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Provider reads secrets from an external store. A path names a group of
+// secrets, such as a Vault KV entry, and Get returns all of its keys.
+type Provider interface {
+	Get(ctx context.Context, path string) (map[string]string, error)
+}
+
+// Resolver resolves references of the form "<provider>:<path>#<key>" with
+// the provider registered under that name. Values naming no registered
+// provider, such as "https://example.com", are not references.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{providers: map[string]Provider{}}
+}
+
+// Register makes p resolve references that start with name + ":".
+func (r *Resolver) Register(name string, p Provider) {
+	r.providers[name] = p
+}
+
+// IsReference reports whether value refers to a registered provider.
+func (r *Resolver) IsReference(value string) bool {
+	name, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	_, ok = r.providers[name]
+	return ok
+}
+
+// Resolve returns the secret value refers to, or value itself if it is not
+// a reference.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !r.IsReference(value) {
+		return value, nil
+	}
+
+	name, rest, _ := strings.Cut(value, ":")
+	path, key, ok := strings.Cut(rest, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("secret reference %q must have the form %s:<path>#<key>", value, name)
+	}
+
+	values, err := r.providers[name].Get(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s secret %s: %w", name, path, err)
+	}
+	secret, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("%s secret %s has no key %q", name, path, key)
+	}
+	return secret, nil
+}
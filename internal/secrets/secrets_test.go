@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolverVault(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/propmanager/s3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"access_key":"AKIAVAULT","secret_key":"vault-secret"},"metadata":{"version":3}}}`))
+	}))
+	defer vault.Close()
+
+	resolver := NewResolver()
+	resolver.Register("vault", NewVaultProvider(vault.URL, "root-token", "kv"))
+	ctx := context.Background()
+
+	if got, err := resolver.Resolve(ctx, "vault:propmanager/s3#secret_key"); err != nil || got != "vault-secret" {
+		t.Errorf("Resolve = %q, %v, want %q", got, err, "vault-secret")
+	}
+	if _, err := resolver.Resolve(ctx, "vault:propmanager/s3#missing"); err == nil {
+		t.Error("Resolve of a missing key succeeded")
+	}
+	if _, err := resolver.Resolve(ctx, "vault:propmanager/db#password"); err == nil {
+		t.Error("Resolve of a missing path succeeded")
+	}
+	if got, err := resolver.Resolve(ctx, "https://s3.example.com"); err != nil || got != "https://s3.example.com" {
+		t.Errorf("Resolve of a plain value = %q, %v, want it unchanged", got, err)
+	}
+}
+
+func TestResolverLocalRotation(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.json")
+	write := func(secret string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(`{"propmanager/s3": {"secret_key": "`+secret+`"}}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolver := NewResolver()
+	resolver.Register("local", NewLocalProvider(file))
+	for _, secret := range []string{"first", "rotated"} {
+		write(secret)
+		if got, err := resolver.Resolve(context.Background(), "local:propmanager/s3#secret_key"); err != nil || got != secret {
+			t.Errorf("Resolve = %q, %v, want %q", got, err, secret)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV version 2 engine.
+type VaultProvider struct {
+	address string
+	token   string
+	mount   string
+	client  *http.Client
+}
+
+// NewVaultProvider returns a provider for the KV engine mounted at mount on
+// the Vault server at address, authenticating with token.
+func NewVaultProvider(address, token, mount string) *VaultProvider {
+	return &VaultProvider{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Get returns the latest version of the KV entry at path.
+func (p *VaultProvider) Get(ctx context.Context, path string) (map[string]string, error) {
+	if p.address == "" {
+		return nil, errors.New("vault address is not configured")
+	}
+
+	endpoint := p.address + "/v1/" + url.PathEscape(p.mount) + "/data/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault responded %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding vault response: %w", err)
+	}
+
+	values := make(map[string]string, len(body.Data.Data))
+	for key, value := range body.Data.Data {
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,177 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key to
+// certFile and keyFile, with the given modification time.
+func writeCert(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func commonName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloaderPicksUpRenewedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Hour)
+	writeCert(t, certFile, keyFile, "first", start)
+
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := commonName(t, reloader); got != "first" {
+		t.Fatalf("certificate = %q, want first", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	// A broken renewal keeps the previous certificate.
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := commonName(t, reloader); got != "first" {
+		t.Fatalf("certificate after a broken renewal = %q, want first", got)
+	}
+
+	writeCert(t, certFile, keyFile, "renewed", time.Now())
+	deadline := time.Now().Add(2 * time.Second)
+	for commonName(t, reloader) != "renewed" {
+		if time.Now().After(deadline) {
+			t.Fatal("renewed certificate was not loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewCertReloaderRejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if _, err := NewCertReloader(certFile, keyFile); err == nil {
+		t.Error("NewCertReloader of missing files succeeded")
+	}
+
+	writeCert(t, certFile, keyFile, "server", time.Now())
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCertReloader(certFile, keyFile); err == nil {
+		t.Error("NewCertReloader with an invalid key succeeded")
+	}
+}
+
+func TestNewClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "server", time.Now())
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(dir, "ca.pem")
+	writeCert(t, caFile, filepath.Join(dir, "ca-key.pem"), "internal CA", time.Now())
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("no certificates here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		clientAuth string
+		caFile     string
+		want       tls.ClientAuthType
+		wantCAs    bool
+		wantErr    bool
+	}{
+		{name: "none", clientAuth: ClientAuthNone, want: tls.NoClientCert},
+		{name: "unset", clientAuth: "", want: tls.NoClientCert},
+		{name: "optional", clientAuth: ClientAuthOptional, caFile: caFile, want: tls.VerifyClientCertIfGiven, wantCAs: true},
+		{name: "require", clientAuth: ClientAuthRequire, caFile: caFile, want: tls.RequireAndVerifyClientCert, wantCAs: true},
+		{name: "missing CA file", clientAuth: ClientAuthRequire, caFile: filepath.Join(dir, "missing.pem"), wantErr: true},
+		{name: "CA file without certificates", clientAuth: ClientAuthRequire, caFile: notPEM, wantErr: true},
+		{name: "unknown mode", clientAuth: "sometimes", caFile: caFile, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := New(reloader, tt.clientAuth, tt.caFile)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("New succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.ClientAuth != tt.want {
+				t.Errorf("ClientAuth = %v, want %v", cfg.ClientAuth, tt.want)
+			}
+			if (cfg.ClientCAs != nil) != tt.wantCAs {
+				t.Errorf("ClientCAs set = %v, want %v", cfg.ClientCAs != nil, tt.wantCAs)
+			}
+			if cfg.MinVersion != tls.VersionTLS12 {
+				t.Errorf("MinVersion = %x, want TLS 1.2", cfg.MinVersion)
+			}
+		})
+	}
+}
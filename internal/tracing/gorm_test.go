@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type account struct {
+	ID    uint
+	Token string
+}
+
+// TestGormSpans checks that queries run with a traced context get child
+// spans that record the statement without its bound values.
+func TestGormSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	tx := db.WithContext(ctx)
+	if err := tx.Create(&account{Token: "s3cr3t-token"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var found account
+	if err := tx.Where("token = ?", "s3cr3t-token").First(&found).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.First(&found, 99).Error; err != gorm.ErrRecordNotFound {
+		t.Fatalf("err = %v, want ErrRecordNotFound", err)
+	}
+	parent.End()
+
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if strings.HasPrefix(span.Name(), "gorm.") {
+			spans = append(spans, span)
+		}
+	}
+	names := []string{"gorm.create accounts", "gorm.query accounts", "gorm.query accounts"}
+	if len(spans) != len(names) {
+		t.Fatalf("recorded %d database spans, want %d", len(spans), len(names))
+	}
+	for i, span := range spans {
+		if span.Name() != names[i] {
+			t.Errorf("span %d = %s, want %s", i, span.Name(), names[i])
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("%s is not a child of the request span", span.Name())
+		}
+		if span.Status().Code == codes.Error {
+			t.Errorf("%s has error status %v; a missing record is not an error", span.Name(), span.Status())
+		}
+		statement := ""
+		for _, kv := range span.Attributes() {
+			if kv.Key == semconv.DBQueryTextKey {
+				statement = kv.Value.AsString()
+			}
+		}
+		if strings.Contains(statement, "s3cr3t") || !strings.Contains(statement, "?") {
+			t.Errorf("%s records %q, want the statement with placeholders only", span.Name(), statement)
+		}
+	}
+}